@@ -122,6 +122,74 @@ func (c *Client) Unsubscribe(sub *apps.Subscription) error {
 	return nil
 }
 
+func (c *Client) CreateSchedule(schedule *apps.Schedule) (*apps.Schedule, error) {
+	created, res, err := c.ClientPP.CreateSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return created, nil
+}
+
+func (c *Client) GetSchedules() ([]apps.Schedule, error) {
+	schedules, res, err := c.ClientPP.GetSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return schedules, nil
+}
+
+func (c *Client) CancelSchedule(id string) error {
+	res, err := c.ClientPP.CancelSchedule(id)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *Client) GetCallJob(id string) (*apps.CallJob, error) {
+	job, res, err := c.ClientPP.GetCallJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return job, nil
+}
+
+func (c *Client) UpdateCallJobProgress(id string, progress int, message string) error {
+	res, err := c.ClientPP.UpdateCallJobProgress(id, apps.CallJobProgress{
+		Progress: progress,
+		Message:  message,
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
 func (c *Client) StoreOAuth2App(oauth2App apps.OAuth2App) error {
 	res, err := c.ClientPP.StoreOAuth2App(oauth2App)
 	if err != nil {
@@ -174,6 +242,19 @@ func (c *Client) Call(creq apps.CallRequest) (*apps.CallResponse, error) {
 	return cresp, nil
 }
 
+func (c *Client) CallApp(creq apps.CallRequest) (*apps.CallResponse, error) {
+	cresp, res, err := c.ClientPP.CallApp(creq)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return cresp, nil
+}
+
 func (c *Client) CreatePost(post *model.Post) (*model.Post, error) {
 	createdPost, res, err := c.Client4.CreatePost(post)
 	if err != nil {
@@ -148,6 +148,78 @@ func (c *ClientPP) Unsubscribe(sub *apps.Subscription) (*model.Response, error)
 	return model.BuildResponse(r), nil
 }
 
+func (c *ClientPP) CreateSchedule(schedule *apps.Schedule) (*apps.Schedule, *model.Response, error) {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := c.DoAPIPOST(c.apipath(appspath.Schedule), string(data)) // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var created apps.Schedule
+	err = json.NewDecoder(r.Body).Decode(&created)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+
+	return &created, model.BuildResponse(r), nil
+}
+
+func (c *ClientPP) GetSchedules() ([]apps.Schedule, *model.Response, error) {
+	r, err := c.DoAPIGET(c.apipath(appspath.Schedule), "") // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var schedules []apps.Schedule
+	err = json.NewDecoder(r.Body).Decode(&schedules)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+
+	return schedules, model.BuildResponse(r), nil
+}
+
+func (c *ClientPP) CancelSchedule(id string) (*model.Response, error) {
+	r, err := c.DoAPIDELETE(c.apipath(appspath.Schedule) + "/" + url.PathEscape(id)) // nolint:bodyclose
+	if err != nil {
+		return model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	return model.BuildResponse(r), nil
+}
+
+func (c *ClientPP) GetCallJob(id string) (*apps.CallJob, *model.Response, error) {
+	r, err := c.DoAPIGET(c.apipath(appspath.CallJob)+"/"+url.PathEscape(id), "") // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var job apps.CallJob
+	err = json.NewDecoder(r.Body).Decode(&job)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+
+	return &job, model.BuildResponse(r), nil
+}
+
+func (c *ClientPP) UpdateCallJobProgress(id string, progress apps.CallJobProgress) (*model.Response, error) {
+	r, err := c.DoAPIPOST(c.apipath(appspath.CallJob)+"/"+url.PathEscape(id)+appspath.CallJobProgress, utils.ToJSON(progress)) // nolint:bodyclose
+	if err != nil {
+		return model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	return model.BuildResponse(r), nil
+}
+
 func (c *ClientPP) StoreOAuth2App(oauth2App apps.OAuth2App) (*model.Response, error) {
 	r, err := c.DoAPIPOST(c.apipath(appspath.OAuth2App), utils.ToJSON(oauth2App)) // nolint:bodyclose
 	if err != nil {
@@ -352,6 +424,29 @@ func (c *ClientPP) Call(creq apps.CallRequest) (*apps.CallResponse, *model.Respo
 	return &cresp, model.BuildResponse(r), nil
 }
 
+// CallApp invokes a call of another app, on behalf of the client's user. The
+// other app must allow the calls from the client's app.
+func (c *ClientPP) CallApp(creq apps.CallRequest) (*apps.CallResponse, *model.Response, error) {
+	b, err := json.Marshal(&creq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := c.DoAPIPOST(c.apipath(appspath.AppCall), string(b)) // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var cresp apps.CallResponse
+	err = json.NewDecoder(r.Body).Decode(&cresp)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+
+	return &cresp, model.BuildResponse(r), nil
+}
+
 func (c *ClientPP) getPluginsRoute() string {
 	return "/plugins"
 }
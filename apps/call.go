@@ -29,6 +29,22 @@ type Call struct {
 
 	// Custom data that will be passed to the function in JSON, "as is".
 	State interface{} `json:"state,omitempty"`
+
+	// Async requests that a call submitted from the user agent be invoked in
+	// the background. The proxy responds right away with a "job" response,
+	// the CallJob's ID is passed to the app in CallRequest.JobID. Use for calls
+	// that take longer than a regular request allows, like exports and
+	// reports. The path must be declared in the App's Manifest.AsyncCallPaths.
+	// The number of async calls running at a time is limited, past the limit
+	// the call fails with a "too many requests" error.
+	Async bool `json:"async,omitempty"`
+
+	// Timeout is the time, in milliseconds, that the call is given to
+	// complete. It overrides the App's Manifest.CallTimeout, and is capped by
+	// the maximum configured by the administrator. Async calls are given the
+	// maximum async call timeout configured by the administrator, unless they
+	// specify a shorter Timeout.
+	Timeout int `json:"timeout,omitempty"`
 }
 
 func (c *Call) UnmarshalJSON(data []byte) error {
@@ -43,9 +59,11 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 
 	// Need a type that is just like Call, but without UnmarshalJSON
 	structValue := struct {
-		Path   string      `json:"path,omitempty"`
-		Expand *Expand     `json:"expand,omitempty"`
-		State  interface{} `json:"state,omitempty"`
+		Path    string      `json:"path,omitempty"`
+		Expand  *Expand     `json:"expand,omitempty"`
+		State   interface{} `json:"state,omitempty"`
+		Async   bool        `json:"async,omitempty"`
+		Timeout int         `json:"timeout,omitempty"`
 	}{}
 	err = json.Unmarshal(data, &structValue)
 	if err != nil {
@@ -53,9 +71,11 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	}
 
 	*c = Call{
-		Path:   structValue.Path,
-		Expand: structValue.Expand,
-		State:  structValue.State,
+		Path:    structValue.Path,
+		Expand:  structValue.Expand,
+		State:   structValue.State,
+		Async:   structValue.Async,
+		Timeout: structValue.Timeout,
 	}
 	return nil
 }
@@ -111,6 +131,12 @@ func (c *Call) WithDefault(def Call) Call {
 	if clone.State == nil {
 		clone.State = def.State
 	}
+	if !clone.Async {
+		clone.Async = def.Async
+	}
+	if clone.Timeout == 0 {
+		clone.Timeout = def.Timeout
+	}
 	return *clone
 }
 
@@ -151,6 +177,12 @@ func (c Call) String() string {
 	if c.State != nil {
 		s += fmt.Sprintf(", state: %v", utils.LogDigest(c.State))
 	}
+	if c.Async {
+		s += ", async"
+	}
+	if c.Timeout != 0 {
+		s += fmt.Sprintf(", timeout: %vms", c.Timeout)
+	}
 	return s
 }
 
@@ -162,5 +194,11 @@ func (c Call) Loggable() []interface{} {
 	if c.State != nil {
 		props = append(props, "call_state", utils.LogDigest(c.State))
 	}
+	if c.Async {
+		props = append(props, "call_async", true)
+	}
+	if c.Timeout != 0 {
+		props = append(props, "call_timeout", c.Timeout)
+	}
 	return props
 }
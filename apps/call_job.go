@@ -0,0 +1,65 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type CallJobStatus string
+
+const (
+	// CallJobStatusRunning indicates that the upstream call is in progress.
+	CallJobStatusRunning CallJobStatus = "running"
+
+	// CallJobStatusComplete indicates that the call succeeded, Response
+	// contains the app's response.
+	CallJobStatusComplete CallJobStatus = "complete"
+
+	// CallJobStatusFailed indicates that the call failed, Response contains
+	// the error.
+	CallJobStatusFailed CallJobStatus = "failed"
+)
+
+// CallJob tracks an asynchronous call, see Call.Async. It is created by the
+// proxy when the call is submitted, and updated with the app's progress
+// reports and, eventually, the final response. Jobs expire a day after they
+// are created.
+type CallJob struct {
+	ID     string `json:"id"`
+	AppID  AppID  `json:"app_id"`
+	UserID string `json:"user_id"`
+	Path   string `json:"path"`
+
+	Status CallJobStatus `json:"status"`
+
+	// Progress is the percentage of the work done, as reported by the app, and
+	// Message is an optional, displayable description of the current step.
+	Progress int    `json:"progress,omitempty"`
+	Message  string `json:"message,omitempty"`
+
+	// Response is set once the job is no longer running.
+	Response *CallResponse `json:"response,omitempty"`
+
+	CreateAt int64 `json:"create_at"`
+	UpdateAt int64 `json:"update_at"`
+}
+
+// CallJobProgress is submitted by an app to report the progress of a running
+// CallJob.
+type CallJobProgress struct {
+	Progress int    `json:"progress"`
+	Message  string `json:"message,omitempty"`
+}
+
+func (p CallJobProgress) Validate() error {
+	if p.Progress < 0 || p.Progress > 100 {
+		return utils.NewInvalidError("progress must be between 0 and 100, got %v", p.Progress)
+	}
+	return nil
+}
+
+func (job CallJob) IsDone() bool {
+	return job.Status == CallJobStatusComplete || job.Status == CallJobStatusFailed
+}
@@ -33,6 +33,24 @@ type CallRequest struct {
 
 	// In the case of a lookup call, the query the user has typed in for autocomplete.
 	Query string `json:"query,omitempty"`
+
+	// JobID is set when the call is invoked asynchronously, see Call.Async.
+	// The app may use it to report progress with the UpdateCallJobProgress
+	// API.
+	JobID string `json:"job_id,omitempty"`
+
+	// IdempotencyKey identifies the user action that caused the call, so that
+	// repeating it, e.g. by double-clicking a submit button, does not invoke
+	// the app again. The calls with the same key made by the same user to the
+	// same app within a configured window get the same response. If not set
+	// by the client, a key is derived for the submissions of bindings and
+	// forms of the apps that set Manifest.DeduplicateSubmits.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// FormID is the ID of the form being submitted, as served to the user in a
+	// call response, see Form.ID. The submitted values are validated against
+	// the form before the call is made, and rejected if the form has expired.
+	FormID string `json:"form_id,omitempty"`
 }
 
 // UnmarshalJSON has to be defined since Call is embedded anonymously, and
@@ -48,11 +66,14 @@ func (creq *CallRequest) UnmarshalJSON(data []byte) error {
 	// Need a type that is just like CallRequest, but without Call to avoid
 	// recursion.
 	structValue := struct {
-		Values        map[string]interface{} `json:"values,omitempty"`
-		Context       Context                `json:"context,omitempty"`
-		RawCommand    string                 `json:"raw_command,omitempty"`
-		SelectedField string                 `json:"selected_field,omitempty"`
-		Query         string                 `json:"query,omitempty"`
+		Values         map[string]interface{} `json:"values,omitempty"`
+		Context        Context                `json:"context,omitempty"`
+		RawCommand     string                 `json:"raw_command,omitempty"`
+		SelectedField  string                 `json:"selected_field,omitempty"`
+		Query          string                 `json:"query,omitempty"`
+		JobID          string                 `json:"job_id,omitempty"`
+		IdempotencyKey string                 `json:"idempotency_key,omitempty"`
+		FormID         string                 `json:"form_id,omitempty"`
 	}{}
 	err = json.Unmarshal(data, &structValue)
 	if err != nil {
@@ -60,12 +81,15 @@ func (creq *CallRequest) UnmarshalJSON(data []byte) error {
 	}
 
 	*creq = CallRequest{
-		Call:          call,
-		Values:        structValue.Values,
-		Context:       structValue.Context,
-		RawCommand:    structValue.RawCommand,
-		SelectedField: structValue.SelectedField,
-		Query:         structValue.Query,
+		Call:           call,
+		Values:         structValue.Values,
+		Context:        structValue.Context,
+		RawCommand:     structValue.RawCommand,
+		SelectedField:  structValue.SelectedField,
+		Query:          structValue.Query,
+		JobID:          structValue.JobID,
+		IdempotencyKey: structValue.IdempotencyKey,
+		FormID:         structValue.FormID,
 	}
 	return nil
 }
@@ -161,5 +185,14 @@ func (creq CallRequest) Loggable() []interface{} {
 	if creq.Query != "" {
 		props = append(props, "query", creq.Query)
 	}
+	if creq.JobID != "" {
+		props = append(props, "job_id", creq.JobID)
+	}
+	if creq.IdempotencyKey != "" {
+		props = append(props, "idempotency_key", creq.IdempotencyKey)
+	}
+	if creq.FormID != "" {
+		props = append(props, "form_id", creq.FormID)
+	}
 	return props
 }
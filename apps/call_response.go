@@ -23,10 +23,17 @@ const (
 	// modal.
 	CallResponseTypeForm CallResponseType = "form"
 
-	// CallResponseTypeCall indicates that another Call that should be executed
-	// (all the way from the user-agent). Call is returned. NOT YET IMPLEMENTED.
+	// CallResponseTypeCall indicates that another Call should be executed.
+	// Call is returned. The proxy executes the follow-up call with the original
+	// user context, and returns its response instead. The number of follow-up
+	// calls is limited, and a call may not be repeated.
 	CallResponseTypeCall CallResponseType = "call"
 
+	// CallResponseTypeJob is returned by the proxy when an async call is
+	// started, see Call.Async. Job is returned, its status can be polled, and
+	// a websocket event is published to the acting user when it is done.
+	CallResponseTypeJob CallResponseType = "job"
+
 	// CallResponseTypeNavigate indicates that the user should be forcefully
 	// navigated to a URL, which may be a channel in Mattermost. NavigateToURL
 	// and UseExternalBrowser are expected to be returned.
@@ -38,8 +45,8 @@ const (
 // Submit requests expect ok, error, form, call, or navigate response types.
 // Returning a "form" type in response to a submission from the user-agent
 // triggers displaying a Modal. Returning a "call" type in response to a
-// submission causes the call to be executed from the user-agent (NOT
-// IMPLEMENTED YET)
+// submission causes the call to be executed by the proxy, and its response to
+// be returned to the user-agent.
 //
 // Form requests expect form or error.
 //
@@ -66,6 +73,9 @@ type CallResponse struct {
 
 	// Used in CallResponseTypeForm
 	Form *Form `json:"form,omitempty"`
+
+	// Used in CallResponseTypeJob
+	Job *CallJob `json:"job,omitempty"`
 }
 
 func NewErrorResponse(err error) CallResponse {
@@ -133,6 +143,12 @@ func (cresp CallResponse) String() string {
 	case CallResponseTypeCall:
 		return fmt.Sprintf("Call: %v", cresp.Call)
 
+	case CallResponseTypeJob:
+		if cresp.Job == nil {
+			return "Job: (none)"
+		}
+		return fmt.Sprintf("Job: %s", cresp.Job.ID)
+
 	case CallResponseTypeNavigate:
 		s := fmt.Sprintf("Navigate to: %q", cresp.NavigateToURL)
 		if cresp.UseExternalBrowser {
@@ -174,6 +190,11 @@ func (cresp CallResponse) Loggable() []interface{} {
 			props = append(props, "response_call", cresp.Call.String())
 		}
 
+	case CallResponseTypeJob:
+		if cresp.Job != nil {
+			props = append(props, "job_id", cresp.Job.ID)
+		}
+
 	case CallResponseTypeNavigate:
 		props = append(props, "response_url", cresp.NavigateToURL)
 		if cresp.UseExternalBrowser {
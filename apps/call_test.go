@@ -21,7 +21,9 @@ func TestUnmarshalCall(t *testing.T) {
 		},
 		"expand": {
 			"acting_user": "all"
-		}
+		},
+		"async": true,
+		"timeout": 90000
 	}
 	`
 
@@ -36,6 +38,8 @@ func TestUnmarshalCall(t *testing.T) {
 		Expand: &apps.Expand{
 			ActingUser: apps.ExpandAll,
 		},
+		Async:   true,
+		Timeout: 90000,
 	}, c)
 
 	const short = `"/test"`
@@ -48,6 +52,9 @@ func TestUnmarshalCall(t *testing.T) {
 func TestUnmarshalCallRequest(t *testing.T) {
 	const payload = `
 	{
+		"job_id": "rdc9kpjbwbyxfpqp7o3zk0m1ra",
+		"idempotency_key": "submit-1",
+		"form_id": "form-1",
 		"context": {
 			"team_id": "9pu8hstcpigm5x4dboe6hz9ddw",
 			"mattermost_site_url": "https://some.test"
@@ -65,6 +72,9 @@ func TestUnmarshalCallRequest(t *testing.T) {
 	data, err := apps.CallRequestFromJSON([]byte(payload))
 
 	require.NoError(t, err)
+	require.Equal(t, "rdc9kpjbwbyxfpqp7o3zk0m1ra", data.JobID)
+	require.Equal(t, "submit-1", data.IdempotencyKey)
+	require.Equal(t, "form-1", data.FormID)
 	require.Equal(t, "9pu8hstcpigm5x4dboe6hz9ddw", data.Context.TeamID)
 	require.Equal(t, "https://some.test", data.Context.MattermostSiteURL)
 	require.Equal(t, "cywc3e8nebyujrpuip98t69a3h", data.Values["secret"])
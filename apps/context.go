@@ -85,6 +85,19 @@ type ExpandedContext struct {
 	// BotUserID of the App.
 	BotUserID string `json:"bot_user_id"`
 
+	// RequestID is the ID of the apps plugin request that caused the call. It
+	// is included in the plugin's log messages, and can be used to correlate
+	// them with the app's. It is also sent in the RequestIDHeader.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Deadline is the time by which the app must respond to the call, in Unix
+	// milliseconds. The remaining time is also sent in the TimeoutHeader.
+	Deadline int64 `json:"deadline,omitempty"`
+
+	// CallerAppID is the app that invoked the call on behalf of the acting
+	// user, if the call was made by another app rather than by the user.
+	CallerAppID AppID `json:"caller_app_id,omitempty"`
+
 	// BotAccessToken is always provided in expanded context.
 	BotAccessToken string `json:"bot_access_token,omitempty"`
 	App            *App   `json:"app,omitempty"`
@@ -93,12 +106,17 @@ type ExpandedContext struct {
 	ActingUserAccessToken string               `json:"acting_user_access_token,omitempty"`
 	Locale                string               `json:"locale,omitempty"`
 	Channel               *model.Channel       `json:"channel,omitempty"`
+	PreviousChannel       *model.Channel       `json:"previous_channel,omitempty"`
 	ChannelMember         *model.ChannelMember `json:"channel_member,omitempty"`
 	Team                  *model.Team          `json:"team,omitempty"`
 	TeamMember            *model.TeamMember    `json:"team_member,omitempty"`
 	Post                  *model.Post          `json:"post,omitempty"`
 	RootPost              *model.Post          `json:"root_post,omitempty"`
 
+	// Reaction is the subject of reaction_added and reaction_removed
+	// notifications, it is included without having to be expanded.
+	Reaction *model.Reaction `json:"reaction,omitempty"`
+
 	// TODO replace User with mentions
 	User *model.User `json:"user,omitempty"`
 
@@ -140,6 +158,30 @@ func (c Context) Loggable() []interface{} {
 	return props
 }
 
+// Redacted returns a copy of the context with the secrets redacted as in the
+// log messages: the access tokens and the remote OAuth2 client credentials are
+// reduced to their last 4 characters, and the app's and the user's OAuth2 data
+// are replaced with "(private)".
+func (c Context) Redacted() Context {
+	e := &c.ExpandedContext
+	redact := func(s *string) {
+		if *s != "" {
+			*s = utils.LastN(*s, 4)
+		}
+	}
+	redact(&e.ActingUserAccessToken)
+	redact(&e.BotAccessToken)
+	redact(&e.OAuth2.OAuth2App.ClientID)
+	redact(&e.OAuth2.OAuth2App.ClientSecret)
+	if e.OAuth2.OAuth2App.Data != nil {
+		e.OAuth2.OAuth2App.Data = "(private)"
+	}
+	if e.OAuth2.User != nil {
+		e.OAuth2.User = "(private)"
+	}
+	return c
+}
+
 func (c Context) loggable() (map[string]string, []interface{}) {
 	display := map[string]string{}
 	props := []interface{}{}
@@ -181,6 +223,11 @@ func (c Context) loggable() (map[string]string, []interface{}) {
 		props = append(props, "root_post_id", c.ExpandedContext.RootPost.Id)
 	}
 
+	if c.ExpandedContext.Reaction != nil {
+		display["reaction"] = c.ExpandedContext.Reaction.EmojiName
+		props = append(props, "reaction", c.ExpandedContext.Reaction.EmojiName)
+	}
+
 	if c.ExpandedContext.BotUserID != "" {
 		display["bot_user_id"] = c.ExpandedContext.BotUserID
 		props = append(props, "bot_user_id", c.ExpandedContext.BotUserID)
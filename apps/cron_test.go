@@ -0,0 +1,146 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	t.Parallel()
+
+	for expr, valid := range map[string]bool{
+		"* * * * *":         true,
+		"*/15 * * * *":      true,
+		"0 9-17 * * 1-5":    true,
+		"0,30 0 1,15 * *":   true,
+		"5/10 * * * *":      true,
+		"0 0 * * 7":         true,
+		"@daily":            true,
+		"@HOURLY":           true,
+		"":                  false,
+		"* * * *":           false,
+		"* * * * * *":       false,
+		"60 * * * *":        false,
+		"* 24 * * *":        false,
+		"* * 0 * *":         false,
+		"* * * 13 *":        false,
+		"* * * * 8":         false,
+		"*/0 * * * *":       false,
+		"5-1 * * * *":       false,
+		"a * * * *":         false,
+		"@sometimes":        false,
+		"1-a * * * *":       false,
+		"0 0 1 1 * extra":   false,
+		"  0  0  1  1  *  ": true,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := apps.ParseCronExpression(expr)
+			if valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCronExpressionNext(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday.
+	base := time.Date(2022, time.June, 15, 10, 17, 30, 0, time.UTC)
+	for name, tc := range map[string]struct {
+		expr     string
+		expected time.Time
+	}{
+		"every minute": {
+			expr:     "* * * * *",
+			expected: time.Date(2022, time.June, 15, 10, 18, 0, 0, time.UTC),
+		},
+		"every 15 minutes": {
+			expr:     "*/15 * * * *",
+			expected: time.Date(2022, time.June, 15, 10, 30, 0, 0, time.UTC),
+		},
+		"hourly": {
+			expr:     "@hourly",
+			expected: time.Date(2022, time.June, 15, 11, 0, 0, 0, time.UTC),
+		},
+		"daily": {
+			expr:     "@daily",
+			expected: time.Date(2022, time.June, 16, 0, 0, 0, 0, time.UTC),
+		},
+		"weekly on Sunday": {
+			expr:     "@weekly",
+			expected: time.Date(2022, time.June, 19, 0, 0, 0, 0, time.UTC),
+		},
+		"Sunday as 7": {
+			expr:     "30 8 * * 7",
+			expected: time.Date(2022, time.June, 19, 8, 30, 0, 0, time.UTC),
+		},
+		"monthly": {
+			expr:     "@monthly",
+			expected: time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"yearly": {
+			expr:     "@yearly",
+			expected: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"business hours": {
+			expr:     "0 9-17 * * 1-5",
+			expected: time.Date(2022, time.June, 15, 11, 0, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			expr:     "0 0 20 * 5",
+			expected: time.Date(2022, time.June, 17, 0, 0, 0, 0, time.UTC),
+		},
+		"leap day": {
+			expr:     "0 0 29 2 *",
+			expected: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"never": {
+			expr:     "0 0 30 2 *",
+			expected: time.Time{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := apps.ParseCronExpression(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, c.Next(base))
+		})
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	t.Parallel()
+
+	call := *apps.NewCall("/scheduled")
+	for name, tc := range map[string]struct {
+		schedule apps.Schedule
+		valid    bool
+	}{
+		"cron":             {apps.Schedule{Call: call, Cron: "@daily"}, true},
+		"at":               {apps.Schedule{Call: call, At: 1655288250000}, true},
+		"no call":          {apps.Schedule{Cron: "@daily"}, false},
+		"neither":          {apps.Schedule{Call: call}, false},
+		"both":             {apps.Schedule{Call: call, Cron: "@daily", At: 1655288250000}, false},
+		"invalid cron":     {apps.Schedule{Call: call, Cron: "@never"}, false},
+		"invalid cron too": {apps.Schedule{Call: call, Cron: "61 * * * *"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.schedule.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -105,6 +105,11 @@ type Expand struct {
 	// Id only.
 	Channel ExpandLevel `json:"channel,omitempty"`
 
+	// PreviousChannel (default: none, optional): the channel as it was before
+	// the change, in the channel_archived, channel_updated, and
+	// channel_converted notifications. Same levels as Channel.
+	PreviousChannel ExpandLevel `json:"previous_channel,omitempty"`
+
 	// ChannelMember (default: none, optional): expand model.ChannelMember if
 	// ChannelID and ActingUserID (or UserID) are set. if both ActingUserID and
 	// UserID are set, it expands UserID, as may be relevant in
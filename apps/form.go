@@ -57,6 +57,13 @@ type Form struct {
 
 	// Fields is the list of fields in the form.
 	Fields []Field `json:"fields,omitempty"`
+
+	// ID is assigned by the proxy to the forms served to the users in call
+	// responses and bindings. The clients pass it back in CallRequest.FormID
+	// when the form is submitted, so that the submission is validated against
+	// that form. Submissions without it are validated against the forms served
+	// to the user with the same submit path.
+	ID string `json:"id,omitempty"`
 }
 
 func (f *Form) UnmarshalJSON(data []byte) error {
@@ -81,6 +88,7 @@ func (f *Form) UnmarshalJSON(data []byte) error {
 		Submit        *Call   `json:"submit,omitempty"`
 		SubmitButtons string  `json:"submit_buttons,omitempty"`
 		Fields        []Field `json:"fields,omitempty"`
+		ID            string  `json:"id,omitempty"`
 	}{}
 	err = json.Unmarshal(data, &structValue)
 	if err != nil {
@@ -96,6 +104,7 @@ func (f *Form) UnmarshalJSON(data []byte) error {
 		Submit:        structValue.Submit,
 		SubmitButtons: structValue.SubmitButtons,
 		Fields:        structValue.Fields,
+		ID:            structValue.ID,
 	}
 	return nil
 }
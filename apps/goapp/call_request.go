@@ -2,6 +2,9 @@ package goapp
 
 import (
 	"context"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/apps/appclient"
@@ -16,6 +19,31 @@ type CallRequest struct {
 	asBot        *appclient.Client `json:"-"`
 	asActingUser *appclient.Client `json:"-"`
 	Log          utils.Logger      `json:"-"`
+
+	// RequestID is the ID of the apps plugin request that caused the call,
+	// it is included in Log to correlate the app's logs with the server's.
+	RequestID string `json:"-"`
+
+	// Deadline is when the server stops waiting for the response, it is also
+	// applied to GoContext. It is zero if the server did not set one.
+	Deadline time.Time `json:"-"`
+}
+
+// requestIDAndDeadline returns the request ID and the deadline of a call, from
+// its context, or if not expanded there, from the headers of the HTTP request.
+func requestIDAndDeadline(cc apps.Context, header http.Header) (string, time.Time) {
+	requestID := cc.RequestID
+	if requestID == "" {
+		requestID = header.Get(apps.RequestIDHeader)
+	}
+
+	var deadline time.Time
+	if cc.Deadline != 0 {
+		deadline = time.UnixMilli(cc.Deadline)
+	} else if timeout, err := strconv.ParseInt(header.Get(apps.TimeoutHeader), 10, 64); err == nil {
+		deadline = time.Now().Add(time.Duration(timeout) * time.Millisecond)
+	}
+	return requestID, deadline
 }
 
 func (creq CallRequest) AsBot() *appclient.Client {
@@ -2,7 +2,9 @@ package goapp
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -39,3 +41,36 @@ func TestCallRequestJSON(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, json1, json2)
 }
+
+func TestRequestIDAndDeadline(t *testing.T) {
+	deadline := time.Now().Add(10 * time.Second).Truncate(time.Millisecond)
+
+	t.Run("from context", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(apps.RequestIDHeader, "from-header")
+		header.Set(apps.TimeoutHeader, "1000")
+		requestID, d := requestIDAndDeadline(apps.Context{
+			ExpandedContext: apps.ExpandedContext{
+				RequestID: "from-context",
+				Deadline:  deadline.UnixMilli(),
+			},
+		}, header)
+		require.Equal(t, "from-context", requestID)
+		require.True(t, deadline.Equal(d))
+	})
+
+	t.Run("from headers", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(apps.RequestIDHeader, "from-header")
+		header.Set(apps.TimeoutHeader, "10000")
+		requestID, d := requestIDAndDeadline(apps.Context{}, header)
+		require.Equal(t, "from-header", requestID)
+		require.WithinDuration(t, deadline, d, time.Second)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		requestID, d := requestIDAndDeadline(apps.Context{}, http.Header{})
+		require.Empty(t, requestID)
+		require.True(t, d.IsZero())
+	})
+}
@@ -1,6 +1,7 @@
 package goapp
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"path"
@@ -32,7 +33,16 @@ func (app *App) HandleCall(p string, h HandlerFunc) {
 			return
 		}
 		creq.App = app
+		creq.RequestID, creq.Deadline = requestIDAndDeadline(creq.Context, req.Header)
+		if !creq.Deadline.IsZero() {
+			ctx, cancel := context.WithDeadline(creq.GoContext, creq.Deadline)
+			defer cancel()
+			creq.GoContext = ctx
+		}
 		creq.Log = app.log.With(creq)
+		if creq.RequestID != "" {
+			creq.Log = creq.Log.With("request_id", creq.RequestID)
+		}
 
 		cresp := h(creq)
 		if cresp.Type == apps.CallResponseTypeError {
@@ -6,10 +6,21 @@ package apps
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+const (
+	// RequestIDHeader carries Context.RequestID in the calls to the apps.
+	RequestIDHeader = "Mattermost-App-Request-Id"
+
+	// TimeoutHeader carries the time remaining until Context.Deadline, in
+	// milliseconds, in the calls to the apps.
+	TimeoutHeader = "Mattermost-App-Timeout"
+)
+
 // HTTPCallRequest is a scoped down version of
 // https://pkg.go.dev/github.com/aws/aws-lambda-go@v1.13.3/events#APIGatewayProxyRequest
 type HTTPCallRequest struct {
@@ -46,10 +57,12 @@ func (creq CallRequest) ToHTTPCallRequestJSON() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to encode HTTP request as JSON")
 	}
+	headers := creq.CallHeaders()
+	headers["Content-Type"] = "application/json"
 	request := HTTPCallRequest{
 		Path:       creq.Path,
 		HTTPMethod: http.MethodPost,
-		Headers:    map[string]string{"Content-Type": "application/json"},
+		Headers:    headers,
 		Body:       string(body),
 	}
 	payload, err := json.Marshal(request)
@@ -58,3 +71,20 @@ func (creq CallRequest) ToHTTPCallRequestJSON() ([]byte, error) {
 	}
 	return payload, nil
 }
+
+// CallHeaders returns the request ID and the remaining timeout headers to send
+// to the app along with the call.
+func (creq CallRequest) CallHeaders() map[string]string {
+	headers := map[string]string{}
+	if creq.Context.RequestID != "" {
+		headers[RequestIDHeader] = creq.Context.RequestID
+	}
+	if creq.Context.Deadline != 0 {
+		remaining := time.Until(time.UnixMilli(creq.Context.Deadline)).Milliseconds()
+		if remaining < 0 {
+			remaining = 0
+		}
+		headers[TimeoutHeader] = strconv.FormatInt(remaining, 10)
+	}
+	return headers
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestToHTTPCallRequestJSON(t *testing.T) {
+	t.Run("with request ID and deadline", func(t *testing.T) {
+		creq := apps.CallRequest{
+			Call: *apps.NewCall("/test"),
+			Context: apps.Context{
+				ExpandedContext: apps.ExpandedContext{
+					RequestID: "request-id",
+					Deadline:  time.Now().Add(5 * time.Second).UnixMilli(),
+				},
+			},
+		}
+		data, err := creq.ToHTTPCallRequestJSON()
+		require.NoError(t, err)
+
+		req := apps.HTTPCallRequest{}
+		require.NoError(t, json.Unmarshal(data, &req))
+		require.Equal(t, "/test", req.Path)
+		require.Equal(t, "application/json", req.Headers["Content-Type"])
+		require.Equal(t, "request-id", req.Headers[apps.RequestIDHeader])
+		timeout, err := strconv.Atoi(req.Headers[apps.TimeoutHeader])
+		require.NoError(t, err)
+		require.InDelta(t, 5000, timeout, 1000)
+	})
+
+	t.Run("past deadline", func(t *testing.T) {
+		creq := apps.CallRequest{
+			Context: apps.Context{
+				ExpandedContext: apps.ExpandedContext{
+					Deadline: time.Now().Add(-time.Second).UnixMilli(),
+				},
+			},
+		}
+		require.Equal(t, map[string]string{apps.TimeoutHeader: "0"}, creq.CallHeaders())
+	})
+
+	t.Run("without", func(t *testing.T) {
+		require.Empty(t, apps.CallRequest{}.CallHeaders())
+	})
+}
@@ -166,3 +166,36 @@ func TestLoggable(t *testing.T) {
 		})
 	}
 }
+
+func TestContextRedacted(t *testing.T) {
+	cc := Context{
+		ExpandedContext: ExpandedContext{
+			BotUserID:             "id_of_bot_user",
+			BotAccessToken:        "bot_user_access_tokenXYZ",
+			ActingUserAccessToken: "acting_user_access_tokenXYZ",
+			OAuth2: OAuth2Context{
+				OAuth2App: OAuth2App{
+					RemoteRootURL: "https://remote.test",
+					ClientID:      "client_idXYZ",
+					ClientSecret:  "client_secretXYZ",
+					Data:          map[string]interface{}{"key": "secret"},
+				},
+				User: map[string]interface{}{"token": "secret"},
+			},
+		},
+	}
+
+	redacted := cc.Redacted()
+	require.Equal(t, "id_of_bot_user", redacted.BotUserID)
+	require.Equal(t, "***nXYZ", redacted.BotAccessToken)
+	require.Equal(t, "***nXYZ", redacted.ActingUserAccessToken)
+	require.Equal(t, "https://remote.test", redacted.OAuth2.RemoteRootURL)
+	require.Equal(t, "***dXYZ", redacted.OAuth2.ClientID)
+	require.Equal(t, "***tXYZ", redacted.OAuth2.ClientSecret)
+	require.Equal(t, "(private)", redacted.OAuth2.Data)
+	require.Equal(t, "(private)", redacted.OAuth2.User)
+
+	// The original is not modified.
+	require.Equal(t, "bot_user_access_tokenXYZ", cc.BotAccessToken)
+	require.Equal(t, map[string]interface{}{"token": "secret"}, cc.OAuth2.User)
+}
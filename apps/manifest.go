@@ -5,6 +5,7 @@ package apps
 
 import (
 	"encoding/json"
+	"strings"
 	"unicode"
 
 	"github.com/hashicorp/go-multierror"
@@ -117,6 +118,14 @@ type Manifest struct {
 	// is passed to the call serialized as HTTPCallRequest (JSON).
 	OnRemoteWebhook *Call `json:"on_remote_webhook,omitempty"`
 
+	// OnSubscriptionRemoved gets invoked when one of the App's subscriptions
+	// is removed because its owner has been deactivated, or no longer has the
+	// permission to subscribe to the event. The removed subscription, its
+	// owner, and the reason are passed as "subscription", "owner_user_id", and
+	// "reason" Values. It is not called unless explicitly provided in the
+	// manifest.
+	OnSubscriptionRemoved *Call `json:"on_subscription_removed,omitempty"`
+
 	// Requested Access
 	RequestedPermissions Permissions `json:"requested_permissions,omitempty"`
 
@@ -129,6 +138,53 @@ type Manifest struct {
 	// "/command/apptrigger"}``.
 	RequestedLocations Locations `json:"requested_locations,omitempty"`
 
+	// Subscriptions are created when the App is installed, and are owned by
+	// the App's bot user. They are reconciled with the manifest when the App's
+	// version changes, and are removed when the App is uninstalled. The
+	// install fails if any of them can not be created, or references a team
+	// or a channel that does not exist on the Mattermost server.
+	Subscriptions []Subscription `json:"subscriptions,omitempty"`
+
+	// CallTimeout is the time, in milliseconds, that the calls to the App are
+	// given to complete, unless the Call specifies its own Timeout. It is
+	// capped by the maximum configured by the administrator. If not set, calls
+	// time out after 30 seconds. It does not apply to pings, they always time
+	// out after 1 second.
+	CallTimeout int `json:"call_timeout,omitempty"`
+
+	// RestrictUserCalls opts the App in to rejecting the calls made by the
+	// users to the paths other than UserCallPaths, and the paths of the
+	// bindings and forms that have been served to the user. Without it, users
+	// may invoke any of the App's paths.
+	RestrictUserCalls bool `json:"restrict_user_calls,omitempty"`
+
+	// UserCallPaths are the call paths that users may invoke directly if
+	// RestrictUserCalls is set, in addition to the paths of the App's bindings
+	// and forms, which are allowed automatically once served to the user, and
+	// of the in-post bindings created by the App's bot, once posted. It is
+	// needed for any calls made by the users' clients outside of the bindings
+	// and forms. A path ending with "/*" allows all paths that start with its
+	// prefix.
+	UserCallPaths []string `json:"user_call_paths,omitempty"`
+
+	// DeduplicateSubmits opts the App in to de-duplicating the submissions of
+	// its bindings and forms that the clients send without an idempotency key,
+	// see CallRequest.IdempotencyKey. A submission repeated by the same user
+	// with the same values within the configured window gets the response to
+	// the first one, and is not sent to the App. Commands are not
+	// de-duplicated.
+	DeduplicateSubmits bool `json:"deduplicate_submits,omitempty"`
+
+	// AsyncCallPaths are the call paths that may be invoked asynchronously, see
+	// Call.Async, in the same format as UserCallPaths. Async calls to other
+	// paths are rejected.
+	AsyncCallPaths []string `json:"async_call_paths,omitempty"`
+
+	// AppCallers are the other apps that may invoke the App's calls, and the
+	// paths they may invoke. The calling apps must also be granted the
+	// call_apps permission.
+	AppCallers []AppCaller `json:"app_callers,omitempty"`
+
 	// Deployment information
 	Deploy
 
@@ -140,6 +196,50 @@ type Manifest struct {
 	v7AppType string
 }
 
+// AppCaller allows another app to invoke some of the App's calls.
+type AppCaller struct {
+	AppID AppID `json:"app_id"`
+
+	// Paths that the app may invoke, in the same format as UserCallPaths.
+	Paths []string `json:"paths"`
+}
+
+// AllowsUserCallPath returns true if the path is declared in UserCallPaths.
+func (m Manifest) AllowsUserCallPath(callPath string) bool {
+	return callPathAllowed(m.UserCallPaths, callPath)
+}
+
+// AllowsAsyncCallPath returns true if the path is declared in AsyncCallPaths.
+func (m Manifest) AllowsAsyncCallPath(callPath string) bool {
+	return callPathAllowed(m.AsyncCallPaths, callPath)
+}
+
+// AllowsAppCall returns true if the path is declared in AppCallers for the
+// calling app.
+func (m Manifest) AllowsAppCall(callerAppID AppID, callPath string) bool {
+	for _, caller := range m.AppCallers {
+		if caller.AppID == callerAppID && callPathAllowed(caller.Paths, callPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func callPathAllowed(allowedPaths []string, callPath string) bool {
+	for _, allowed := range allowedPaths {
+		if prefix := strings.TrimSuffix(allowed, "*"); prefix != allowed && strings.HasSuffix(prefix, "/") {
+			if strings.HasPrefix(callPath, prefix) {
+				return true
+			}
+			continue
+		}
+		if callPath == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 // DecodeCompatibleManifest decodes any known version of manifest.json into the
 // current format. Since App embeds Manifest anonymously, it appears impossible
 // to implement json.Unmarshaler without introducing all kinds of complexities.
@@ -194,6 +294,45 @@ func (m Manifest) Validate() error {
 		}
 	}
 
+	if m.CallTimeout < 0 {
+		result = multierror.Append(result,
+			utils.NewInvalidError("call_timeout must not be negative"))
+	}
+
+	for _, callPath := range m.UserCallPaths {
+		if !strings.HasPrefix(callPath, "/") {
+			result = multierror.Append(result,
+				utils.NewInvalidError("user_call_paths: %q must start with a %q", callPath, "/"))
+		}
+	}
+
+	for _, callPath := range m.AsyncCallPaths {
+		if !strings.HasPrefix(callPath, "/") {
+			result = multierror.Append(result,
+				utils.NewInvalidError("async_call_paths: %q must start with a %q", callPath, "/"))
+		}
+	}
+
+	for _, caller := range m.AppCallers {
+		if err := caller.AppID.Validate(); err != nil {
+			result = multierror.Append(result,
+				utils.NewInvalidError("app_callers: %v", err))
+		}
+		for _, callPath := range caller.Paths {
+			if !strings.HasPrefix(callPath, "/") {
+				result = multierror.Append(result,
+					utils.NewInvalidError("app_callers: %s: %q must start with a %q", caller.AppID, callPath, "/"))
+			}
+		}
+	}
+
+	for _, sub := range m.Subscriptions {
+		if err := sub.Validate(); err != nil {
+			result = multierror.Append(result,
+				utils.NewInvalidError("subscription %s invalid: %v", sub.Event, err))
+		}
+	}
+
 	for _, v := range []validator{
 		m.AppID,
 		m.Version,
@@ -6,6 +6,7 @@ package apps_test
 import (
 	"testing"
 
+	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
@@ -155,6 +156,166 @@ func TestValidateManifest(t *testing.T) {
 			},
 			ExpectedError: true,
 		},
+		"valid subscriptions": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				Subscriptions: []apps.Subscription{
+					{
+						Event: apps.Event{Subject: apps.SubjectBotMentioned},
+						Call:  *apps.NewCall("/bot-mentioned"),
+					},
+					{
+						Event: apps.Event{Subject: apps.SubjectChannelCreated, TeamID: "team-id"},
+						Call:  *apps.NewCall("/channel-created"),
+					},
+				},
+			},
+			ExpectedError: false,
+		},
+		"subscription without a call": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				Subscriptions: []apps.Subscription{
+					{
+						Event: apps.Event{Subject: apps.SubjectBotMentioned},
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"subscription with an invalid scope": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				Subscriptions: []apps.Subscription{
+					{
+						Event: apps.Event{Subject: apps.SubjectUserCreated, TeamID: "team-id"},
+						Call:  *apps.NewCall("/user-created"),
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"subscription with an invalid filter": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				Subscriptions: []apps.Subscription{
+					{
+						Event: apps.Event{Subject: apps.SubjectUserCreated},
+						Call:  *apps.NewCall("/user-created"),
+						Filter: &apps.SubscriptionFilter{
+							ChannelTypes: []model.ChannelType{"X"},
+						},
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"call timeout": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				CallTimeout: 120000,
+			},
+			ExpectedError: false,
+		},
+		"negative call timeout": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				CallTimeout: -1,
+			},
+			ExpectedError: true,
+		},
+		"user call paths": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				UserCallPaths: []string{"/create", "/admin/*"},
+			},
+			ExpectedError: false,
+		},
+		"relative user call path": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				UserCallPaths: []string{"create"},
+			},
+			ExpectedError: true,
+		},
+		"app callers": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				AppCallers: []apps.AppCaller{
+					{AppID: "tickets", Paths: []string{"/page/*"}},
+				},
+			},
+			ExpectedError: false,
+		},
+		"invalid app caller": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+				AppCallers: []apps.AppCaller{
+					{AppID: "t", Paths: []string{"page"}},
+				},
+			},
+			ExpectedError: true,
+		},
 		"no lambda for AWS app": {
 			Manifest: apps.Manifest{
 				AppID:       "abc",
@@ -258,3 +419,51 @@ func TestValidateManifest(t *testing.T) {
 		})
 	}
 }
+
+func TestManifestAllowsUserCallPath(t *testing.T) {
+	m := apps.Manifest{
+		UserCallPaths: []string{"/create", "/admin/*"},
+	}
+	for callPath, expected := range map[string]bool{
+		"/create":          true,
+		"/create/more":     false,
+		"/admin":           false,
+		"/admin/":          true,
+		"/admin/configure": true,
+		"/administer":      false,
+		"/on_install":      false,
+	} {
+		t.Run(callPath, func(t *testing.T) {
+			assert.Equal(t, expected, m.AllowsUserCallPath(callPath))
+		})
+	}
+
+	all := apps.Manifest{UserCallPaths: []string{"/*"}}
+	assert.True(t, all.AllowsUserCallPath("/on_install"))
+	assert.False(t, apps.Manifest{}.AllowsUserCallPath("/create"))
+}
+
+func TestManifestAllowsAsyncCallPath(t *testing.T) {
+	m := apps.Manifest{
+		AsyncCallPaths: []string{"/export", "/reports/*"},
+	}
+	assert.True(t, m.AllowsAsyncCallPath("/export"))
+	assert.True(t, m.AllowsAsyncCallPath("/reports/weekly"))
+	assert.False(t, m.AllowsAsyncCallPath("/create"))
+	assert.False(t, apps.Manifest{}.AllowsAsyncCallPath("/export"))
+}
+
+func TestManifestAllowsAppCall(t *testing.T) {
+	m := apps.Manifest{
+		AppCallers: []apps.AppCaller{
+			{AppID: "tickets", Paths: []string{"/page/*", "/status"}},
+			{AppID: "crm", Paths: []string{"/status"}},
+		},
+	}
+	assert.True(t, m.AllowsAppCall("tickets", "/page/form"))
+	assert.True(t, m.AllowsAppCall("tickets", "/status"))
+	assert.True(t, m.AllowsAppCall("crm", "/status"))
+	assert.False(t, m.AllowsAppCall("crm", "/page/form"))
+	assert.False(t, m.AllowsAppCall("other", "/status"))
+	assert.False(t, m.AllowsAppCall("tickets", "/on_install"))
+}
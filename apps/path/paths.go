@@ -16,9 +16,13 @@ const (
 	OAuth2User        = "/oauth2/user"
 	Subscribe         = "/subscribe"
 	Unsubscribe       = "/unsubscribe"
+	Schedule          = "/schedule"
+	CallJobProgress   = "/progress"
 
 	// Invoke.
-	Call = "/call"
+	Call    = "/call"
+	CallJob = "/call-job"
+	AppCall = "/app-call"
 
 	// Administration.
 	EnableApp        = "/enable-app"
@@ -30,6 +34,12 @@ const (
 	// Marketplace and local manifest store.
 	Marketplace = "/marketplace"
 
+	// Operational metrics, in the Prometheus text format.
+	Metrics = "/metrics"
+
+	// Audit log of the calls and the app lifecycle actions.
+	Audit = "/audit"
+
 	// APIs for user agents.
 	BotIDs      = "/bot-ids"
 	OAuthAppIDs = "/oauth-app-ids"
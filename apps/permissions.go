@@ -33,6 +33,11 @@ const (
 	// PermissionRemoteWebhooks means that the app is allowed to receive webhooks from a remote (3rd
 	// party) system, and process them as Bot.
 	PermissionRemoteWebhooks Permission = "remote_webhooks"
+
+	// PermissionCallApps means that the app is allowed to invoke the calls of
+	// other apps, on behalf of the acting user. The other apps must allow it
+	// in their manifest's AppCallers.
+	PermissionCallApps Permission = "call_apps"
 )
 
 func (p Permissions) Contains(permission Permission) bool {
@@ -57,6 +62,8 @@ func (p Permission) String() string {
 		m = "use a remote (3rd party) OAuth2 and store secrets"
 	case PermissionRemoteWebhooks:
 		m = "receive webhook messages from a remote (3rd party) system"
+	case PermissionCallApps:
+		m = "invoke the calls of other apps that allow it"
 	default:
 		m = "unknown permission: " + string(p)
 	}
@@ -0,0 +1,73 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// Schedule is submitted by an app to the Schedule API, to have a call invoked
+// at a specific time, or on a recurring basis. Scheduled calls are invoked once
+// across the cluster, with the app's bot as the acting user.
+type Schedule struct {
+	// ID is assigned when the schedule is created.
+	ID string `json:"id,omitempty"`
+
+	// AppID is the app that created the schedule, set by the server.
+	AppID AppID `json:"app_id,omitempty"`
+
+	// Call is the call to invoke.
+	Call Call `json:"call"`
+
+	// Cron is a recurring schedule, as a standard 5-field cron expression
+	// evaluated in UTC, see CronExpression. At is the time of a one-off call,
+	// in Unix milliseconds. Exactly one of the two must be set.
+	Cron string `json:"cron,omitempty"`
+	At   int64  `json:"at,omitempty"`
+
+	// NextRunAt, LastRunAt, and LastError are maintained by the server.
+	NextRunAt int64  `json:"next_run_at,omitempty"`
+	LastRunAt int64  `json:"last_run_at,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+func (s Schedule) Validate() error {
+	var result error
+	emptyCall := Call{}
+	if s.Call == emptyCall {
+		result = multierror.Append(result, utils.NewInvalidError("call must not be empty"))
+	}
+
+	switch {
+	case s.Cron != "" && s.At != 0:
+		result = multierror.Append(result, utils.NewInvalidError("only one of cron and at may be set"))
+	case s.Cron != "":
+		if _, err := ParseCronExpression(s.Cron); err != nil {
+			result = multierror.Append(result, err)
+		}
+	case s.At == 0:
+		result = multierror.Append(result, utils.NewInvalidError("cron or at must be set"))
+	}
+	return result
+}
+
+// Next returns the time of the next run after t, or the zero time if there is
+// none.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.Cron == "" {
+		if s.LastRunAt != 0 {
+			return time.Time{}
+		}
+		return time.UnixMilli(s.At)
+	}
+	c, err := ParseCronExpression(s.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	return c.Next(t)
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 
+	"github.com/mattermost/mattermost-server/v6/model"
+
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
@@ -22,6 +24,21 @@ const (
 	//   Requires: model.PermissionViewMembers.
 	SubjectUserCreated Subject = "user_created"
 
+	// SubjectUserDeactivated, SubjectUserReactivated, SubjectUserUpdated,
+	// SubjectUserRoleChanged: system-wide watch for changes to users. There
+	// are no plugin hooks for these events, so the changes are detected by
+	// periodically comparing the users' UpdateAt, DeleteAt and Roles, and the
+	// notifications may be delayed by up to a few minutes. A change to the
+	// user's roles is reported as user_role_changed rather than user_updated.
+	//   TeamID: must be empty.
+	//   ChannelID must be empty.
+	//   Expandable: User.
+	//   Requires: model.PermissionViewMembers.
+	SubjectUserDeactivated Subject = "user_deactivated"
+	SubjectUserReactivated Subject = "user_reactivated"
+	SubjectUserUpdated     Subject = "user_updated"
+	SubjectUserRoleChanged Subject = "user_role_changed"
+
 	// SubjectUserJoinedChannel, SubjectUserLeftChannel watch the specified
 	// channel for users joining and leaving it.
 	//   TeamID: must be empty.
@@ -72,18 +89,71 @@ const (
 	//   Requires: model.PermissionListTeamChannels.
 	SubjectChannelCreated Subject = "channel_created"
 
-	// TODO: re-enable post_created and bot_mentioned once perf issues are
-	// resolved, see https://mattermost.atlassian.net/browse/MM-44388
+	// SubjectChannelArchived, SubjectChannelRestored watch for channels in the
+	// specified team being archived, and unarchived.
+	//   TeamID: specifies the team to watch.
+	//   ChannelID: must be empty, all channels are watched.
+	//   Expandable: Channel, PreviousChannel (archived only), Team, User (the
+	//   user who made the change), ChannelMember, TeamMember.
+	//   Requires: model.PermissionListTeamChannels. For private channels,
+	//   notifications are only sent if the subscriber can read the channel.
+	SubjectChannelArchived Subject = "channel_archived"
+	SubjectChannelRestored Subject = "channel_restored"
 
-	// SubjectPostCreated subscribes to MessageHasBeenPosted plugin events, for
-	// the specified channel. By default notifications include UserID (author), PostID,
-	// RootPostID, ChannelID, but only Post is fully expanded. Expand can be
-	// used to expand other entities.
-	// SubjectPostCreated Subject = "post_created"
+	// SubjectChannelUpdated watches for changes to the display name, header,
+	// or purpose of channels in the specified team. Each notification is for
+	// a single field. PreviousChannel is the channel as currently stored, with
+	// only that field - DisplayName, Header, or Purpose - set to its value
+	// before the change, as recorded by the server's system post.
+	//   TeamID: specifies the team to watch.
+	//   ChannelID: must be empty, all channels are watched.
+	//   Expandable: Channel, PreviousChannel, Team, User (the user who made the
+	//   change), ChannelMember, TeamMember.
+	//   Requires: model.PermissionListTeamChannels. For private channels,
+	//   notifications are only sent if the subscriber can read the channel.
+	SubjectChannelUpdated Subject = "channel_updated"
 
-	// SubjectBotMentioned subscribes to MessageHasBeenPosted plugin events, specifically
-	// when the App's bot is mentioned in the post.
-	// SubjectBotMentioned Subject = "bot_mentioned"
+	// SubjectChannelConverted watches for channels in the specified team being
+	// converted from public to private, or vice versa. PreviousChannel is the
+	// channel as currently stored, with only the Type changed: the server does
+	// not record the previous type, it is the opposite of the current one.
+	//   TeamID: specifies the team to watch.
+	//   ChannelID: must be empty, all channels are watched.
+	//   Expandable: Channel, PreviousChannel, Team, User (the user who made the
+	//   change), ChannelMember, TeamMember.
+	//   Requires: model.PermissionListTeamChannels. For private channels,
+	//   notifications are only sent if the subscriber can read the channel.
+	SubjectChannelConverted Subject = "channel_converted"
+
+	// SubjectPostCreated watches for new posts in the specified channel,
+	// including replies. Posts made by the app's own bot are not reported.
+	//   TeamID: must be empty.
+	//   ChannelID: specifies the channel to watch.
+	//   Expandable: Post, RootPost, Channel, Team, User (the author),
+	//   ChannelMember, TeamMember.
+	//   Requires: model.PermissionReadChannel permission to ChannelID.
+	SubjectPostCreated Subject = "post_created"
+
+	// SubjectBotMentioned system-wide watch for posts that @-mention the app's
+	// own bot. Notifications are only sent for the channels the bot is a
+	// member of.
+	//   TeamID: must be empty.
+	//   ChannelID: must be empty.
+	//   Expandable: Post, RootPost, Channel, Team, User (the author),
+	//   ChannelMember, TeamMember.
+	//   Requires: none - the bot must be a member of the channel to be notified.
+	SubjectBotMentioned Subject = "bot_mentioned"
+
+	// SubjectReactionAdded, SubjectReactionRemoved watch for emoji reactions
+	// added to, or removed from posts in the specified channel. Reactions of
+	// the app's own bot are not reported.
+	//   TeamID: must be empty.
+	//   ChannelID: specifies the channel to watch.
+	//   Expandable: Post, RootPost, Channel, Team, User (the reacting user),
+	//   ChannelMember, TeamMember. Reaction is always included.
+	//   Requires: model.PermissionReadChannel permission to ChannelID.
+	SubjectReactionAdded   Subject = "reaction_added"
+	SubjectReactionRemoved Subject = "reaction_removed"
 )
 
 // Subscription is submitted by an app to the Subscribe API. It determines what
@@ -94,6 +164,62 @@ type Subscription struct {
 
 	// Call is the (one-way) call to make upon the event.
 	Call Call `json:"call"`
+
+	// Filter, if set, is evaluated by the server before the app is notified,
+	// so that the app is not called for the events it would discard.
+	Filter *SubscriptionFilter `json:"filter,omitempty"`
+}
+
+// SubscriptionFilter narrows down the events a subscription is notified on.
+// All of the set criteria must match. User criteria apply to the user in the
+// event's context (e.g. the user who joined the team, or who created the post),
+// channel criteria apply to the event's channel. A criterion that can not be
+// evaluated because the event has no user, or no channel does not match.
+type SubscriptionFilter struct {
+	// ChannelTypes, if set, limits the notifications to the events in the
+	// channels of the listed types ("O", "P", "D", "G").
+	ChannelTypes []model.ChannelType `json:"channel_types,omitempty"`
+
+	// IsBot, if set, limits the notifications to the events for bot users
+	// (true), or for regular users (false).
+	IsBot *bool `json:"is_bot,omitempty"`
+
+	// Roles, if set, requires the user to have at least one of the listed
+	// system roles, e.g. "system_admin". ExcludeRoles excludes the users with
+	// any of the listed system roles, e.g. "system_guest".
+	Roles        []string `json:"roles,omitempty"`
+	ExcludeRoles []string `json:"exclude_roles,omitempty"`
+
+	// TeamIDs, if set, requires the user to be a member of at least one of the
+	// listed teams.
+	TeamIDs []string `json:"team_ids,omitempty"`
+}
+
+func (f SubscriptionFilter) Validate() error {
+	var result error
+	for _, t := range f.ChannelTypes {
+		switch t {
+		case model.ChannelTypeOpen, model.ChannelTypePrivate, model.ChannelTypeDirect, model.ChannelTypeGroup:
+		default:
+			result = multierror.Append(result, utils.NewInvalidError("invalid channel type %q", t))
+		}
+	}
+	for _, role := range append(append([]string{}, f.Roles...), f.ExcludeRoles...) {
+		if role == "" {
+			result = multierror.Append(result, utils.NewInvalidError("role must not be empty"))
+		}
+	}
+	for _, teamID := range f.TeamIDs {
+		if !model.IsValidId(teamID) {
+			result = multierror.Append(result, utils.NewInvalidError("invalid team ID %q", teamID))
+		}
+	}
+	return result
+}
+
+// NeedsUser returns true if the filter has any user criteria.
+func (f SubscriptionFilter) NeedsUser() bool {
+	return f.IsBot != nil || len(f.Roles)+len(f.ExcludeRoles)+len(f.TeamIDs) > 0
 }
 
 type Event struct {
@@ -111,6 +237,11 @@ func (sub Subscription) Validate() error {
 	if sub.Call == emptyCall {
 		result = multierror.Append(result, utils.NewInvalidError("call must not be empty"))
 	}
+	if sub.Filter != nil {
+		if err := sub.Filter.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
 	return sub.Event.validate(result)
 }
 
@@ -127,8 +258,13 @@ func (e Event) validate(appendTo error) error {
 	switch e.Subject {
 	// Globally scoped, must not contain any extra qualifiers.
 	case SubjectUserCreated,
+		SubjectUserDeactivated,
+		SubjectUserReactivated,
+		SubjectUserUpdated,
+		SubjectUserRoleChanged,
 		SubjectBotJoinedTeam,
-		SubjectBotLeftTeam /*, SubjectBotMentioned*/ :
+		SubjectBotLeftTeam,
+		SubjectBotMentioned:
 		if e.TeamID != "" {
 			appendTo = multierror.Append(appendTo, utils.NewInvalidError("%s is globally scoped; team_id and channel_id must both be empty", e.Subject))
 		}
@@ -141,7 +277,11 @@ func (e Event) validate(appendTo error) error {
 		SubjectUserLeftTeam,
 		SubjectBotJoinedChannel,
 		SubjectBotLeftChannel,
-		SubjectChannelCreated:
+		SubjectChannelCreated,
+		SubjectChannelArchived,
+		SubjectChannelRestored,
+		SubjectChannelUpdated,
+		SubjectChannelConverted:
 		if e.TeamID == "" {
 			appendTo = multierror.Append(appendTo, utils.NewInvalidError("%s is scoped to a team; teamID must not be empty", e.Subject))
 		}
@@ -151,7 +291,10 @@ func (e Event) validate(appendTo error) error {
 
 	// Channel scoped, require ChannelID, no TeamID
 	case SubjectUserJoinedChannel,
-		SubjectUserLeftChannel /*, SubjectPostCreated */ :
+		SubjectUserLeftChannel,
+		SubjectPostCreated,
+		SubjectReactionAdded,
+		SubjectReactionRemoved:
 		if e.TeamID != "" {
 			appendTo = multierror.Append(appendTo, utils.NewInvalidError("%s is scoped to a channel; teamID must be empty", e.Subject))
 		}
@@ -189,7 +189,7 @@ var awsTestS3Cmd = &cobra.Command{
 			return err
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), config.DefaultCallTimeout)
 		defer cancel()
 
 		resp, _, err := upTest.GetStatic(ctx, helloServerless(), "test.txt")
@@ -245,7 +245,7 @@ var awsTestLambdaCmd = &cobra.Command{
 			Call: *apps.NewCall("/ping"),
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), config.DefaultCallTimeout)
 		defer cancel()
 
 		resp, err := upTest.Roundtrip(ctx, helloServerless(), creq, false)
@@ -0,0 +1,39 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package appservices
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// UpdateCallJobProgress records the progress of a running async call, as
+// reported by the source app.
+func (a *AppServices) UpdateCallJobProgress(r *incoming.Request, id string, progress apps.CallJobProgress) error {
+	err := r.Check(
+		r.RequireActingUser,
+		r.RequireSourceApp,
+		progress.Validate,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.store.CallJob.Update(id, func(job *apps.CallJob) error {
+		if job.AppID != r.SourceAppID() {
+			return utils.NewNotFoundError("call job %s", id)
+		}
+		if job.IsDone() {
+			return utils.NewInvalidError("call job %s is already %s", id, job.Status)
+		}
+		job.Progress = progress.Progress
+		job.Message = progress.Message
+		job.UpdateAt = time.Now().UnixMilli()
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package appservices
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// CreateSchedule stores a new scheduled call for the source app. The call will
+// be invoked by the proxy with the app's bot as the acting user.
+func (a *AppServices) CreateSchedule(r *incoming.Request, schedule apps.Schedule) (*apps.Schedule, error) {
+	err := r.Check(
+		r.RequireActingUser,
+		r.RequireSourceApp,
+		schedule.Validate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return nil, utils.NewInvalidError("schedule has no future runs")
+	}
+	if schedule.Cron == "" && next.Before(now) {
+		return nil, utils.NewInvalidError("at must be in the future")
+	}
+
+	schedule.ID = model.NewId()
+	schedule.AppID = r.SourceAppID()
+	schedule.NextRunAt = next.UnixMilli()
+	schedule.LastRunAt = 0
+	schedule.LastError = ""
+	err = a.store.Schedule.Add(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Log.Debugf("scheduled call %s for %s, next run at %s", schedule.ID, schedule.AppID, next.UTC().Format(time.RFC3339))
+	return &schedule, nil
+}
+
+// ListSchedules returns the source app's scheduled calls.
+func (a *AppServices) ListSchedules(r *incoming.Request) ([]apps.Schedule, error) {
+	err := r.Check(
+		r.RequireActingUser,
+		r.RequireSourceApp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.store.Schedule.List(r.SourceAppID())
+}
+
+// CancelSchedule deletes a scheduled call of the source app.
+func (a *AppServices) CancelSchedule(r *incoming.Request, id string) error {
+	err := r.Check(
+		r.RequireActingUser,
+		r.RequireSourceApp,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = a.store.Schedule.Delete(r.SourceAppID(), id)
+	if err != nil {
+		return err
+	}
+
+	r.Log.Debugf("canceled scheduled call %s for %s", id, r.SourceAppID())
+	return nil
+}
@@ -16,6 +16,17 @@ type Service interface {
 	GetSubscriptions(_ *incoming.Request) ([]apps.Subscription, error)
 	Unsubscribe(*incoming.Request, apps.Event) error
 	UnsubscribeApp(*incoming.Request, apps.AppID) error
+	RemoveInvalidSubscriptions(_ *incoming.Request, match func(store.Subscription) bool) ([]RemovedSubscription, error)
+
+	// Scheduled calls
+
+	CreateSchedule(*incoming.Request, apps.Schedule) (*apps.Schedule, error)
+	ListSchedules(*incoming.Request) ([]apps.Schedule, error)
+	CancelSchedule(_ *incoming.Request, id string) error
+
+	// Async calls
+
+	UpdateCallJobProgress(_ *incoming.Request, id string, progress apps.CallJobProgress) error
 
 	// KV
 
@@ -6,6 +6,7 @@ package appservices
 import (
 	"github.com/pkg/errors"
 
+	mmerrors "github.com/mattermost/mattermost-plugin-api/errors"
 	"github.com/mattermost/mattermost-server/v6/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
@@ -31,20 +32,22 @@ func (a *AppServices) Subscribe(r *incoming.Request, sub apps.Subscription) erro
 		return err
 	}
 
-	// If there was a prior same-scoped subscription from the app, remove it.
+	// If there was a prior same-scoped subscription from the app, replace it.
 	ownerID := r.ActingUserID()
-	all, err := a.unsubscribe(r, ownerID, sub.Event)
-	if err != nil && errors.Cause(err) != utils.ErrNotFound {
-		return err
-	}
-
-	// Make and save the new subscription.
-	all = append(all, store.Subscription{
-		Call:        sub.Call,
-		AppID:       r.SourceAppID(),
-		OwnerUserID: ownerID,
+	all, err := a.store.Subscription.Update(sub.Event, func(stored []store.Subscription) ([]store.Subscription, error) {
+		modified := []store.Subscription{}
+		for _, s := range stored {
+			if s.AppID != r.SourceAppID() || s.OwnerUserID != ownerID {
+				modified = append(modified, s)
+			}
+		}
+		return append(modified, store.Subscription{
+			Call:        sub.Call,
+			AppID:       r.SourceAppID(),
+			OwnerUserID: ownerID,
+			Filter:      sub.Filter,
+		}), nil
 	})
-	err = a.store.Subscription.Save(sub.Event, all)
 	if err != nil {
 		return err
 	}
@@ -89,8 +92,9 @@ func (a *AppServices) GetSubscriptions(r *incoming.Request) (out []apps.Subscrip
 		for _, s := range stored.Subscriptions {
 			if s.AppID == r.SourceAppID() && s.OwnerUserID == r.ActingUserID() {
 				out = append(out, apps.Subscription{
-					Event: stored.Event,
-					Call:  s.Call,
+					Event:  stored.Event,
+					Call:   s.Call,
+					Filter: s.Filter,
 				})
 			}
 		}
@@ -114,59 +118,189 @@ func (a *AppServices) UnsubscribeApp(r *incoming.Request, appID apps.AppID) erro
 
 	n := 0
 	for _, stored := range allStored {
-		modified := []store.Subscription{}
-		for _, s := range stored.Subscriptions {
-			if s.AppID == appID {
-				n++
-			} else {
-				modified = append(modified, s)
-			}
+		if !hasAppSubscription(stored.Subscriptions, appID) {
+			continue
 		}
-		if len(modified) < len(stored.Subscriptions) {
-			err = a.store.Subscription.Save(stored.Event, modified)
-			if err != nil {
-				return err
+		removed := 0
+		_, err = a.store.Subscription.Update(stored.Event, func(subs []store.Subscription) ([]store.Subscription, error) {
+			removed = 0
+			modified := []store.Subscription{}
+			for _, s := range subs {
+				if s.AppID == appID {
+					removed++
+				} else {
+					modified = append(modified, s)
+				}
 			}
+			return modified, nil
+		})
+		if err != nil {
+			return err
 		}
+		n += removed
 	}
 
 	r.Log.Debugf("removed all (%v) subscriptions for %s", n, appID)
 	return err
 }
 
-func (a *AppServices) unsubscribe(r *incoming.Request, ownerUserID string, e apps.Event) ([]store.Subscription, error) {
-	all, err := a.store.Subscription.Get(e)
+// RemovedSubscription is a subscription that was removed because its owner is
+// no longer valid.
+type RemovedSubscription struct {
+	apps.Subscription
+	AppID       apps.AppID `json:"app_id"`
+	OwnerUserID string     `json:"owner_user_id"`
+	Reason      string     `json:"reason"`
+}
+
+// RemoveInvalidSubscriptions re-validates the owners of the subscriptions
+// selected by match, and removes the subscriptions whose owner has been
+// deactivated or deleted, or no longer has the permission to subscribe to the
+// event. If match is nil, all subscriptions are checked.
+func (a *AppServices) RemoveInvalidSubscriptions(r *incoming.Request, match func(store.Subscription) bool) ([]RemovedSubscription, error) {
+	err := r.Check(
+		r.RequireSysadminOrPlugin,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	for i, s := range all {
-		if s.AppID != r.SourceAppID() || s.OwnerUserID != ownerUserID {
-			continue
+	allStored, err := a.store.Subscription.List()
+	if err != nil {
+		return nil, err
+	}
+
+	mm := r.Config().MattermostAPI()
+	owners := map[string]*model.User{}
+	getOwner := func(userID string) (*model.User, error) {
+		if user, ok := owners[userID]; ok {
+			return user, nil
+		}
+		user, err := mm.User.Get(userID)
+		if err != nil {
+			return nil, err
 		}
+		owners[userID] = user
+		return user, nil
+	}
 
-		allowed := false
-		if s.OwnerUserID == r.ActingUserID() {
-			allowed = true
-		} else if err = r.RequireSysadminOrPlugin(); err == nil {
-			allowed = true
+	validate := func(event apps.Event, s store.Subscription) *RemovedSubscription {
+		sub := apps.Subscription{
+			Event:  event,
+			Call:   s.Call,
+			Filter: s.Filter,
+		}
+		reason := ""
+		owner, err := getOwner(s.OwnerUserID)
+		switch {
+		case errors.Is(err, mmerrors.ErrNotFound):
+			reason = "owner not found"
+		case err != nil:
+			// Do not remove subscriptions on transient errors.
+			r.Log.WithError(err).Debugf("failed to get subscription owner %s", s.OwnerUserID)
+		case owner.DeleteAt != 0:
+			reason = "owner is deactivated"
+		default:
+			if err = a.hasPermissionToSubscribe(r.WithActingUserID(s.OwnerUserID), sub)(); err != nil {
+				reason = err.Error()
+			}
 		}
-		if !allowed {
-			return nil, utils.NewForbiddenError("must be the owner of the subscription, or system administrator to unsubscribe")
+		if reason == "" {
+			return nil
+		}
+		return &RemovedSubscription{
+			Subscription: sub,
+			AppID:        s.AppID,
+			OwnerUserID:  s.OwnerUserID,
+			Reason:       reason,
 		}
+	}
 
-		modified := all[:i]
-		if i < len(all) {
-			modified = append(modified, all[i+1:]...)
+	removed := []RemovedSubscription{}
+	for _, stored := range allStored {
+		// Validate the listed subscriptions before the update, so that the
+		// owners are not looked up again if the update is retried.
+		invalid := map[subscriptionOwner]*RemovedSubscription{}
+		for _, s := range stored.Subscriptions {
+			if match != nil && !match(s) {
+				continue
+			}
+			if removedSub := validate(stored.Event, s); removedSub != nil {
+				invalid[subscriptionOwnerKey(s)] = removedSub
+			}
+		}
+		if len(invalid) == 0 {
+			continue
 		}
-		err = a.store.Subscription.Save(e, modified)
+
+		var removedFromEvent []RemovedSubscription
+		_, err = a.store.Subscription.Update(stored.Event, func(subs []store.Subscription) ([]store.Subscription, error) {
+			removedFromEvent = nil
+			modified := []store.Subscription{}
+			for _, s := range subs {
+				if removedSub, ok := invalid[subscriptionOwnerKey(s)]; ok {
+					removedFromEvent = append(removedFromEvent, *removedSub)
+					continue
+				}
+				modified = append(modified, s)
+			}
+			return modified, nil
+		})
 		if err != nil {
-			return nil, err
+			return removed, err
+		}
+		removed = append(removed, removedFromEvent...)
+	}
+
+	r.Log.Debugf("removed %v subscriptions with invalid owners", len(removed))
+	return removed, nil
+}
+
+func (a *AppServices) unsubscribe(r *incoming.Request, ownerUserID string, e apps.Event) ([]store.Subscription, error) {
+	return a.store.Subscription.Update(e, func(all []store.Subscription) ([]store.Subscription, error) {
+		for i, s := range all {
+			if s.AppID != r.SourceAppID() || s.OwnerUserID != ownerUserID {
+				continue
+			}
+
+			allowed := false
+			if s.OwnerUserID == r.ActingUserID() {
+				allowed = true
+			} else if err := r.RequireSysadminOrPlugin(); err == nil {
+				allowed = true
+			}
+			if !allowed {
+				return nil, utils.NewForbiddenError("must be the owner of the subscription, or system administrator to unsubscribe")
+			}
+
+			modified := append([]store.Subscription{}, all[:i]...)
+			return append(modified, all[i+1:]...), nil
 		}
-		return modified, nil
+		return nil, utils.ErrNotFound
+	})
+}
+
+// subscriptionOwner identifies a subscription to an event, there is at most
+// one per app and owner.
+type subscriptionOwner struct {
+	appID       apps.AppID
+	ownerUserID string
+}
+
+func subscriptionOwnerKey(s store.Subscription) subscriptionOwner {
+	return subscriptionOwner{
+		appID:       s.AppID,
+		ownerUserID: s.OwnerUserID,
 	}
+}
 
-	return all, utils.ErrNotFound
+func hasAppSubscription(subs []store.Subscription, appID apps.AppID) bool {
+	for _, s := range subs {
+		if s.AppID == appID {
+			return true
+		}
+	}
+	return false
 }
 
 func (a *AppServices) hasPermissionToSubscribe(r *incoming.Request, sub apps.Subscription) func() error {
@@ -175,12 +309,17 @@ func (a *AppServices) hasPermissionToSubscribe(r *incoming.Request, sub apps.Sub
 		userID := r.ActingUserID()
 
 		switch sub.Subject {
-		case apps.SubjectUserCreated:
+		case apps.SubjectUserCreated,
+			apps.SubjectUserDeactivated,
+			apps.SubjectUserReactivated,
+			apps.SubjectUserUpdated,
+			apps.SubjectUserRoleChanged:
 			if !mm.User.HasPermissionTo(userID, model.PermissionViewMembers) {
 				return errors.New("no permission to read user")
 			}
 
-		case apps.SubjectUserJoinedChannel, apps.SubjectUserLeftChannel /*, apps.SubjectPostCreated, apps.SubjectBotMentioned */ :
+		case apps.SubjectUserJoinedChannel, apps.SubjectUserLeftChannel, apps.SubjectPostCreated,
+			apps.SubjectReactionAdded, apps.SubjectReactionRemoved:
 			if !mm.User.HasPermissionToChannel(userID, sub.ChannelID, model.PermissionReadChannel) {
 				return errors.New("no permission to read channel")
 			}
@@ -193,12 +332,17 @@ func (a *AppServices) hasPermissionToSubscribe(r *incoming.Request, sub apps.Sub
 		case apps.SubjectBotJoinedChannel,
 			apps.SubjectBotLeftChannel,
 			apps.SubjectBotJoinedTeam,
-			apps.SubjectBotLeftTeam:
+			apps.SubjectBotLeftTeam,
+			apps.SubjectBotMentioned:
 			// When the bot has joined an entity, it will have the permission to
 			// read it.
 			return nil
 
-		case apps.SubjectChannelCreated:
+		case apps.SubjectChannelCreated,
+			apps.SubjectChannelArchived,
+			apps.SubjectChannelRestored,
+			apps.SubjectChannelUpdated,
+			apps.SubjectChannelConverted:
 			if !mm.User.HasPermissionToTeam(userID, sub.TeamID, model.PermissionListTeamChannels) {
 				return errors.New("no permission to list channels")
 			}
@@ -38,40 +38,55 @@ const (
 	fForce          = "force"
 	fBase64         = "base64"
 	fBase64Key      = "base64_key"
+	fCallID         = "call_id"
 	fConsent        = "consent"
 	fCurrentValue   = "current_value"
 	fDeployType     = "deploy_type"
 	fID             = "id"
 	fIncludePlugins = "include_plugins"
+	fLimit          = "limit"
 	FieldNamespace  = "namespace"
 	fNewValue       = "new_value"
+	fNotificationID = "notification_id"
+	fScheduleID     = "schedule_id"
 	fSecret         = "secret"
+	fSince          = "since"
 	fURL            = "url"
+	fUser           = "user"
 	fSessionID      = "session_id"
 )
 
 const (
-	PathDebugClean        = "/debug/clean"
-	PathDebugKVInfo       = "/debug/kv/info"
-	PathDebugKVList       = "/debug/kv/list"
-	PathDebugSessionsList = "/debug/session/list"
-	pDebugBindings        = "/debug/bindings"
-	pDebugKVClean         = "/debug/kv/clean"
-	pDebugKVCreate        = "/debug/kv/create"
-	pDebugKVEdit          = "/debug/kv/edit"
-	pDebugKVEditModal     = "/debug/kv/edit-modal"
-	pDebugOAuthConfigView = "/debug/oauth/config/view"
-	pDebugSessionsRevoke  = "/debug/session/delete"
-	pDebugSessionsView    = "/debug/session/view"
-	pDisable              = "/disable"
-	pEnable               = "/enable"
-	pInfo                 = "/info"
-	pInstallConsent       = "/install-consent"
-	pInstallConsentSource = "/install-consent/form"
-	pInstallHTTP          = "/install-http"
-	pInstallListed        = "/install-listed"
-	pList                 = "/list"
-	pUninstall            = "/uninstall"
+	PathDebugClean            = "/debug/clean"
+	PathDebugKVInfo           = "/debug/kv/info"
+	PathDebugKVList           = "/debug/kv/list"
+	PathDebugSessionsList     = "/debug/session/list"
+	pDebugAudit               = "/debug/audit"
+	pDebugBindings            = "/debug/bindings"
+	pDebugCallsList           = "/debug/calls/list"
+	pDebugCallsReplay         = "/debug/calls/replay"
+	pDebugKVClean             = "/debug/kv/clean"
+	pDebugKVCreate            = "/debug/kv/create"
+	pDebugKVEdit              = "/debug/kv/edit"
+	pDebugKVEditModal         = "/debug/kv/edit-modal"
+	pDebugNotificationsList   = "/debug/notifications/list"
+	pDebugNotificationsPurge  = "/debug/notifications/purge"
+	pDebugNotificationsQueues = "/debug/notifications/queues"
+	pDebugNotificationsReplay = "/debug/notifications/replay"
+	pDebugOAuthConfigView     = "/debug/oauth/config/view"
+	pDebugSchedulesCancel     = "/debug/schedules/cancel"
+	pDebugSchedulesList       = "/debug/schedules/list"
+	pDebugSessionsRevoke      = "/debug/session/delete"
+	pDebugSessionsView        = "/debug/session/view"
+	pDisable                  = "/disable"
+	pEnable                   = "/enable"
+	pInfo                     = "/info"
+	pInstallConsent           = "/install-consent"
+	pInstallConsentSource     = "/install-consent/form"
+	pInstallHTTP              = "/install-http"
+	pInstallListed            = "/install-listed"
+	pList                     = "/list"
+	pUninstall                = "/uninstall"
 )
 
 const (
@@ -109,23 +124,32 @@ func NewBuiltinApp(conf config.Service, proxy proxy.Service, appservices appserv
 		pInfo: a.info,
 
 		// Commands that require sysadmin.
-		pDebugBindings:        requireAdmin(a.debugBindings),
-		PathDebugClean:        requireAdmin(a.debugClean),
-		pDebugKVClean:         requireAdmin(a.debugKVClean),
-		pDebugKVCreate:        requireAdmin(a.debugKVCreate),
-		pDebugKVEdit:          requireAdmin(a.debugKVEdit),
-		PathDebugKVInfo:       requireAdmin(a.debugKVInfo),
-		PathDebugKVList:       requireAdmin(a.debugKVList),
-		PathDebugSessionsList: requireAdmin(a.debugSessionsList),
-		pDebugSessionsRevoke:  requireAdmin(a.debugSessionsRevoke),
-		pDebugSessionsView:    requireAdmin(a.debugSessionsView),
-		pDebugOAuthConfigView: requireAdmin(a.debugOAuthConfigView),
-		pEnable:               requireAdmin(a.enable),
-		pDisable:              requireAdmin(a.disable),
-		pInstallListed:        requireAdmin(a.installListed),
-		pInstallHTTP:          requireAdmin(a.installHTTP),
-		pList:                 requireAdmin(a.list),
-		pUninstall:            requireAdmin(a.uninstall),
+		pDebugAudit:               requireAdmin(a.debugAudit),
+		pDebugBindings:            requireAdmin(a.debugBindings),
+		pDebugCallsList:           requireAdmin(a.debugCallsList),
+		pDebugCallsReplay:         requireAdmin(a.debugCallsReplay),
+		PathDebugClean:            requireAdmin(a.debugClean),
+		pDebugKVClean:             requireAdmin(a.debugKVClean),
+		pDebugKVCreate:            requireAdmin(a.debugKVCreate),
+		pDebugKVEdit:              requireAdmin(a.debugKVEdit),
+		PathDebugKVInfo:           requireAdmin(a.debugKVInfo),
+		PathDebugKVList:           requireAdmin(a.debugKVList),
+		pDebugNotificationsList:   requireAdmin(a.debugNotificationsList),
+		pDebugNotificationsPurge:  requireAdmin(a.debugNotificationsPurge),
+		pDebugNotificationsQueues: requireAdmin(a.debugNotificationsQueues),
+		pDebugNotificationsReplay: requireAdmin(a.debugNotificationsReplay),
+		pDebugSchedulesCancel:     requireAdmin(a.debugSchedulesCancel),
+		pDebugSchedulesList:       requireAdmin(a.debugSchedulesList),
+		PathDebugSessionsList:     requireAdmin(a.debugSessionsList),
+		pDebugSessionsRevoke:      requireAdmin(a.debugSessionsRevoke),
+		pDebugSessionsView:        requireAdmin(a.debugSessionsView),
+		pDebugOAuthConfigView:     requireAdmin(a.debugOAuthConfigView),
+		pEnable:                   requireAdmin(a.enable),
+		pDisable:                  requireAdmin(a.disable),
+		pInstallListed:            requireAdmin(a.installListed),
+		pInstallHTTP:              requireAdmin(a.installHTTP),
+		pList:                     requireAdmin(a.list),
+		pUninstall:                requireAdmin(a.uninstall),
 
 		// Modals.
 		pDebugKVEditModal:     requireAdmin(a.debugKVEditModal),
@@ -17,7 +17,9 @@ func (a *builtinApp) debugCommandBinding(loc *i18n.Localizer) apps.Binding {
 			Other: "debug",
 		}),
 		Bindings: []apps.Binding{
+			a.debugAuditCommandBinding(loc),
 			a.debugBindingsCommandBinding(loc),
+			a.debugCallsCommandBinding(loc),
 			a.debugCleanCommandBinding(loc),
 			{
 				Location: "kv",
@@ -37,6 +39,8 @@ func (a *builtinApp) debugCommandBinding(loc *i18n.Localizer) apps.Binding {
 					a.debugKVListCommandBinding(loc),
 				},
 			},
+			a.debugNotificationsCommandBinding(loc),
+			a.debugSchedulesCommandBinding(loc),
 			{
 				Location: "sessions",
 				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
@@ -0,0 +1,147 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const defaultDebugAuditLimit = 20
+
+func (a *builtinApp) debugAuditCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Location: "audit",
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.audit.label",
+			Other: "audit",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.audit.description",
+			Other: "Display the audit log of the calls and the app lifecycle actions.",
+		}),
+		Form: &apps.Form{
+			Submit: newUserCall(pDebugAudit),
+			Fields: []apps.Field{
+				a.appIDField(LookupInstalledApps, 1, false, loc),
+				{
+					Name: fUser,
+					Type: apps.FieldTypeUser,
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.user.label",
+						Other: "user",
+					}),
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.user.description",
+						Other: "Only display the entries of the user.",
+					}),
+				},
+				{
+					Name: fSince,
+					Type: apps.FieldTypeText,
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.since.label",
+						Other: "since",
+					}),
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.since.description",
+						Other: "Only display the entries of the last period of time, e.g. `90m` or `24h`.",
+					}),
+					AutocompleteHint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.since.hint",
+						Other: "[ duration ]",
+					}),
+				},
+				{
+					Name: fLimit,
+					Type: apps.FieldTypeText,
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.limit.label",
+						Other: "limit",
+					}),
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.limit.description",
+						Other: "Maximum number of entries to display, the newest first. Defaults to 20.",
+					}),
+					AutocompleteHint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.limit.hint",
+						Other: "[ number ]",
+					}),
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) debugAudit(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	f := store.AuditFilter{
+		AppID:  apps.AppID(creq.GetValue(FieldAppID, "")),
+		UserID: creq.GetValue(fUser, ""),
+		Limit:  defaultDebugAuditLimit,
+	}
+	if since := creq.GetValue(fSince, ""); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			return apps.NewErrorResponse(utils.NewInvalidError("invalid duration %q", since))
+		}
+		f.Since = time.Now().Add(-d).UnixMilli()
+	}
+	if limit := creq.GetValue(fLimit, ""); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return apps.NewErrorResponse(utils.NewInvalidError("invalid limit %q", limit))
+		}
+		f.Limit = n
+	}
+
+	entries, err := a.proxy.ListAudit(r, f)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	txt := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.audit.submit.message",
+			Other: "{{.Count}} audit log entries",
+		},
+		TemplateData: map[string]string{
+			"Count": strconv.Itoa(len(entries)),
+		},
+	})
+	txt += "\n"
+	if len(entries) > 0 {
+		txt += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.audit.submit.header",
+			Other: "| Time | Event | App | User | Path | Result | Error |",
+		})
+		txt += "\n| :-- | :-- | :-- | :-- | :-- | :-- | :-- |\n"
+	}
+	for _, e := range entries {
+		app := string(e.AppID)
+		if e.CallerAppID != "" {
+			app = fmt.Sprintf("%s (from %s)", e.AppID, e.CallerAppID)
+		}
+		path := ""
+		if e.Path != "" {
+			path = "`" + e.Path + "`"
+		}
+		txt += fmt.Sprintf("|%s|%s|%s|%s|%s|%s|%s|\n",
+			time.UnixMilli(e.CreateAt).UTC().Format(time.RFC3339), e.Event, app, e.UserID, path, e.Result, e.Error)
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: entries,
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+func (a *builtinApp) debugCallsCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Location: "calls",
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.calls.label",
+			Other: "calls",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.calls.description",
+			Other: "Inspect or replay the recent calls to an app.",
+		}),
+		Bindings: []apps.Binding{
+			{
+				Location: "list",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.calls.list.label",
+					Other: "list",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.calls.list.description",
+					Other: "Display the recent calls to an app, recorded on this server.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugCallsList),
+					Fields: []apps.Field{
+						a.appIDField(LookupInstalledApps, 1, true, loc),
+					},
+				},
+			},
+			{
+				Location: "replay",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.calls.replay.label",
+					Other: "replay",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.calls.replay.description",
+					Other: "Replay a recorded call against the current version of the app, and display the changes in its response.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugCallsReplay),
+					Fields: []apps.Field{
+						a.appIDField(LookupInstalledApps, 1, true, loc),
+						{
+							Name:                 fCallID,
+							Type:                 apps.FieldTypeText,
+							IsRequired:           true,
+							AutocompletePosition: 2,
+							Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+								ID:    "field.call_id.label",
+								Other: "call_id",
+							}),
+							Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+								ID:    "field.call_id.description",
+								Other: "ID of the recorded call, see output of `debug calls list`.",
+							}),
+							AutocompleteHint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+								ID:    "field.call_id.hint",
+								Other: "[ call ID ]",
+							}),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) debugCallsList(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+
+	calls, err := a.proxy.ListRecordedCalls(r, appID)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	txt := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.calls.list.submit.message",
+			Other: "{{.Count}} recorded calls",
+		},
+		TemplateData: map[string]string{
+			"Count": strconv.Itoa(len(calls)),
+		},
+	})
+	txt += "\n"
+	if len(calls) > 0 {
+		txt += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.calls.list.submit.header",
+			Other: "| ID | Time | User | Path | Response | Elapsed |",
+		})
+		txt += "\n| :-- | :-- | :-- | :-- | :-- | :-- |\n"
+	}
+	for _, c := range calls {
+		txt += fmt.Sprintf("|`%s`|%s|%s|`%s`|%s|%v|\n",
+			c.ID, time.UnixMilli(c.CreateAt).UTC().Format(time.RFC3339), c.ActingUserID, c.Request.Path, c.Response.Type,
+			time.Duration(c.ElapsedMs)*time.Millisecond)
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: calls,
+	}
+}
+
+func (a *builtinApp) debugCallsReplay(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	id := creq.GetValue(fCallID, "")
+
+	replay, err := a.proxy.ReplayRecordedCall(r, appID, id)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	txt := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.calls.replay.submit.message",
+			Other: "Replayed call `{{.Path}}` to `{{.AppID}}`, response type: `{{.Type}}`.",
+		},
+		TemplateData: map[string]string{
+			"AppID": string(appID),
+			"Path":  replay.Recorded.Request.Path,
+			"Type":  string(replay.Response.Type),
+		},
+	})
+	txt += "\n"
+	if replay.Diff == "" {
+		txt += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.calls.replay.submit.same",
+			Other: "The response is the same as recorded.",
+		})
+	} else {
+		txt += "\n```diff\n" + replay.Diff + "```\n"
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: replay,
+	}
+}
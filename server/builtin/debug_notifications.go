@@ -0,0 +1,236 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+func (a *builtinApp) debugNotificationsCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Location: "notifications",
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.notifications.label",
+			Other: "notifications",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.notifications.description",
+			Other: "Inspect the notification queues, replay or purge the failed subscription notifications.",
+		}),
+		Bindings: []apps.Binding{
+			{
+				Location: "queues",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.queues.label",
+					Other: "queues",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.queues.description",
+					Other: "Display the state of the per-app notification queues.",
+				}),
+				Submit: newUserCall(pDebugNotificationsQueues),
+			},
+			{
+				Location: "list",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.list.label",
+					Other: "list",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.list.description",
+					Other: "Display the dead-letter list of failed notifications for an app.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugNotificationsList),
+					Fields: []apps.Field{
+						a.appIDField(LookupInstalledApps, 1, true, loc),
+					},
+				},
+			},
+			{
+				Location: "replay",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.replay.label",
+					Other: "replay",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.replay.description",
+					Other: "Re-deliver failed notifications to an app, all or a specific one.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugNotificationsReplay),
+					Fields: []apps.Field{
+						a.appIDField(LookupEnabledApps, 1, true, loc),
+						a.notificationIDField(loc),
+					},
+				},
+			},
+			{
+				Location: "purge",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.purge.label",
+					Other: "purge",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.notifications.purge.description",
+					Other: "Delete failed notifications for an app, all or a specific one.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugNotificationsPurge),
+					Fields: []apps.Field{
+						a.appIDField(LookupInstalledApps, 1, true, loc),
+						a.notificationIDField(loc),
+					},
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) notificationIDField(loc *i18n.Localizer) apps.Field {
+	return apps.Field{
+		Name: fNotificationID,
+		Type: apps.FieldTypeText,
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "field.notification_id.label",
+			Other: "notification_id",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "field.notification_id.description",
+			Other: "ID of the failed notification, see output of `debug notifications list`. All if omitted.",
+		}),
+		AutocompleteHint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "field.notification_id.hint",
+			Other: "[ notification ID ]",
+		}),
+	}
+}
+
+func (a *builtinApp) debugNotificationsList(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+
+	failed, err := a.proxy.ListFailedNotifications(r, appID)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	txt := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.notifications.list.submit.message",
+			Other: "{{.Count}} failed notifications for `{{.AppID}}`",
+		},
+		TemplateData: map[string]string{
+			"Count": strconv.Itoa(len(failed)),
+			"AppID": string(appID),
+		},
+	})
+	txt += "\n"
+	if len(failed) > 0 {
+		txt += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.notifications.list.submit.header",
+			Other: "| ID | Event | Call | Attempts | Created | Last error |",
+		})
+		txt += "\n| :-- | :-- | :-- | :-- | :-- | :-- |\n"
+	}
+	for _, n := range failed {
+		txt += fmt.Sprintf("|`%s`|%s|`%s`|%v|%s|%s|\n",
+			n.ID, n.Event, n.Subscription.Call.Path, n.Attempts, time.UnixMilli(n.CreatedAt).String(), n.LastError)
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: failed,
+	}
+}
+
+func (a *builtinApp) debugNotificationsQueues(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	stats := a.proxy.NotificationQueueStats()
+	conf := a.conf.Get().Notifications
+
+	txt := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.notifications.queues.submit.message",
+			Other: "{{.Count}} notification queues, up to {{.Workers}} workers and {{.QueueSize}} queued notifications per app, shed policy `{{.ShedPolicy}}`",
+		},
+		TemplateData: map[string]string{
+			"Count":      strconv.Itoa(len(stats)),
+			"Workers":    strconv.Itoa(conf.Workers),
+			"QueueSize":  strconv.Itoa(conf.QueueSize),
+			"ShedPolicy": string(conf.ShedPolicy),
+		},
+	})
+	txt += "\n"
+	if len(stats) > 0 {
+		txt += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.notifications.queues.submit.header",
+			Other: "| App | Queued | Active | Delivered | Shed |",
+		})
+		txt += "\n| :-- | --: | --: | --: | --: |\n"
+	}
+	for _, s := range stats {
+		txt += fmt.Sprintf("|%s|%v/%v|%v/%v|%v|%v|\n",
+			s.AppID, s.Queued, s.Capacity, s.Active, s.Workers, s.Delivered, s.Shed)
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: stats,
+	}
+}
+
+func (a *builtinApp) debugNotificationsReplay(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	id := creq.GetValue(fNotificationID, "")
+
+	delivered, failed, err := a.proxy.ReplayFailedNotifications(r, appID, id)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	return apps.NewTextResponse(a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.notifications.replay.submit",
+			Other: "Replayed failed notifications for `{{.AppID}}`: {{.Delivered}} delivered, {{.Failed}} failed again.",
+		},
+		TemplateData: map[string]string{
+			"AppID":     string(appID),
+			"Delivered": strconv.Itoa(delivered),
+			"Failed":    strconv.Itoa(failed),
+		},
+	}))
+}
+
+func (a *builtinApp) debugNotificationsPurge(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	id := creq.GetValue(fNotificationID, "")
+
+	n, err := a.proxy.PurgeFailedNotifications(r, appID, id)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	return apps.NewTextResponse(a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.notifications.purge.submit",
+			Other: "Deleted {{.Count}} failed notifications for `{{.AppID}}`.",
+		},
+		TemplateData: map[string]string{
+			"AppID": string(appID),
+			"Count": strconv.Itoa(n),
+		},
+	}))
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+func (a *builtinApp) debugSchedulesCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Location: "schedules",
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.schedules.label",
+			Other: "schedules",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.schedules.description",
+			Other: "Inspect or cancel the apps' scheduled calls.",
+		}),
+		Bindings: []apps.Binding{
+			{
+				Location: "list",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.schedules.list.label",
+					Other: "list",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.schedules.list.description",
+					Other: "Display the scheduled calls of an app, or of all apps.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugSchedulesList),
+					Fields: []apps.Field{
+						a.appIDField(LookupInstalledApps, 1, false, loc),
+					},
+				},
+			},
+			{
+				Location: "cancel",
+				Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.schedules.cancel.label",
+					Other: "cancel",
+				}),
+				Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+					ID:    "command.debug.schedules.cancel.description",
+					Other: "Cancel a scheduled call of an app.",
+				}),
+				Form: &apps.Form{
+					Submit: newUserCall(pDebugSchedulesCancel),
+					Fields: []apps.Field{
+						a.appIDField(LookupInstalledApps, 1, true, loc),
+						{
+							Name:                 fScheduleID,
+							Type:                 apps.FieldTypeText,
+							IsRequired:           true,
+							AutocompletePosition: 2,
+							Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+								ID:    "field.schedule_id.label",
+								Other: "schedule_id",
+							}),
+							Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+								ID:    "field.schedule_id.description",
+								Other: "ID of the scheduled call, see output of `debug schedules list`.",
+							}),
+							AutocompleteHint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+								ID:    "field.schedule_id.hint",
+								Other: "[ schedule ID ]",
+							}),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) debugSchedulesList(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+
+	schedules, err := a.proxy.ListSchedules(r, appID)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	txt := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.schedules.list.submit.message",
+			Other: "{{.Count}} scheduled calls",
+		},
+		TemplateData: map[string]string{
+			"Count": strconv.Itoa(len(schedules)),
+		},
+	})
+	txt += "\n"
+	if len(schedules) > 0 {
+		txt += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.schedules.list.submit.header",
+			Other: "| App | ID | Call | Schedule | Next run | Last run | Last error |",
+		})
+		txt += "\n| :-- | :-- | :-- | :-- | :-- | :-- | :-- |\n"
+	}
+	for _, s := range schedules {
+		schedule := s.Cron
+		if schedule == "" {
+			schedule = time.UnixMilli(s.At).UTC().String()
+		}
+		lastRun := ""
+		if s.LastRunAt != 0 {
+			lastRun = time.UnixMilli(s.LastRunAt).UTC().String()
+		}
+		txt += fmt.Sprintf("|%s|`%s`|`%s`|`%s`|%s|%s|%s|\n",
+			s.AppID, s.ID, s.Call.Path, schedule, time.UnixMilli(s.NextRunAt).UTC().String(), lastRun, s.LastError)
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: schedules,
+	}
+}
+
+func (a *builtinApp) debugSchedulesCancel(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	loc := a.newLocalizer(creq)
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	id := creq.GetValue(fScheduleID, "")
+
+	err := a.proxy.CancelSchedule(r, appID, id)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	return apps.NewTextResponse(a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.debug.schedules.cancel.submit",
+			Other: "Canceled scheduled call `{{.ID}}` for `{{.AppID}}`.",
+		},
+		TemplateData: map[string]string{
+			"AppID": string(appID),
+			"ID":    id,
+		},
+	}))
+}
@@ -5,6 +5,7 @@ package builtin
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
@@ -31,7 +32,7 @@ func (a *builtinApp) installConsent(r *incoming.Request, creq apps.CallRequest)
 	if err != nil {
 		return apps.NewErrorResponse(errors.Wrap(err, "failed to find a valid manifest in State"))
 	}
-	if !consent && len(m.RequestedLocations)+len(m.RequestedPermissions) > 0 {
+	if !consent && len(m.RequestedLocations)+len(m.RequestedPermissions)+len(m.Subscriptions)+len(m.AppCallers) > 0 {
 		return apps.NewErrorResponse(errors.New("consent to use APIs and locations is required to install"))
 	}
 
@@ -121,6 +122,26 @@ func (a *builtinApp) newInstallConsentForm(m apps.Manifest, creq apps.CallReques
 			h += fmt.Sprintf("  - %s\n", permission.String())
 		}
 	}
+	if len(m.Subscriptions) > 0 {
+		h += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "modal.install_consent.header.subscriptions",
+			Other: "- Subscribe to the following **events** as the App's bot:",
+		}) + "\n"
+		// Subjects are not localized
+		for _, sub := range m.Subscriptions {
+			h += fmt.Sprintf("  - %s\n", sub.Event.String())
+		}
+	}
+	if len(m.AppCallers) > 0 {
+		h += a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "modal.install_consent.header.app_callers",
+			Other: "- Accept **calls** from the following apps:",
+		}) + "\n"
+		// Paths are not localized
+		for _, caller := range m.AppCallers {
+			h += fmt.Sprintf("  - %s: `%s`\n", caller.AppID, strings.Join(caller.Paths, "`, `"))
+		}
+	}
 	if h != "" {
 		header := a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
 			DefaultMessage: &i18n.Message{
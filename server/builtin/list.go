@@ -10,6 +10,7 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/proxy"
 )
 
 func (a *builtinApp) listCommandBinding(loc *i18n.Localizer) apps.Binding {
@@ -92,6 +93,17 @@ func (a *builtinApp) list(r *incoming.Request, creq apps.CallRequest) apps.CallR
 					Other: "Installed, **Unreachable**",
 				})
 			}
+			if state := a.proxy.CircuitBreakerState(app.AppID); state != proxy.CircuitClosed {
+				status += a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+					DefaultMessage: &i18n.Message{
+						ID:    "command.list.submit.status.circuit_breaker",
+						Other: ", circuit breaker **{{.State}}**",
+					},
+					TemplateData: map[string]string{
+						"State": string(state),
+					},
+				})
+			}
 		}
 
 		version := string(app.Version)
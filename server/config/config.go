@@ -8,6 +8,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"time"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 	"github.com/mattermost/mattermost-server/v6/model"
@@ -39,6 +40,110 @@ type StoredConfig struct {
 	// added, and the Manifest struct is stored in KV under
 	// manifest_<sha1(Manifest)>. Implementation in `store.Manifest`.
 	LocalManifests map[string]string `json:"local_manifests,omitempty"`
+
+	// NotificationWorkers, NotificationQueueSize, and NotificationShedPolicy
+	// are set in the System Console, and control the delivery of subscription
+	// notifications to apps. The effective values, with the defaults applied,
+	// are in Config.Notifications.
+	NotificationWorkers    int    `json:"NotificationWorkers,omitempty"`
+	NotificationQueueSize  int    `json:"NotificationQueueSize,omitempty"`
+	NotificationShedPolicy string `json:"NotificationShedPolicy,omitempty"`
+
+	// MaxCallTimeoutSeconds is set in the System Console, and caps the call
+	// timeouts declared by the apps. The effective value, with the default
+	// applied, is in Config.MaxCallTimeout.
+	MaxCallTimeoutSeconds int `json:"MaxCallTimeoutSeconds,omitempty"`
+
+	// MaxAsyncCallTimeoutSeconds is set in the System Console, and caps the
+	// duration of async calls. The effective value, with the default applied,
+	// is in Config.MaxAsyncCallTimeout.
+	MaxAsyncCallTimeoutSeconds int `json:"MaxAsyncCallTimeoutSeconds,omitempty"`
+
+	// RateLimitPerApp, RateLimitPerUser, and RateLimitPerAppUser are set in
+	// the System Console, and limit the calls made from the user agents, see
+	// Config.RateLimits.
+	RateLimitPerApp     int `json:"RateLimitPerApp,omitempty"`
+	RateLimitPerUser    int `json:"RateLimitPerUser,omitempty"`
+	RateLimitPerAppUser int `json:"RateLimitPerAppUser,omitempty"`
+
+	// IdempotencyWindowSeconds is set in the System Console, see
+	// Config.IdempotencyWindow. 0 means the default, a negative value disables
+	// the de-duplication of calls.
+	IdempotencyWindowSeconds int `json:"IdempotencyWindowSeconds,omitempty"`
+
+	// TracingOTLPEndpoint is the URL of the OpenTelemetry collector to export
+	// the traces to, using OTLP/HTTP. Tracing is disabled if it is empty.
+	TracingOTLPEndpoint string `json:"TracingOTLPEndpoint,omitempty"`
+
+	// AuditLogMaxEntries and AuditLogRetentionDays are set in the System
+	// Console, see Config.AuditLog. 0 means the default, a negative
+	// AuditLogMaxEntries disables the audit log.
+	AuditLogMaxEntries    int `json:"AuditLogMaxEntries,omitempty"`
+	AuditLogRetentionDays int `json:"AuditLogRetentionDays,omitempty"`
+}
+
+// ShedPolicy determines what happens to a new notification when the app's
+// notification queue is full.
+type ShedPolicy string
+
+const (
+	// ShedRetryLater persists the new notification, to be delivered by the
+	// periodic retry job.
+	ShedRetryLater = ShedPolicy("retry_later")
+
+	// ShedDropNewest drops the new notification.
+	ShedDropNewest = ShedPolicy("drop_newest")
+
+	// ShedDropOldest drops the oldest queued notification to make room for the
+	// new one.
+	ShedDropOldest = ShedPolicy("drop_oldest")
+)
+
+const (
+	DefaultNotificationWorkers   = 4
+	DefaultNotificationQueueSize = 1000
+	DefaultShedPolicy            = ShedRetryLater
+	DefaultMaxCallTimeout        = 5 * time.Minute
+	DefaultMaxAsyncCallTimeout   = 15 * time.Minute
+	DefaultIdempotencyWindow     = 10 * time.Second
+	DefaultAuditLogMaxEntries    = 10000
+	DefaultAuditLogRetention     = 30 * 24 * time.Hour
+)
+
+// NotificationsConfig is the effective configuration of the per-app
+// notification queues.
+type NotificationsConfig struct {
+	// Workers is the number of notifications delivered concurrently to each
+	// app.
+	Workers int
+
+	// QueueSize is the maximum number of notifications waiting to be
+	// delivered to each app.
+	QueueSize int
+
+	ShedPolicy ShedPolicy
+}
+
+// RateLimitsConfig is the effective configuration of the call rate limits.
+// Each limit is the number of calls per minute, enforced as a token bucket
+// that holds up to a minute's worth of calls. 0, or a negative value, means no
+// limit.
+type RateLimitsConfig struct {
+	PerApp     int
+	PerUser    int
+	PerAppUser int
+}
+
+// AuditLogConfig is the effective configuration of the audit log of the calls
+// and the app lifecycle actions. The audit log is kept by the plugin, the
+// entries are not written to the server's audit log.
+type AuditLogConfig struct {
+	// MaxEntries is the size of the audit log, the oldest entries are
+	// periodically removed past it. 0 means the audit log is disabled.
+	MaxEntries int
+
+	// Retention is how long the entries are kept.
+	Retention time.Duration
 }
 
 var BuildDate string
@@ -70,6 +175,25 @@ type Config struct {
 	// Maximum size of incoming remote webhook messages
 	MaxWebhookSize int
 
+	Notifications NotificationsConfig
+
+	// MaxCallTimeout caps the call timeouts declared by the apps, see
+	// apps.Manifest.CallTimeout and apps.Call.Timeout.
+	MaxCallTimeout time.Duration
+
+	// MaxAsyncCallTimeout caps the duration of async calls, see
+	// apps.Call.Async.
+	MaxAsyncCallTimeout time.Duration
+
+	RateLimits RateLimitsConfig
+
+	// IdempotencyWindow is how long the responses to the calls with an
+	// idempotency key are kept, and replayed to the repeated calls. 0 means
+	// the calls are not de-duplicated.
+	IdempotencyWindow time.Duration
+
+	AuditLog AuditLogConfig
+
 	AWSRegion    string
 	AWSAccessKey string
 	AWSSecretKey string
@@ -137,6 +261,63 @@ func (conf *Config) update(stored StoredConfig, mmconf *model.Config, license *m
 		conf.MaxWebhookSize = int(*mmconf.FileSettings.MaxFileSize)
 	}
 
+	conf.Notifications = NotificationsConfig{
+		Workers:    DefaultNotificationWorkers,
+		QueueSize:  DefaultNotificationQueueSize,
+		ShedPolicy: DefaultShedPolicy,
+	}
+	if stored.NotificationWorkers > 0 {
+		conf.Notifications.Workers = stored.NotificationWorkers
+	}
+	if stored.NotificationQueueSize > 0 {
+		conf.Notifications.QueueSize = stored.NotificationQueueSize
+	}
+	switch policy := ShedPolicy(stored.NotificationShedPolicy); policy {
+	case ShedRetryLater, ShedDropNewest, ShedDropOldest:
+		conf.Notifications.ShedPolicy = policy
+	case "":
+	default:
+		log.Warnf("ignored unknown notification shed policy %q, using %q", policy, DefaultShedPolicy)
+	}
+
+	conf.MaxCallTimeout = DefaultMaxCallTimeout
+	if stored.MaxCallTimeoutSeconds > 0 {
+		conf.MaxCallTimeout = time.Duration(stored.MaxCallTimeoutSeconds) * time.Second
+	}
+	conf.MaxAsyncCallTimeout = DefaultMaxAsyncCallTimeout
+	if stored.MaxAsyncCallTimeoutSeconds > 0 {
+		conf.MaxAsyncCallTimeout = time.Duration(stored.MaxAsyncCallTimeoutSeconds) * time.Second
+	}
+
+	conf.RateLimits = RateLimitsConfig{
+		PerApp:     stored.RateLimitPerApp,
+		PerUser:    stored.RateLimitPerUser,
+		PerAppUser: stored.RateLimitPerAppUser,
+	}
+
+	switch {
+	case stored.IdempotencyWindowSeconds > 0:
+		conf.IdempotencyWindow = time.Duration(stored.IdempotencyWindowSeconds) * time.Second
+	case stored.IdempotencyWindowSeconds == 0:
+		conf.IdempotencyWindow = DefaultIdempotencyWindow
+	default:
+		conf.IdempotencyWindow = 0
+	}
+
+	conf.AuditLog = AuditLogConfig{
+		MaxEntries: DefaultAuditLogMaxEntries,
+		Retention:  DefaultAuditLogRetention,
+	}
+	switch {
+	case stored.AuditLogMaxEntries > 0:
+		conf.AuditLog.MaxEntries = stored.AuditLogMaxEntries
+	case stored.AuditLogMaxEntries < 0:
+		conf.AuditLog.MaxEntries = 0
+	}
+	if stored.AuditLogRetentionDays > 0 {
+		conf.AuditLog.Retention = time.Duration(stored.AuditLogRetentionDays) * 24 * time.Hour
+	}
+
 	conf.DeveloperMode = pluginapi.IsConfiguredForDevelopment(mmconf)
 
 	conf.AllowHTTPApps = !conf.MattermostCloudMode || conf.DeveloperMode
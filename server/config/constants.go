@@ -31,10 +31,18 @@ const (
 	WebSocketEventRefreshBindings = "refresh_bindings"
 	WebSocketEventPluginEnabled   = "plugin_enabled"
 	WebSocketEventPluginDisabled  = "plugin_disabled"
+	WebSocketEventCallJobDone     = "call_job_done"
 )
 
 const (
+	// RequestTimeout limits the incoming HTTP requests, other than the ones
+	// that invoke app calls.
 	RequestTimeout = time.Second * 30
+
+	// DefaultCallTimeout and DefaultPingTimeout apply to the calls to apps
+	// that do not declare their own timeouts.
+	DefaultCallTimeout = time.Second * 30
+	DefaultPingTimeout = time.Second * 1
 )
 
 const (
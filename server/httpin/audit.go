@@ -0,0 +1,58 @@
+package httpin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+// DefaultAuditLimit is the number of audit entries returned if the request
+// does not specify a limit.
+const DefaultAuditLimit = 100
+
+// ListAudit returns the entries of the audit log, the newest first.
+//   Path: /api/v1/audit
+//   Method: GET
+//   Input: query parameters app_id, user_id, since and until (Unix
+//   milliseconds), and limit; all optional.
+//   Output: []AuditEntry
+func (s *Service) ListAudit(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	q := req.URL.Query()
+	f := store.AuditFilter{
+		AppID:  apps.AppID(q.Get("app_id")),
+		UserID: q.Get("user_id"),
+		Limit:  DefaultAuditLimit,
+	}
+	for name, v := range map[string]*int64{
+		"since": &f.Since,
+		"until": &f.Until,
+	} {
+		if q.Get(name) == "" {
+			continue
+		}
+		if *v, err = strconv.ParseInt(q.Get(name), 10, 64); err != nil {
+			err = utils.NewInvalidError("invalid %s: %v", name, err)
+			return
+		}
+	}
+	if q.Get("limit") != "" {
+		if f.Limit, err = strconv.Atoi(q.Get("limit")); err != nil || f.Limit <= 0 {
+			err = utils.NewInvalidError("invalid limit %q", q.Get("limit"))
+			return
+		}
+	}
+
+	entries, err := s.Proxy.ListAudit(r, f)
+	if err != nil {
+		return
+	}
+	_ = httputils.WriteJSON(w, entries)
+}
@@ -1,12 +1,15 @@
 package httpin
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/proxy"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
 )
@@ -15,7 +18,8 @@ import (
 //   Path: /api/v1/call
 //   Method: POST
 //   Input: CallRequest
-//   Output: CallResponse
+//   Output: CallResponse, with status 429 and a Retry-After header if the
+//   call exceeded a rate limit.
 func (s *Service) Call(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
 	creq, err := apps.CallRequestFromJSONReader(req.Body)
 	if err != nil {
@@ -41,5 +45,47 @@ func (s *Service) Call(r *incoming.Request, w http.ResponseWriter, req *http.Req
 		s.Config.Telemetry().TrackCall(string(creq.Context.AppID), string(creq.Context.Location), r.ActingUserID(), "submit")
 	}
 
+	writeCallResponse(w, cresp)
+}
+
+// AppCall handles a call request made by an App to another App, on behalf of
+// the acting user.
+//   Path: /api/v1/app-call
+//   Method: POST
+//   Input: CallRequest
+//   Output: CallResponse, with status 429 and a Retry-After header if the
+//   call exceeded a rate limit.
+func (s *Service) AppCall(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	creq, err := apps.CallRequestFromJSONReader(req.Body)
+	if err != nil {
+		err = errors.Wrap(err, "failed to unmarshal Call request")
+		r.Log.WithError(err).Infof("incoming app call failed")
+		httputils.WriteErrorIfNeeded(w, utils.NewInvalidError(err))
+		return
+	}
+	if creq.Context.UserAgentContext.AppID == "" {
+		err = errors.New("app ID is not set in Call request")
+		r.Log.WithError(err).Infof("incoming app call failed")
+		httputils.WriteErrorIfNeeded(w, utils.NewInvalidError(err))
+		return
+	}
+	r = r.WithDestination(creq.Context.UserAgentContext.AppID)
+
+	cresp := s.Proxy.InvokeAppCall(r, *creq)
+	writeCallResponse(w, cresp)
+}
+
+func writeCallResponse(w http.ResponseWriter, cresp proxy.CallResponse) {
+	if cresp.RetryAfter > 0 {
+		// Rejected by a rate limit.
+		retryAfter := int(math.Ceil(cresp.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		_ = httputils.WriteJSONStatus(w, http.StatusTooManyRequests, cresp)
+		return
+	}
+
 	_ = httputils.WriteJSON(w, cresp)
 }
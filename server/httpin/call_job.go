@@ -0,0 +1,45 @@
+package httpin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+// GetCallJob returns the status of an async call started by the acting user,
+// and its response once done.
+//   Path: /api/v1/call-job/{id}
+//   Method: GET
+//   Input: None
+//   Output: CallJob
+func (s *Service) GetCallJob(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	job, err := s.Proxy.GetCallJob(r, mux.Vars(req)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), httputils.ErrorToStatus(err))
+		return
+	}
+	_ = httputils.WriteJSON(w, job)
+}
+
+// UpdateCallJobProgress reports the progress of an App's running async call.
+//   Path: /api/v1/call-job/{id}/progress
+//   Method: PUT, POST
+//   Input: CallJobProgress
+//   Output: None
+func (s *Service) UpdateCallJobProgress(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var progress apps.CallJobProgress
+	if err := json.NewDecoder(req.Body).Decode(&progress); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err := s.AppServices.UpdateCallJobProgress(r, mux.Vars(req)["id"], progress)
+	if err != nil {
+		http.Error(w, err.Error(), httputils.ErrorToStatus(err))
+		return
+	}
+}
@@ -0,0 +1,29 @@
+package httpin
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/metrics"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+// Metrics outputs the operational metrics of the apps proxy, for a Prometheus
+// scraper authenticated as a system administrator, e.g. with a bot's access
+// token.
+//   Path: /api/v1/metrics
+//   Method: GET
+//   Output: Prometheus text format
+func (s *Service) Metrics(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	if err = r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	err = s.Proxy.WriteMetrics(w)
+}
@@ -0,0 +1,58 @@
+package httpin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+// ListSchedules returns the App's scheduled calls.
+//   Path: /api/v1/schedule
+//   Method: GET
+//   Input: None
+//   Output: []Schedule
+func (s *Service) ListSchedules(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	schedules, err := s.AppServices.ListSchedules(r)
+	if err != nil {
+		http.Error(w, err.Error(), httputils.ErrorToStatus(err))
+		return
+	}
+	_ = httputils.WriteJSON(w, schedules)
+}
+
+// CreateSchedule schedules a one-off or a recurring call to the App.
+//   Path: /api/v1/schedule
+//   Method: POST
+//   Input: Schedule
+//   Output: Schedule, with the ID and NextRunAt set
+func (s *Service) CreateSchedule(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var schedule apps.Schedule
+	if err := json.NewDecoder(req.Body).Decode(&schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := s.AppServices.CreateSchedule(r, schedule)
+	if err != nil {
+		http.Error(w, err.Error(), httputils.ErrorToStatus(err))
+		return
+	}
+	_ = httputils.WriteJSON(w, created)
+}
+
+// CancelSchedule removes an App's scheduled call.
+//   Path: /api/v1/schedule/{id}
+//   Method: DELETE
+//   Input: None
+//   Output: None
+func (s *Service) CancelSchedule(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if err := s.AppServices.CancelSchedule(r, id); err != nil {
+		http.Error(w, err.Error(), httputils.ErrorToStatus(err))
+		return
+	}
+}
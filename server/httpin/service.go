@@ -30,6 +30,10 @@ type Service struct {
 	baseLog     utils.Logger
 	router      *mux.Router
 	handlerFunc handlerFunc
+
+	// invokesCalls is set for the routes that invoke app calls, see
+	// HandleCallFunc.
+	invokesCalls bool
 }
 
 var _ http.Handler = (*Service)(nil)
@@ -67,13 +71,14 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	h.HandleFunc(path.Ping, h.Ping).Methods(http.MethodPost)
 
 	// User-agent APIs.
-	h.HandleFunc(path.Call, h.Call).Methods(http.MethodPost)
+	h.HandleCallFunc(path.Call, h.Call).Methods(http.MethodPost)
+	h.HandleFunc(path.CallJob+"/{id}", h.GetCallJob).Methods(http.MethodGet)
 	h.HandleFunc(path.Bindings, h.GetBindings).Methods(http.MethodGet)
 	h.HandleFunc(path.BotIDs, h.GetBotIDs).Methods(http.MethodGet)
 	h.HandleFunc(path.OAuthAppIDs, h.GetOAuthAppIDs).Methods(http.MethodGet)
 
-	// App Service API, intended to be used by Apps. Subscriptions, KV, OAuth2
-	// services.
+	// App Service API, intended to be used by Apps. Subscriptions, KV, OAuth2,
+	// scheduled calls, async call progress, and app-to-app call services.
 	h.HandleFunc(path.KV+"/{key}", h.KVDelete).Methods(http.MethodDelete)
 	h.HandleFunc(path.KV+"/{key}", h.KVGet).Methods(http.MethodGet)
 	h.HandleFunc(path.KV+"/{key}", h.KVPut).Methods(http.MethodPut, http.MethodPost)
@@ -86,6 +91,11 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	h.HandleFunc(path.Subscribe, h.GetSubscriptions).Methods(http.MethodGet)
 	h.HandleFunc(path.Subscribe, h.Subscribe).Methods(http.MethodPost)
 	h.HandleFunc(path.Unsubscribe, h.Unsubscribe).Methods(http.MethodPost)
+	h.HandleFunc(path.Schedule, h.ListSchedules).Methods(http.MethodGet)
+	h.HandleFunc(path.Schedule, h.CreateSchedule).Methods(http.MethodPost)
+	h.HandleFunc(path.Schedule+"/{id}", h.CancelSchedule).Methods(http.MethodDelete)
+	h.HandleFunc(path.CallJob+"/{id}"+path.CallJobProgress, h.UpdateCallJobProgress).Methods(http.MethodPut, http.MethodPost)
+	h.HandleCallFunc(path.AppCall, h.AppCall).Methods(http.MethodPost)
 
 	// Admin API, can be used by plugins, external services, or the user agent.
 	h.HandleFunc(path.DisableApp, h.DisableApp).Methods(http.MethodPost)
@@ -94,6 +104,8 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	h.HandleFunc(path.Marketplace, h.GetMarketplace).Methods(http.MethodGet)
 	h.HandleFunc(path.UninstallApp, h.UninstallApp).Methods(http.MethodPost)
 	h.HandleFunc(path.UpdateAppListing, h.UpdateAppListing).Methods(http.MethodPost)
+	h.HandleFunc(path.Metrics, h.Metrics).Methods(http.MethodGet)
+	h.HandleFunc(path.Audit, h.ListAudit).Methods(http.MethodGet)
 	h.PathPrefix(path.Apps).PathPrefix(`/{appid:[A-Za-z0-9-_.]+}`).HandleFunc("", h.GetApp).Methods(http.MethodGet)
 
 	return rootHandler
@@ -102,6 +114,7 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 func (s *Service) PathPrefix(prefix string) *Service {
 	clone := *s
 	clone.handlerFunc = nil
+	clone.invokesCalls = false
 	clone.router = clone.router.PathPrefix(prefix).Subrouter()
 	return &clone
 }
@@ -112,6 +125,16 @@ func (s *Service) HandleFunc(path string, handlerFunc handlerFunc) *mux.Route {
 	return clone.router.Handle(path, &clone)
 }
 
+// HandleCallFunc is HandleFunc for the routes that invoke app calls. The calls
+// are limited by their own timeouts, so the request as a whole is limited by
+// the longest one allowed, MaxCallTimeout, rather than RequestTimeout.
+func (s *Service) HandleCallFunc(path string, handlerFunc handlerFunc) *mux.Route {
+	clone := *s
+	clone.handlerFunc = handlerFunc
+	clone.invokesCalls = true
+	return clone.router.Handle(path, &clone)
+}
+
 // ServePluginHTTP is the interface invoked from the plugin's ServeHTTP
 func (s *Service) ServePluginHTTP(c *plugin.Context, w http.ResponseWriter, req *http.Request) {
 	req.Header.Set(config.MattermostSessionIDHeader, c.SessionId)
@@ -128,8 +151,12 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if s, ok := mux.Vars(req)[AppIDVar]; ok {
 		r = r.WithDestination(apps.AppID(s))
 	}
+	timeout := config.RequestTimeout
+	if s.invokesCalls {
+		timeout = s.Config.Get().MaxCallTimeout
+	}
 	var cancel context.CancelFunc
-	r = r.WithTimeout(config.RequestTimeout, &cancel)
+	r = r.WithTimeout(timeout, &cancel)
 	defer cancel()
 	r.Log = r.Log.With(
 		"path", req.URL.Path,
@@ -57,6 +57,12 @@ func (r *Request) Clone() *Request {
 	return &clone
 }
 
+// RequestID is a unique ID of the request, it is included in the log
+// messages, and is passed to the apps in Context.RequestID.
+func (r *Request) RequestID() string {
+	return r.requestID
+}
+
 func (r *Request) Ctx() context.Context {
 	return r.ctx
 }
@@ -69,6 +75,9 @@ func (r *Request) WithCtx(ctx context.Context) *Request {
 
 func (r *Request) WithTimeout(timeout time.Duration, cancelFunc *context.CancelFunc) *Request {
 	if timeout == 0 {
+		if cancelFunc != nil {
+			*cancelFunc = func() {}
+		}
 		return r
 	}
 	r = r.Clone()
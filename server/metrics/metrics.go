@@ -0,0 +1,151 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package metrics maintains the operational metrics of the apps proxy, and
+// exposes them in the Prometheus text format. All methods are safe to call on
+// a nil *Metrics, which does nothing.
+package metrics
+
+import (
+	"io"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+const namespace = "mattermost_apps_"
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// durationBuckets are the upper bounds of the latency histograms, in seconds.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type Metrics struct {
+	calls        *vec
+	callDuration *vec
+
+	bindingsDuration *vec
+
+	expandCalls    *vec
+	expandDuration *vec
+
+	notificationQueue     *vec
+	notificationDelivered *vec
+	notificationShed      *vec
+}
+
+func New() *Metrics {
+	return &Metrics{
+		calls: newVec(namespace+"calls_total",
+			"Calls to the apps, by response type. Error rates are the share of the error responses.",
+			kindCounter, nil, "app_id", "path", "deploy_type", "response_type"),
+		callDuration: newVec(namespace+"call_duration_seconds",
+			"Duration of the calls to the apps, including the context expansion.",
+			kindHistogram, durationBuckets, "app_id", "path", "deploy_type", "response_type"),
+		bindingsDuration: newVec(namespace+"bindings_duration_seconds",
+			"Duration of fetching the bindings of all apps for a user.",
+			kindHistogram, durationBuckets),
+		expandCalls: newVec(namespace+"expand_api_calls_total",
+			"Expansions of the context fields of the calls, by field and result. Most make a Mattermost API call.",
+			kindCounter, nil, "field", "result"),
+		expandDuration: newVec(namespace+"expand_api_call_duration_seconds",
+			"Duration of the expansions of the context fields of the calls.",
+			kindHistogram, durationBuckets, "field"),
+		notificationQueue: newVec(namespace+"notification_queue",
+			"State of the per-app notification queues: queued, capacity, active, and workers.",
+			kindGauge, nil, "app_id", "state"),
+		notificationDelivered: newVec(namespace+"notifications_delivered_total",
+			"Notifications delivered from the app's queue since it was created.",
+			kindCounter, nil, "app_id"),
+		notificationShed: newVec(namespace+"notifications_shed_total",
+			"Notifications rejected by the app's full queue since it was created.",
+			kindCounter, nil, "app_id"),
+	}
+}
+
+// ObserveCall records a call to an app, and its duration.
+func (m *Metrics) ObserveCall(appID apps.AppID, callPath string, deployType apps.DeployType, responseType apps.CallResponseType, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	labels := []string{string(appID), callPath, string(deployType), string(responseType)}
+	m.calls.add(1, labels...)
+	m.callDuration.observe(elapsed.Seconds(), labels...)
+}
+
+// ObserveBindings records the duration of a bindings fan-out to all apps.
+func (m *Metrics) ObserveBindings(elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.bindingsDuration.observe(elapsed.Seconds())
+}
+
+// ObserveExpand records the expansion of a context field, usually a Mattermost
+// API call.
+func (m *Metrics) ObserveExpand(field string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.expandCalls.add(1, field, result)
+	m.expandDuration.observe(elapsed.Seconds(), field)
+}
+
+// NotificationQueue is the state of an app's notification queue, as reported
+// by SetNotificationQueues.
+type NotificationQueue struct {
+	AppID     apps.AppID
+	Queued    int
+	Capacity  int
+	Active    int
+	Workers   int
+	Delivered uint64
+	Shed      uint64
+}
+
+// SetNotificationQueues replaces the notification queue metrics with a
+// snapshot of the current queues.
+func (m *Metrics) SetNotificationQueues(queues []NotificationQueue) {
+	if m == nil {
+		return
+	}
+	m.notificationQueue.reset()
+	m.notificationDelivered.reset()
+	m.notificationShed.reset()
+	for _, q := range queues {
+		appID := string(q.AppID)
+		m.notificationQueue.set(float64(q.Queued), appID, "queued")
+		m.notificationQueue.set(float64(q.Capacity), appID, "capacity")
+		m.notificationQueue.set(float64(q.Active), appID, "active")
+		m.notificationQueue.set(float64(q.Workers), appID, "workers")
+		m.notificationDelivered.set(float64(q.Delivered), appID)
+		m.notificationShed.set(float64(q.Shed), appID)
+	}
+}
+
+// Write outputs all metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	if m == nil {
+		return nil
+	}
+	for _, v := range []*vec{
+		m.calls,
+		m.callDuration,
+		m.bindingsDuration,
+		m.expandCalls,
+		m.expandDuration,
+		m.notificationQueue,
+		m.notificationDelivered,
+		m.notificationShed,
+	} {
+		if err := v.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestMetricsWrite(t *testing.T) {
+	m := New()
+	m.ObserveCall("app1", "/submit", apps.DeployHTTP, apps.CallResponseTypeOK, 20*time.Millisecond)
+	m.ObserveCall("app1", "/submit", apps.DeployHTTP, apps.CallResponseTypeOK, 3*time.Second)
+	m.ObserveCall("app1", "/submit", apps.DeployHTTP, apps.CallResponseTypeError, 40*time.Second)
+	m.ObserveBindings(70 * time.Millisecond)
+	m.ObserveExpand("channel", time.Millisecond, nil)
+	m.ObserveExpand("channel", time.Millisecond, errors.New("not found"))
+	m.SetNotificationQueues([]NotificationQueue{
+		{AppID: "app1", Queued: 3, Capacity: 100, Active: 2, Workers: 4, Delivered: 10, Shed: 1},
+	})
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, m.Write(buf))
+	out := buf.String()
+
+	for _, line := range []string{
+		`# TYPE mattermost_apps_calls_total counter`,
+		`mattermost_apps_calls_total{app_id="app1",path="/submit",deploy_type="http",response_type="ok"} 2`,
+		`mattermost_apps_calls_total{app_id="app1",path="/submit",deploy_type="http",response_type="error"} 1`,
+		`# TYPE mattermost_apps_call_duration_seconds histogram`,
+		`mattermost_apps_call_duration_seconds_bucket{app_id="app1",path="/submit",deploy_type="http",response_type="ok",le="0.025"} 1`,
+		`mattermost_apps_call_duration_seconds_bucket{app_id="app1",path="/submit",deploy_type="http",response_type="ok",le="2.5"} 1`,
+		`mattermost_apps_call_duration_seconds_bucket{app_id="app1",path="/submit",deploy_type="http",response_type="ok",le="5"} 2`,
+		`mattermost_apps_call_duration_seconds_bucket{app_id="app1",path="/submit",deploy_type="http",response_type="error",le="30"} 0`,
+		`mattermost_apps_call_duration_seconds_bucket{app_id="app1",path="/submit",deploy_type="http",response_type="error",le="+Inf"} 1`,
+		`mattermost_apps_call_duration_seconds_sum{app_id="app1",path="/submit",deploy_type="http",response_type="ok"} 3.02`,
+		`mattermost_apps_call_duration_seconds_count{app_id="app1",path="/submit",deploy_type="http",response_type="ok"} 2`,
+		`mattermost_apps_bindings_duration_seconds_bucket{le="0.05"} 0`,
+		`mattermost_apps_bindings_duration_seconds_bucket{le="0.1"} 1`,
+		`mattermost_apps_bindings_duration_seconds_count 1`,
+		`mattermost_apps_expand_api_calls_total{field="channel",result="ok"} 1`,
+		`mattermost_apps_expand_api_calls_total{field="channel",result="error"} 1`,
+		`mattermost_apps_expand_api_call_duration_seconds_count{field="channel"} 2`,
+		`# TYPE mattermost_apps_notification_queue gauge`,
+		`mattermost_apps_notification_queue{app_id="app1",state="queued"} 3`,
+		`mattermost_apps_notification_queue{app_id="app1",state="capacity"} 100`,
+		`mattermost_apps_notifications_delivered_total{app_id="app1"} 10`,
+		`mattermost_apps_notifications_shed_total{app_id="app1"} 1`,
+	} {
+		require.Contains(t, out, line+"\n")
+	}
+
+	// The queue metrics are replaced by each snapshot.
+	m.SetNotificationQueues(nil)
+	buf.Reset()
+	require.NoError(t, m.Write(buf))
+	require.NotContains(t, buf.String(), `app_id="app1",state="queued"`)
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	m := New()
+	m.ObserveCall("app1", "/a\"b\\c\nd", apps.DeployHTTP, apps.CallResponseTypeOK, 0)
+	m.ObserveCall("app1", "/invalid\xff", apps.DeployHTTP, apps.CallResponseTypeOK, 0)
+	m.ObserveCall("app1", "/trailing\\", apps.DeployHTTP, apps.CallResponseTypeOK, 0)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, m.Write(buf))
+	out := buf.String()
+	require.Contains(t, out, `mattermost_apps_calls_total{app_id="app1",path="/a\"b\\c\nd",deploy_type="http",response_type="ok"} 1`+"\n")
+	require.Contains(t, out, "mattermost_apps_calls_total{app_id=\"app1\",path=\"/invalid\uFFFD\",deploy_type=\"http\",response_type=\"ok\"} 1\n")
+	require.Contains(t, out, `mattermost_apps_calls_total{app_id="app1",path="/trailing\\",deploy_type="http",response_type="ok"} 1`+"\n")
+}
+
+var (
+	textFormatName       = `[a-zA-Z_:][a-zA-Z0-9_:]*`
+	textFormatLabel      = `([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\\n]|\\[\\"n])*)"`
+	textFormatLabelRE    = regexp.MustCompile(textFormatLabel)
+	textFormatSampleRE   = regexp.MustCompile(`^(` + textFormatName + `)(?:\{(` + textFormatLabel + `(?:,` + textFormatLabel + `)*)\})? (\S+)$`)
+	textFormatCommentRE  = regexp.MustCompile(`^# (HELP|TYPE) (` + textFormatName + `) (.*)$`)
+	textFormatUnescaper  = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n")
+	textFormatMetricType = map[string]bool{"counter": true, "gauge": true, "histogram": true}
+)
+
+// TestMetricsTextFormat checks the output against the Prometheus text
+// exposition format, version 0.0.4:
+// https://github.com/prometheus/docs/blob/main/content/docs/instrumenting/exposition_formats.md
+func TestMetricsTextFormat(t *testing.T) {
+	m := New()
+	for _, path := range []string{"/submit", "/a\"b\\c\nd", "/invalid\xff\xfe", "/trailing\\", "/ü/日本"} {
+		m.ObserveCall("app1", path, apps.DeployHTTP, apps.CallResponseTypeOK, 20*time.Millisecond)
+		m.ObserveCall("app1", path, apps.DeployHTTP, apps.CallResponseTypeOK, 5*time.Second)
+		m.ObserveCall("app1", path, apps.DeployHTTP, apps.CallResponseTypeError, time.Minute)
+	}
+	m.ObserveBindings(0)
+	m.ObserveExpand("channel", time.Millisecond, nil)
+	m.SetNotificationQueues([]NotificationQueue{{AppID: "app1", Queued: 3, Capacity: 100}})
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, m.Write(buf))
+	require.True(t, strings.HasSuffix(buf.String(), "\n"))
+
+	type histogramSeries struct {
+		les     []string
+		buckets []float64
+		sum     bool
+		count   float64
+		counted bool
+	}
+	types := map[string]string{}
+	helps := map[string]bool{}
+	histograms := map[string]*histogramSeries{}
+	family := ""
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, "#") {
+			match := textFormatCommentRE.FindStringSubmatch(line)
+			require.NotNil(t, match, line)
+			name := match[2]
+			if match[1] == "HELP" {
+				require.False(t, helps[name], "duplicate HELP: %s", line)
+				helps[name] = true
+				continue
+			}
+			require.Empty(t, types[name], "duplicate TYPE: %s", line)
+			require.True(t, textFormatMetricType[match[3]], line)
+			types[name] = match[3]
+			family = name
+			continue
+		}
+
+		match := textFormatSampleRE.FindStringSubmatch(line)
+		require.NotNil(t, match, line)
+		name, value := match[1], match[len(match)-1]
+		parsed, err := strconv.ParseFloat(value, 64)
+		require.NoError(t, err, line)
+
+		labels := map[string]string{}
+		seriesLabels := []string{}
+		for _, l := range textFormatLabelRE.FindAllStringSubmatch(match[2], -1) {
+			require.NotContains(t, labels, l[1], "duplicate label: %s", line)
+			labels[l[1]] = textFormatUnescaper.Replace(l[2])
+			require.True(t, utf8.ValidString(labels[l[1]]), line)
+			if l[1] != "le" {
+				seriesLabels = append(seriesLabels, l[0])
+			}
+		}
+
+		if types[family] != "histogram" {
+			require.Equal(t, family, name, "sample outside of its family: %s", line)
+			require.NotContains(t, labels, "le", line)
+			continue
+		}
+		key := family + "{" + strings.Join(seriesLabels, ",") + "}"
+		h := histograms[key]
+		if h == nil {
+			h = &histogramSeries{}
+			histograms[key] = h
+		}
+		switch name {
+		case family + "_bucket":
+			require.Contains(t, labels, "le", line)
+			require.False(t, h.sum || h.counted, "bucket after the sum or count: %s", line)
+			h.les = append(h.les, labels["le"])
+			h.buckets = append(h.buckets, parsed)
+		case family + "_sum":
+			require.NotContains(t, labels, "le", line)
+			h.sum = true
+		case family + "_count":
+			require.NotContains(t, labels, "le", line)
+			h.count = parsed
+			h.counted = true
+		default:
+			require.Fail(t, "sample outside of its family", line)
+		}
+	}
+
+	for name, typ := range types {
+		require.True(t, helps[name], "no HELP for %s", name)
+		if typ == "histogram" {
+			require.False(t, strings.HasSuffix(name, "_total"), name)
+		}
+		if typ == "counter" {
+			require.True(t, strings.HasSuffix(name, "_total"), name)
+		}
+	}
+	require.NotEmpty(t, histograms)
+	for key, h := range histograms {
+		require.True(t, h.sum, "no _sum for %s", key)
+		require.True(t, h.counted, "no _count for %s", key)
+		require.NotEmpty(t, h.les, key)
+		require.Equal(t, "+Inf", h.les[len(h.les)-1], "the last bucket of %s is not +Inf", key)
+		require.Equal(t, h.count, h.buckets[len(h.buckets)-1], "the +Inf bucket of %s is not the count", key)
+		prev := math.Inf(-1)
+		for i, le := range h.les {
+			upper, err := strconv.ParseFloat(le, 64)
+			require.NoError(t, err, key)
+			require.Greater(t, upper, prev, "the buckets of %s are not sorted", key)
+			prev = upper
+			if i > 0 {
+				require.GreaterOrEqual(t, h.buckets[i], h.buckets[i-1], "the buckets of %s are not cumulative", key)
+			}
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveCall("app1", "/submit", apps.DeployHTTP, apps.CallResponseTypeOK, time.Second)
+	m.ObserveBindings(time.Second)
+	m.ObserveExpand("channel", time.Second, nil)
+	m.SetNotificationQueues(nil)
+	require.NoError(t, m.Write(&bytes.Buffer{}))
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// vec is a metric family: a named metric of a kind, with a value per unique
+// combination of its label values.
+type vec struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+
+	mutex  sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+
+	// value is the value of a counter or a gauge, or the sum of the observed
+	// values of a histogram.
+	value float64
+
+	// count and bucketCounts are the histogram's number of observations, and
+	// the non-cumulative count of them per bucket.
+	count        uint64
+	bucketCounts []uint64
+}
+
+func newVec(name, help, kind string, buckets []float64, labels ...string) *vec {
+	return &vec{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+}
+
+func (v *vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %v label values, got %v", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s := v.series[key]
+	if s == nil {
+		s = &series{
+			labelValues:  append([]string{}, labelValues...),
+			bucketCounts: make([]uint64, len(v.buckets)),
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *vec) add(delta float64, labelValues ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.get(labelValues).value += delta
+}
+
+func (v *vec) set(value float64, labelValues ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.get(labelValues).value = value
+}
+
+func (v *vec) observe(value float64, labelValues ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	s := v.get(labelValues)
+	s.value += value
+	s.count++
+	for i, upper := range v.buckets {
+		if value <= upper {
+			s.bucketCounts[i]++
+			break
+		}
+	}
+}
+
+// reset removes all series, used for the gauges that are re-populated from a
+// snapshot.
+func (v *vec) reset() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.series = map[string]*series{}
+}
+
+// write outputs the metric family in the Prometheus text exposition format.
+func (v *vec) write(w io.Writer) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	keys := []string{}
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", v.name, v.kind)
+	for _, k := range keys {
+		s := v.series[k]
+		if v.kind != kindHistogram {
+			fmt.Fprintf(b, "%s%s %s\n", v.name, v.formatLabels(s.labelValues, "", ""), formatFloat(s.value))
+			continue
+		}
+
+		cumulative := uint64(0)
+		for i, upper := range v.buckets {
+			cumulative += s.bucketCounts[i]
+			fmt.Fprintf(b, "%s_bucket%s %v\n", v.name, v.formatLabels(s.labelValues, "le", formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %v\n", v.name, v.formatLabels(s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", v.name, v.formatLabels(s.labelValues, "", ""), formatFloat(s.value))
+		fmt.Fprintf(b, "%s_count%s %v\n", v.name, v.formatLabels(s.labelValues, "", ""), s.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (v *vec) formatLabels(labelValues []string, extraName, extraValue string) string {
+	pairs := []string{}
+	for i, name := range v.labels {
+		pairs = append(pairs, name+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// escapeLabelValue escapes a label value, and replaces the invalid UTF-8, that
+// the text format does not allow, e.g. in the call paths.
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(strings.ToValidUTF8(s, "\uFFFD"))
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
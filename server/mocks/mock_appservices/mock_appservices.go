@@ -9,7 +9,9 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	apps "github.com/mattermost/mattermost-plugin-apps/apps"
+	appservices "github.com/mattermost/mattermost-plugin-apps/server/appservices"
 	incoming "github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	store "github.com/mattermost/mattermost-plugin-apps/server/store"
 )
 
 // MockService is a mock of Service interface.
@@ -35,6 +37,35 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelSchedule mocks base method.
+func (m *MockService) CancelSchedule(arg0 *incoming.Request, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockServiceMockRecorder) CancelSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockService)(nil).CancelSchedule), arg0, arg1)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockService) CreateSchedule(arg0 *incoming.Request, arg1 apps.Schedule) (*apps.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", arg0, arg1)
+	ret0, _ := ret[0].(*apps.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockServiceMockRecorder) CreateSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockService)(nil).CreateSchedule), arg0, arg1)
+}
+
 // GetOAuth2User mocks base method.
 func (m *MockService) GetOAuth2User(arg0 *incoming.Request) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +96,36 @@ func (mr *MockServiceMockRecorder) GetSubscriptions(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockService)(nil).GetSubscriptions), arg0)
 }
 
+// KVDebugAppInfo mocks base method.
+func (m *MockService) KVDebugAppInfo(arg0 *incoming.Request, arg1 apps.AppID) (*store.KVDebugAppInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KVDebugAppInfo", arg0, arg1)
+	ret0, _ := ret[0].(*store.KVDebugAppInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KVDebugAppInfo indicates an expected call of KVDebugAppInfo.
+func (mr *MockServiceMockRecorder) KVDebugAppInfo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KVDebugAppInfo", reflect.TypeOf((*MockService)(nil).KVDebugAppInfo), arg0, arg1)
+}
+
+// KVDebugInfo mocks base method.
+func (m *MockService) KVDebugInfo(arg0 *incoming.Request) (*store.KVDebugInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KVDebugInfo", arg0)
+	ret0, _ := ret[0].(*store.KVDebugInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KVDebugInfo indicates an expected call of KVDebugInfo.
+func (mr *MockServiceMockRecorder) KVDebugInfo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KVDebugInfo", reflect.TypeOf((*MockService)(nil).KVDebugInfo), arg0)
+}
+
 // KVDelete mocks base method.
 func (m *MockService) KVDelete(arg0 *incoming.Request, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -123,6 +184,36 @@ func (mr *MockServiceMockRecorder) KVSet(arg0, arg1, arg2, arg3 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KVSet", reflect.TypeOf((*MockService)(nil).KVSet), arg0, arg1, arg2, arg3)
 }
 
+// ListSchedules mocks base method.
+func (m *MockService) ListSchedules(arg0 *incoming.Request) ([]apps.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", arg0)
+	ret0, _ := ret[0].([]apps.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockServiceMockRecorder) ListSchedules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockService)(nil).ListSchedules), arg0)
+}
+
+// RemoveInvalidSubscriptions mocks base method.
+func (m *MockService) RemoveInvalidSubscriptions(arg0 *incoming.Request, arg1 func(store.Subscription) bool) ([]appservices.RemovedSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveInvalidSubscriptions", arg0, arg1)
+	ret0, _ := ret[0].([]appservices.RemovedSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveInvalidSubscriptions indicates an expected call of RemoveInvalidSubscriptions.
+func (mr *MockServiceMockRecorder) RemoveInvalidSubscriptions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveInvalidSubscriptions", reflect.TypeOf((*MockService)(nil).RemoveInvalidSubscriptions), arg0, arg1)
+}
+
 // StoreOAuth2App mocks base method.
 func (m *MockService) StoreOAuth2App(arg0 *incoming.Request, arg1 []byte) error {
 	m.ctrl.T.Helper()
@@ -178,3 +269,31 @@ func (mr *MockServiceMockRecorder) Unsubscribe(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockService)(nil).Unsubscribe), arg0, arg1)
 }
+
+// UnsubscribeApp mocks base method.
+func (m *MockService) UnsubscribeApp(arg0 *incoming.Request, arg1 apps.AppID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeApp", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsubscribeApp indicates an expected call of UnsubscribeApp.
+func (mr *MockServiceMockRecorder) UnsubscribeApp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeApp", reflect.TypeOf((*MockService)(nil).UnsubscribeApp), arg0, arg1)
+}
+
+// UpdateCallJobProgress mocks base method.
+func (m *MockService) UpdateCallJobProgress(arg0 *incoming.Request, arg1 string, arg2 apps.CallJobProgress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCallJobProgress", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCallJobProgress indicates an expected call of UpdateCallJobProgress.
+func (mr *MockServiceMockRecorder) UpdateCallJobProgress(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCallJobProgress", reflect.TypeOf((*MockService)(nil).UpdateCallJobProgress), arg0, arg1, arg2)
+}
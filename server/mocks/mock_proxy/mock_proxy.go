@@ -15,6 +15,7 @@ import (
 	config "github.com/mattermost/mattermost-plugin-apps/server/config"
 	incoming "github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	proxy "github.com/mattermost/mattermost-plugin-apps/server/proxy"
+	store "github.com/mattermost/mattermost-plugin-apps/server/store"
 	upstream "github.com/mattermost/mattermost-plugin-apps/upstream"
 	utils "github.com/mattermost/mattermost-plugin-apps/utils"
 	model "github.com/mattermost/mattermost-server/v6/model"
@@ -70,6 +71,58 @@ func (mr *MockServiceMockRecorder) CanDeploy(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanDeploy", reflect.TypeOf((*MockService)(nil).CanDeploy), arg0)
 }
 
+// CancelSchedule mocks base method.
+func (m *MockService) CancelSchedule(arg0 *incoming.Request, arg1 apps.AppID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockServiceMockRecorder) CancelSchedule(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockService)(nil).CancelSchedule), arg0, arg1, arg2)
+}
+
+// CheckSubscriptionOwners mocks base method.
+func (m *MockService) CheckSubscriptionOwners() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CheckSubscriptionOwners")
+}
+
+// CheckSubscriptionOwners indicates an expected call of CheckSubscriptionOwners.
+func (mr *MockServiceMockRecorder) CheckSubscriptionOwners() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSubscriptionOwners", reflect.TypeOf((*MockService)(nil).CheckSubscriptionOwners))
+}
+
+// CircuitBreakerState mocks base method.
+func (m *MockService) CircuitBreakerState(arg0 apps.AppID) proxy.CircuitState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CircuitBreakerState", arg0)
+	ret0, _ := ret[0].(proxy.CircuitState)
+	return ret0
+}
+
+// CircuitBreakerState indicates an expected call of CircuitBreakerState.
+func (mr *MockServiceMockRecorder) CircuitBreakerState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CircuitBreakerState", reflect.TypeOf((*MockService)(nil).CircuitBreakerState), arg0)
+}
+
+// Close mocks base method.
+func (m *MockService) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockServiceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockService)(nil).Close))
+}
+
 // Configure mocks base method.
 func (m *MockService) Configure(arg0 config.Config, arg1 utils.Logger) error {
 	m.ctrl.T.Helper()
@@ -84,6 +137,18 @@ func (mr *MockServiceMockRecorder) Configure(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configure", reflect.TypeOf((*MockService)(nil).Configure), arg0, arg1)
 }
 
+// DetectUserChanges mocks base method.
+func (m *MockService) DetectUserChanges() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DetectUserChanges")
+}
+
+// DetectUserChanges indicates an expected call of DetectUserChanges.
+func (mr *MockServiceMockRecorder) DetectUserChanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectUserChanges", reflect.TypeOf((*MockService)(nil).DetectUserChanges))
+}
+
 // DisableApp mocks base method.
 func (m *MockService) DisableApp(arg0 *incoming.Request, arg1 apps.Context, arg2 apps.AppID) (string, error) {
 	m.ctrl.T.Helper()
@@ -144,6 +209,21 @@ func (mr *MockServiceMockRecorder) GetBindings(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBindings", reflect.TypeOf((*MockService)(nil).GetBindings), arg0, arg1)
 }
 
+// GetCallJob mocks base method.
+func (m *MockService) GetCallJob(arg0 *incoming.Request, arg1 string) (*apps.CallJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCallJob", arg0, arg1)
+	ret0, _ := ret[0].(*apps.CallJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCallJob indicates an expected call of GetCallJob.
+func (mr *MockServiceMockRecorder) GetCallJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCallJob", reflect.TypeOf((*MockService)(nil).GetCallJob), arg0, arg1)
+}
+
 // GetInstalledApp mocks base method.
 func (m *MockService) GetInstalledApp(arg0 apps.AppID, arg1 bool) (*apps.App, error) {
 	m.ctrl.T.Helper()
@@ -218,6 +298,20 @@ func (mr *MockServiceMockRecorder) InstallApp(arg0, arg1, arg2, arg3, arg4, arg5
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstallApp", reflect.TypeOf((*MockService)(nil).InstallApp), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// InvokeAppCall mocks base method.
+func (m *MockService) InvokeAppCall(arg0 *incoming.Request, arg1 apps.CallRequest) proxy.CallResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvokeAppCall", arg0, arg1)
+	ret0, _ := ret[0].(proxy.CallResponse)
+	return ret0
+}
+
+// InvokeAppCall indicates an expected call of InvokeAppCall.
+func (mr *MockServiceMockRecorder) InvokeAppCall(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvokeAppCall", reflect.TypeOf((*MockService)(nil).InvokeAppCall), arg0, arg1)
+}
+
 // InvokeCall mocks base method.
 func (m *MockService) InvokeCall(arg0 *incoming.Request, arg1 apps.CallRequest) proxy.CallResponse {
 	m.ctrl.T.Helper()
@@ -306,18 +400,116 @@ func (mr *MockServiceMockRecorder) InvokeRemoteWebhook(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvokeRemoteWebhook", reflect.TypeOf((*MockService)(nil).InvokeRemoteWebhook), arg0, arg1)
 }
 
+// ListAudit mocks base method.
+func (m *MockService) ListAudit(arg0 *incoming.Request, arg1 store.AuditFilter) ([]store.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudit", arg0, arg1)
+	ret0, _ := ret[0].([]store.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAudit indicates an expected call of ListAudit.
+func (mr *MockServiceMockRecorder) ListAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudit", reflect.TypeOf((*MockService)(nil).ListAudit), arg0, arg1)
+}
+
+// ListFailedNotifications mocks base method.
+func (m *MockService) ListFailedNotifications(arg0 *incoming.Request, arg1 apps.AppID) ([]store.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailedNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]store.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailedNotifications indicates an expected call of ListFailedNotifications.
+func (mr *MockServiceMockRecorder) ListFailedNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailedNotifications", reflect.TypeOf((*MockService)(nil).ListFailedNotifications), arg0, arg1)
+}
+
+// ListRecordedCalls mocks base method.
+func (m *MockService) ListRecordedCalls(arg0 *incoming.Request, arg1 apps.AppID) ([]proxy.RecordedCall, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecordedCalls", arg0, arg1)
+	ret0, _ := ret[0].([]proxy.RecordedCall)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecordedCalls indicates an expected call of ListRecordedCalls.
+func (mr *MockServiceMockRecorder) ListRecordedCalls(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecordedCalls", reflect.TypeOf((*MockService)(nil).ListRecordedCalls), arg0, arg1)
+}
+
+// ListSchedules mocks base method.
+func (m *MockService) ListSchedules(arg0 *incoming.Request, arg1 apps.AppID) ([]apps.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", arg0, arg1)
+	ret0, _ := ret[0].([]apps.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockServiceMockRecorder) ListSchedules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockService)(nil).ListSchedules), arg0, arg1)
+}
+
 // NewIncomingRequest mocks base method.
-func (m *MockService) NewIncomingRequest(arg0 utils.Logger) *incoming.Request {
+func (m *MockService) NewIncomingRequest() *incoming.Request {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewIncomingRequest", arg0)
+	ret := m.ctrl.Call(m, "NewIncomingRequest")
 	ret0, _ := ret[0].(*incoming.Request)
 	return ret0
 }
 
 // NewIncomingRequest indicates an expected call of NewIncomingRequest.
-func (mr *MockServiceMockRecorder) NewIncomingRequest(arg0 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) NewIncomingRequest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIncomingRequest", reflect.TypeOf((*MockService)(nil).NewIncomingRequest))
+}
+
+// NotificationQueueStats mocks base method.
+func (m *MockService) NotificationQueueStats() []proxy.NotificationQueueStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotificationQueueStats")
+	ret0, _ := ret[0].([]proxy.NotificationQueueStats)
+	return ret0
+}
+
+// NotificationQueueStats indicates an expected call of NotificationQueueStats.
+func (mr *MockServiceMockRecorder) NotificationQueueStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotificationQueueStats", reflect.TypeOf((*MockService)(nil).NotificationQueueStats))
+}
+
+// NotifyChannelArchived mocks base method.
+func (m *MockService) NotifyChannelArchived(arg0 *model.Channel, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyChannelArchived", arg0, arg1)
+}
+
+// NotifyChannelArchived indicates an expected call of NotifyChannelArchived.
+func (mr *MockServiceMockRecorder) NotifyChannelArchived(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChannelArchived", reflect.TypeOf((*MockService)(nil).NotifyChannelArchived), arg0, arg1)
+}
+
+// NotifyChannelConverted mocks base method.
+func (m *MockService) NotifyChannelConverted(arg0, arg1 *model.Channel, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyChannelConverted", arg0, arg1, arg2)
+}
+
+// NotifyChannelConverted indicates an expected call of NotifyChannelConverted.
+func (mr *MockServiceMockRecorder) NotifyChannelConverted(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIncomingRequest", reflect.TypeOf((*MockService)(nil).NewIncomingRequest), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChannelConverted", reflect.TypeOf((*MockService)(nil).NotifyChannelConverted), arg0, arg1, arg2)
 }
 
 // NotifyChannelCreated mocks base method.
@@ -332,6 +524,66 @@ func (mr *MockServiceMockRecorder) NotifyChannelCreated(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChannelCreated", reflect.TypeOf((*MockService)(nil).NotifyChannelCreated), arg0, arg1)
 }
 
+// NotifyChannelRestored mocks base method.
+func (m *MockService) NotifyChannelRestored(arg0 *model.Channel, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyChannelRestored", arg0, arg1)
+}
+
+// NotifyChannelRestored indicates an expected call of NotifyChannelRestored.
+func (mr *MockServiceMockRecorder) NotifyChannelRestored(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChannelRestored", reflect.TypeOf((*MockService)(nil).NotifyChannelRestored), arg0, arg1)
+}
+
+// NotifyChannelUpdated mocks base method.
+func (m *MockService) NotifyChannelUpdated(arg0, arg1 *model.Channel, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyChannelUpdated", arg0, arg1, arg2)
+}
+
+// NotifyChannelUpdated indicates an expected call of NotifyChannelUpdated.
+func (mr *MockServiceMockRecorder) NotifyChannelUpdated(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChannelUpdated", reflect.TypeOf((*MockService)(nil).NotifyChannelUpdated), arg0, arg1, arg2)
+}
+
+// NotifyMessageHasBeenPosted mocks base method.
+func (m *MockService) NotifyMessageHasBeenPosted(arg0 *model.Post) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyMessageHasBeenPosted", arg0)
+}
+
+// NotifyMessageHasBeenPosted indicates an expected call of NotifyMessageHasBeenPosted.
+func (mr *MockServiceMockRecorder) NotifyMessageHasBeenPosted(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyMessageHasBeenPosted", reflect.TypeOf((*MockService)(nil).NotifyMessageHasBeenPosted), arg0)
+}
+
+// NotifyReactionHasBeenAdded mocks base method.
+func (m *MockService) NotifyReactionHasBeenAdded(arg0 *model.Reaction) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyReactionHasBeenAdded", arg0)
+}
+
+// NotifyReactionHasBeenAdded indicates an expected call of NotifyReactionHasBeenAdded.
+func (mr *MockServiceMockRecorder) NotifyReactionHasBeenAdded(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyReactionHasBeenAdded", reflect.TypeOf((*MockService)(nil).NotifyReactionHasBeenAdded), arg0)
+}
+
+// NotifyReactionHasBeenRemoved mocks base method.
+func (m *MockService) NotifyReactionHasBeenRemoved(arg0 *model.Reaction) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyReactionHasBeenRemoved", arg0)
+}
+
+// NotifyReactionHasBeenRemoved indicates an expected call of NotifyReactionHasBeenRemoved.
+func (mr *MockServiceMockRecorder) NotifyReactionHasBeenRemoved(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyReactionHasBeenRemoved", reflect.TypeOf((*MockService)(nil).NotifyReactionHasBeenRemoved), arg0)
+}
+
 // NotifyUserCreated mocks base method.
 func (m *MockService) NotifyUserCreated(arg0 string) {
 	m.ctrl.T.Helper()
@@ -344,8 +596,20 @@ func (mr *MockServiceMockRecorder) NotifyUserCreated(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUserCreated", reflect.TypeOf((*MockService)(nil).NotifyUserCreated), arg0)
 }
 
+// NotifyUserDeactivated mocks base method.
+func (m *MockService) NotifyUserDeactivated(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyUserDeactivated", arg0)
+}
+
+// NotifyUserDeactivated indicates an expected call of NotifyUserDeactivated.
+func (mr *MockServiceMockRecorder) NotifyUserDeactivated(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUserDeactivated", reflect.TypeOf((*MockService)(nil).NotifyUserDeactivated), arg0)
+}
+
 // NotifyUserJoinedChannel mocks base method.
-func (m *MockService) NotifyUserJoinedChannel(arg0 string, arg1 *model.User) {
+func (m *MockService) NotifyUserJoinedChannel(arg0, arg1 string) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "NotifyUserJoinedChannel", arg0, arg1)
 }
@@ -357,7 +621,7 @@ func (mr *MockServiceMockRecorder) NotifyUserJoinedChannel(arg0, arg1 interface{
 }
 
 // NotifyUserJoinedTeam mocks base method.
-func (m *MockService) NotifyUserJoinedTeam(arg0 string, arg1 *model.User) {
+func (m *MockService) NotifyUserJoinedTeam(arg0, arg1 string) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "NotifyUserJoinedTeam", arg0, arg1)
 }
@@ -369,7 +633,7 @@ func (mr *MockServiceMockRecorder) NotifyUserJoinedTeam(arg0, arg1 interface{})
 }
 
 // NotifyUserLeftChannel mocks base method.
-func (m *MockService) NotifyUserLeftChannel(arg0 string, arg1 *model.User) {
+func (m *MockService) NotifyUserLeftChannel(arg0, arg1 string) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "NotifyUserLeftChannel", arg0, arg1)
 }
@@ -381,7 +645,7 @@ func (mr *MockServiceMockRecorder) NotifyUserLeftChannel(arg0, arg1 interface{})
 }
 
 // NotifyUserLeftTeam mocks base method.
-func (m *MockService) NotifyUserLeftTeam(arg0 string, arg1 *model.User) {
+func (m *MockService) NotifyUserLeftTeam(arg0, arg1 string) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "NotifyUserLeftTeam", arg0, arg1)
 }
@@ -392,6 +656,42 @@ func (mr *MockServiceMockRecorder) NotifyUserLeftTeam(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUserLeftTeam", reflect.TypeOf((*MockService)(nil).NotifyUserLeftTeam), arg0, arg1)
 }
 
+// NotifyUserReactivated mocks base method.
+func (m *MockService) NotifyUserReactivated(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyUserReactivated", arg0)
+}
+
+// NotifyUserReactivated indicates an expected call of NotifyUserReactivated.
+func (mr *MockServiceMockRecorder) NotifyUserReactivated(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUserReactivated", reflect.TypeOf((*MockService)(nil).NotifyUserReactivated), arg0)
+}
+
+// NotifyUserRoleChanged mocks base method.
+func (m *MockService) NotifyUserRoleChanged(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyUserRoleChanged", arg0)
+}
+
+// NotifyUserRoleChanged indicates an expected call of NotifyUserRoleChanged.
+func (mr *MockServiceMockRecorder) NotifyUserRoleChanged(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUserRoleChanged", reflect.TypeOf((*MockService)(nil).NotifyUserRoleChanged), arg0)
+}
+
+// NotifyUserUpdated mocks base method.
+func (m *MockService) NotifyUserUpdated(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyUserUpdated", arg0)
+}
+
+// NotifyUserUpdated indicates an expected call of NotifyUserUpdated.
+func (mr *MockServiceMockRecorder) NotifyUserUpdated(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUserUpdated", reflect.TypeOf((*MockService)(nil).NotifyUserUpdated), arg0)
+}
+
 // PingInstalledApps mocks base method.
 func (m *MockService) PingInstalledApps(arg0 context.Context) ([]apps.App, map[apps.AppID]bool) {
 	m.ctrl.T.Helper()
@@ -407,6 +707,100 @@ func (mr *MockServiceMockRecorder) PingInstalledApps(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingInstalledApps", reflect.TypeOf((*MockService)(nil).PingInstalledApps), arg0)
 }
 
+// PruneAudit mocks base method.
+func (m *MockService) PruneAudit() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PruneAudit")
+}
+
+// PruneAudit indicates an expected call of PruneAudit.
+func (mr *MockServiceMockRecorder) PruneAudit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneAudit", reflect.TypeOf((*MockService)(nil).PruneAudit))
+}
+
+// PurgeFailedNotifications mocks base method.
+func (m *MockService) PurgeFailedNotifications(arg0 *incoming.Request, arg1 apps.AppID, arg2 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeFailedNotifications", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeFailedNotifications indicates an expected call of PurgeFailedNotifications.
+func (mr *MockServiceMockRecorder) PurgeFailedNotifications(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeFailedNotifications", reflect.TypeOf((*MockService)(nil).PurgeFailedNotifications), arg0, arg1, arg2)
+}
+
+// RecordPostBindingPaths mocks base method.
+func (m *MockService) RecordPostBindingPaths(arg0 *model.Post) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordPostBindingPaths", arg0)
+}
+
+// RecordPostBindingPaths indicates an expected call of RecordPostBindingPaths.
+func (mr *MockServiceMockRecorder) RecordPostBindingPaths(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPostBindingPaths", reflect.TypeOf((*MockService)(nil).RecordPostBindingPaths), arg0)
+}
+
+// ReplayFailedNotifications mocks base method.
+func (m *MockService) ReplayFailedNotifications(arg0 *incoming.Request, arg1 apps.AppID, arg2 string) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayFailedNotifications", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReplayFailedNotifications indicates an expected call of ReplayFailedNotifications.
+func (mr *MockServiceMockRecorder) ReplayFailedNotifications(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayFailedNotifications", reflect.TypeOf((*MockService)(nil).ReplayFailedNotifications), arg0, arg1, arg2)
+}
+
+// ReplayRecordedCall mocks base method.
+func (m *MockService) ReplayRecordedCall(arg0 *incoming.Request, arg1 apps.AppID, arg2 string) (*proxy.CallReplay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayRecordedCall", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*proxy.CallReplay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayRecordedCall indicates an expected call of ReplayRecordedCall.
+func (mr *MockServiceMockRecorder) ReplayRecordedCall(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayRecordedCall", reflect.TypeOf((*MockService)(nil).ReplayRecordedCall), arg0, arg1, arg2)
+}
+
+// RetryFailedNotifications mocks base method.
+func (m *MockService) RetryFailedNotifications() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RetryFailedNotifications")
+}
+
+// RetryFailedNotifications indicates an expected call of RetryFailedNotifications.
+func (mr *MockServiceMockRecorder) RetryFailedNotifications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailedNotifications", reflect.TypeOf((*MockService)(nil).RetryFailedNotifications))
+}
+
+// RunScheduledCalls mocks base method.
+func (m *MockService) RunScheduledCalls() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunScheduledCalls")
+}
+
+// RunScheduledCalls indicates an expected call of RunScheduledCalls.
+func (mr *MockServiceMockRecorder) RunScheduledCalls() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunScheduledCalls", reflect.TypeOf((*MockService)(nil).RunScheduledCalls))
+}
+
 // SynchronizeInstalledApps mocks base method.
 func (m *MockService) SynchronizeInstalledApps() error {
 	m.ctrl.T.Helper()
@@ -422,18 +816,18 @@ func (mr *MockServiceMockRecorder) SynchronizeInstalledApps() *gomock.Call {
 }
 
 // UninstallApp mocks base method.
-func (m *MockService) UninstallApp(arg0 *incoming.Request, arg1 apps.Context, arg2 apps.AppID) (string, error) {
+func (m *MockService) UninstallApp(arg0 *incoming.Request, arg1 apps.Context, arg2 apps.AppID, arg3 bool) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UninstallApp", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UninstallApp", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UninstallApp indicates an expected call of UninstallApp.
-func (mr *MockServiceMockRecorder) UninstallApp(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) UninstallApp(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallApp", reflect.TypeOf((*MockService)(nil).UninstallApp), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallApp", reflect.TypeOf((*MockService)(nil).UninstallApp), arg0, arg1, arg2, arg3)
 }
 
 // UpdateAppListing mocks base method.
@@ -450,3 +844,17 @@ func (mr *MockServiceMockRecorder) UpdateAppListing(arg0, arg1 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppListing", reflect.TypeOf((*MockService)(nil).UpdateAppListing), arg0, arg1)
 }
+
+// WriteMetrics mocks base method.
+func (m *MockService) WriteMetrics(arg0 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteMetrics", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteMetrics indicates an expected call of WriteMetrics.
+func (mr *MockServiceMockRecorder) WriteMetrics(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteMetrics", reflect.TypeOf((*MockService)(nil).WriteMetrics), arg0)
+}
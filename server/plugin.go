@@ -45,6 +45,12 @@ type Plugin struct {
 
 	telemetryClient mmtelemetry.Client
 	tracker         *telemetry.Telemetry
+
+	notificationRetryJob *cluster.Job
+	userChangesJob       *cluster.Job
+	subscriptionsJob     *cluster.Job
+	scheduledCallsJob    *cluster.Job
+	auditPruneJob        *cluster.Job
 }
 
 func NewPlugin(pluginManifest model.Manifest) *Plugin {
@@ -97,7 +103,7 @@ func (p *Plugin) OnActivate() (err error) {
 
 	// Initialize persistent storage. Also initialize the app API and the
 	// session services, both need the persisitent store.
-	p.store, err = store.MakeService(p.log, p.conf, p.httpOut)
+	p.store, err = store.MakeService(p.log, p.conf, p.httpOut, p.API)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize persistent store")
 	}
@@ -122,6 +128,32 @@ func (p *Plugin) OnActivate() (err error) {
 	)
 	p.log.Debugf("initialized the app proxy")
 
+	p.notificationRetryJob, err = cluster.Schedule(p.API, "NotificationRetryJob",
+		cluster.MakeWaitForInterval(proxy.NotificationRetryInterval), p.proxy.RetryFailedNotifications)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the notification retry job")
+	}
+	p.userChangesJob, err = cluster.Schedule(p.API, "UserChangesJob",
+		cluster.MakeWaitForInterval(proxy.UserChangesInterval), p.proxy.DetectUserChanges)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the user changes job")
+	}
+	p.subscriptionsJob, err = cluster.Schedule(p.API, "SubscriptionOwnersJob",
+		cluster.MakeWaitForInterval(proxy.SubscriptionOwnersInterval), p.proxy.CheckSubscriptionOwners)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the subscription owners job")
+	}
+	p.scheduledCallsJob, err = cluster.Schedule(p.API, "ScheduledCallsJob",
+		cluster.MakeWaitForInterval(proxy.ScheduledCallsInterval), p.proxy.RunScheduledCalls)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the scheduled calls job")
+	}
+	p.auditPruneJob, err = cluster.Schedule(p.API, "AuditPruneJob",
+		cluster.MakeWaitForInterval(proxy.AuditPruneInterval), p.proxy.PruneAudit)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the audit log prune job")
+	}
+
 	p.httpIn = httpin.NewService(p.proxy, p.appservices, p.conf, p.log)
 	p.log.Debugf("initialized incoming HTTP")
 
@@ -144,6 +176,36 @@ func (p *Plugin) OnDeactivate() error { //nolint:golint,unparam
 	conf := p.conf.Get()
 	p.conf.MattermostAPI().Frontend.PublishWebSocketEvent(config.WebSocketEventPluginDisabled, conf.GetPluginVersionInfo(), &model.WebsocketBroadcast{})
 
+	if p.notificationRetryJob != nil {
+		if err := p.notificationRetryJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to stop the notification retry job", "error", err.Error())
+		}
+	}
+	if p.userChangesJob != nil {
+		if err := p.userChangesJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to stop the user changes job", "error", err.Error())
+		}
+	}
+	if p.subscriptionsJob != nil {
+		if err := p.subscriptionsJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to stop the subscription owners job", "error", err.Error())
+		}
+	}
+	if p.scheduledCallsJob != nil {
+		if err := p.scheduledCallsJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to stop the scheduled calls job", "error", err.Error())
+		}
+	}
+	if p.auditPruneJob != nil {
+		if err := p.auditPruneJob.Close(); err != nil {
+			p.API.LogWarn("OnDeactivate: failed to stop the audit log prune job", "error", err.Error())
+		}
+	}
+
+	if p.proxy != nil {
+		p.proxy.Close()
+	}
+
 	if p.telemetryClient != nil {
 		err := p.telemetryClient.Close()
 		if err != nil {
@@ -208,3 +270,27 @@ func (p *Plugin) UserHasLeftTeam(_ *plugin.Context, tm *model.TeamMember, _ *mod
 func (p *Plugin) ChannelHasBeenCreated(_ *plugin.Context, ch *model.Channel) {
 	p.proxy.NotifyChannelCreated(ch.TeamId, ch.Id)
 }
+
+func (p *Plugin) MessageHasBeenPosted(_ *plugin.Context, post *model.Post) {
+	p.proxy.RecordPostBindingPaths(post)
+	p.proxy.NotifyMessageHasBeenPosted(post)
+}
+
+func (p *Plugin) MessageHasBeenUpdated(_ *plugin.Context, newPost, _ *model.Post) {
+	p.proxy.RecordPostBindingPaths(newPost)
+}
+
+func (p *Plugin) ReactionHasBeenAdded(_ *plugin.Context, reaction *model.Reaction) {
+	p.proxy.NotifyReactionHasBeenAdded(reaction)
+}
+
+func (p *Plugin) ReactionHasBeenRemoved(_ *plugin.Context, reaction *model.Reaction) {
+	p.proxy.NotifyReactionHasBeenRemoved(reaction)
+}
+
+func (p *Plugin) OnPluginClusterEvent(_ *plugin.Context, ev model.PluginClusterEvent) {
+	err := p.store.OnPluginClusterEvent(ev)
+	if err != nil {
+		p.log.WithError(err).Warnf("failed to process cluster event %s", ev.Id)
+	}
+}
@@ -36,6 +36,28 @@ func TestOnActivate(t *testing.T) {
 
 	testAPI.On("GetBundlePath").Return("../", nil)
 
+	testAPI.On("KVList", 0, 1000).Return([]string{}, nil)
+
+	testAPI.On("KVSetWithOptions", "mutex_cron_NotificationRetryJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVSetWithOptions", "cron_NotificationRetryJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVGet", "cron_NotificationRetryJob").Return(nil, nil)
+	testAPI.On("KVGet", "ntf.r.index.apps").Return(nil, nil)
+	testAPI.On("KVGet", "sch.apps").Return(nil, nil)
+	testAPI.On("KVSetWithOptions", "mutex_cron_UserChangesJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVSetWithOptions", "cron_UserChangesJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVGet", "cron_UserChangesJob").Return(nil, nil)
+	testAPI.On("KVSetWithOptions", "mutex_cron_SubscriptionOwnersJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVSetWithOptions", "cron_SubscriptionOwnersJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVGet", "cron_SubscriptionOwnersJob").Return(nil, nil)
+	testAPI.On("HasPermissionTo", "", model.PermissionManageSystem).Return(false)
+	testAPI.On("KVSetWithOptions", "mutex_cron_ScheduledCallsJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVSetWithOptions", "cron_ScheduledCallsJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVGet", "cron_ScheduledCallsJob").Return(nil, nil)
+	testAPI.On("KVSetWithOptions", "mutex_cron_AuditPruneJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVSetWithOptions", "cron_AuditPruneJob", mock.Anything, mock.Anything).Return(true, nil)
+	testAPI.On("KVGet", "cron_AuditPruneJob").Return(nil, nil)
+	testAPI.On("KVGet", "aub").Return(nil, nil)
+
 	testAPI.On("SetProfileImage", "the_bot_id", mock.AnythingOfType("[]uint8")).Return(nil)
 
 	testAPI.On("LoadPluginConfiguration", mock.AnythingOfType("*config.StoredConfig")).Return(nil)
@@ -64,6 +86,10 @@ func TestOnActivate(t *testing.T) {
 
 	err := p.OnActivate()
 	require.NoError(t, err)
+	require.NoError(t, p.notificationRetryJob.Close())
+	require.NoError(t, p.userChangesJob.Close())
+	require.NoError(t, p.subscriptionsJob.Close())
+	require.NoError(t, p.scheduledCallsJob.Close())
 }
 
 func TestOnDeactivate(t *testing.T) {
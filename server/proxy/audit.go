@@ -0,0 +1,167 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+const (
+	auditResultOK    = "ok"
+	auditResultError = "error"
+)
+
+// auditQueueSize is the number of audit entries that may wait to be stored.
+const auditQueueSize = 1000
+
+// AuditPruneInterval is how often the audit log is pruned of the entries past
+// its maximum size.
+const AuditPruneInterval = 10 * time.Minute
+
+// ListAudit returns the entries of the audit log that match the filter, the
+// newest first.
+func (p *Proxy) ListAudit(r *incoming.Request, f store.AuditFilter) ([]store.AuditEntry, error) {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	return p.store.Audit.List(f)
+}
+
+// auditCall records a call made from a user agent, or from another app.
+func (p *Proxy) auditCall(r *incoming.Request, appID apps.AppID, callPath string, cresp apps.CallResponse) {
+	e := store.AuditEntry{
+		Event:       store.AuditEventCall,
+		AppID:       appID,
+		CallerAppID: r.SourceAppID(),
+		Path:        callPath,
+		Result:      string(cresp.Type),
+	}
+	if cresp.Type == apps.CallResponseTypeError {
+		e.Error = cresp.Error()
+	}
+	p.audit(r, e)
+}
+
+// auditAction records an app lifecycle action, or an OAuth2 connection. The
+// admin lifecycle actions are also logged to the server log.
+func (p *Proxy) auditAction(r *incoming.Request, event store.AuditEvent, appID apps.AppID, err error) {
+	e := store.AuditEntry{
+		Event:  event,
+		AppID:  appID,
+		Result: auditResultOK,
+	}
+	if err != nil {
+		e.Result = auditResultError
+		e.Error = err.Error()
+	}
+
+	// The plugin API does not provide access to the server's audit log, the
+	// admin actions are logged to the server log instead, with an "audit"
+	// marker to filter them by.
+	if event != store.AuditEventOAuth2Connect {
+		p.conf.MattermostAPI().Log.Info("Apps audit: "+string(event),
+			"audit", true,
+			"app_id", appID,
+			"user_id", r.ActingUserID(),
+			"request_id", r.RequestID(),
+			"result", e.Result,
+			"error", e.Error)
+	}
+	p.audit(r, e)
+}
+
+func (p *Proxy) audit(r *incoming.Request, e store.AuditEntry) {
+	if p.conf.Get().AuditLog.MaxEntries <= 0 {
+		return
+	}
+	e.CreateAt = time.Now().UnixMilli()
+	e.UserID = r.ActingUserID()
+	e.RequestID = r.RequestID()
+	p.auditLog.enqueue(p, e)
+}
+
+// auditWriter stores the audit entries in the background, off the request
+// path. If too many entries are waiting, the new ones are written to the server
+// log instead. The zero value is ready to use.
+type auditWriter struct {
+	mutex   sync.Mutex
+	entries chan store.AuditEntry
+	done    chan struct{}
+	closed  bool
+}
+
+func (w *auditWriter) enqueue(p *Proxy, e store.AuditEntry) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		p.logAuditEntry(e, "the audit log is closed")
+		return
+	}
+	if w.entries == nil {
+		w.entries = make(chan store.AuditEntry, auditQueueSize)
+		w.done = make(chan struct{})
+		go w.work(p)
+	}
+	select {
+	case w.entries <- e:
+	default:
+		p.logAuditEntry(e, "too many entries waiting to be stored")
+	}
+}
+
+func (w *auditWriter) work(p *Proxy) {
+	defer close(w.done)
+	for e := range w.entries {
+		if err := p.store.Audit.Append(e); err != nil {
+			p.logAuditEntry(e, err.Error())
+		}
+	}
+}
+
+// PruneAudit deletes the oldest entries of the audit log past its maximum
+// size. It is invoked periodically, by a single node in the cluster.
+func (p *Proxy) PruneAudit() {
+	if n, err := p.store.Audit.Prune(); err != nil {
+		p.log.WithError(err).Warnf("failed to prune the audit log")
+	} else if n > 0 {
+		p.log.Debugf("pruned %v entries from the audit log", n)
+	}
+}
+
+// close stores the entries waiting in the queue, and stops the writer.
+func (w *auditWriter) close() {
+	w.mutex.Lock()
+	w.closed = true
+	if w.entries != nil {
+		close(w.entries)
+	}
+	done := w.done
+	w.mutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// logAuditEntry writes an audit entry that could not be stored to the server
+// log, so that it is not lost.
+func (p *Proxy) logAuditEntry(e store.AuditEntry, reason string) {
+	p.conf.MattermostAPI().Log.Warn("Apps audit: failed to store the entry, "+reason,
+		"audit", true,
+		"event", string(e.Event),
+		"app_id", e.AppID,
+		"user_id", e.UserID,
+		"caller_app_id", e.CallerAppID,
+		"path", e.Path,
+		"request_id", e.RequestID,
+		"result", e.Result,
+		"error", e.Error)
+}
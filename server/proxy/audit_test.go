@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testAuditStore struct {
+	entries []store.AuditEntry
+}
+
+func (s *testAuditStore) Append(e store.AuditEntry) error {
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *testAuditStore) List(store.AuditFilter) ([]store.AuditEntry, error) {
+	return s.entries, nil
+}
+
+func (s *testAuditStore) Prune() (int, error) {
+	return 0, nil
+}
+
+func TestAuditAppCall(t *testing.T) {
+	caller := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "tickets",
+		},
+		DeployType:         apps.DeployBuiltin,
+		GrantedPermissions: apps.Permissions{apps.PermissionCallApps},
+	}
+	target := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "oncall",
+			AppCallers: []apps.AppCaller{
+				{AppID: "tickets", Paths: []string{"/page/*"}},
+			},
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+
+	ctrl := gomock.NewController(t)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	for _, app := range []apps.App{caller, target} {
+		app := app
+		appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+	}
+	up := mock_upstream.NewMockUpstream(ctrl)
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, _ apps.App, _ apps.CallRequest, _ bool) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(utils.ToJSON(apps.NewTextResponse("paged")))), nil
+		})
+
+	auditStore := &testAuditStore{}
+	conf := config.NewTestConfigService(&config.Config{
+		AuditLog: config.AuditLogConfig{MaxEntries: 10},
+	})
+	p := &Proxy{
+		conf:             conf,
+		store:            &store.Service{App: appStore, Audit: auditStore},
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{target.AppID: up},
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).
+		WithActingUserID("user-id").
+		WithDestination(target.AppID).
+		WithSourceAppID(caller.AppID)
+
+	for _, path := range []string{"/page/form", "/on_install"} {
+		creq := apps.CallRequest{
+			Call: *apps.NewCall(path),
+		}
+		creq.Context.AppID = target.AppID
+		_ = p.InvokeAppCall(r, creq)
+	}
+	// Wait for the entries to be stored.
+	p.auditLog.close()
+
+	require.Len(t, auditStore.entries, 2)
+	ok, forbidden := auditStore.entries[0], auditStore.entries[1]
+	require.NotZero(t, ok.CreateAt)
+	require.Equal(t, r.RequestID(), ok.RequestID)
+	ok.CreateAt, ok.RequestID = 0, ""
+	require.Equal(t, store.AuditEntry{
+		Event:       store.AuditEventCall,
+		AppID:       "oncall",
+		UserID:      "user-id",
+		CallerAppID: "tickets",
+		Path:        "/page/form",
+		Result:      string(apps.CallResponseTypeOK),
+	}, ok)
+	require.Equal(t, "/on_install", forbidden.Path)
+	require.Equal(t, string(apps.CallResponseTypeError), forbidden.Result)
+	require.Contains(t, forbidden.Error, utils.ErrForbidden.Error())
+}
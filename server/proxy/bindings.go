@@ -2,6 +2,8 @@ package proxy
 
 import (
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/mattermost/mattermost-server/v6/model"
@@ -10,6 +12,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 func mergeBindings(bb1, bb2 []apps.Binding) []apps.Binding {
@@ -51,10 +54,19 @@ func (p *Proxy) GetBindings(r *incoming.Request, cc apps.Context) ([]apps.Bindin
 		err      error
 	}
 
+	start := time.Now()
+	ctx, span := tracing.Start(r.Ctx(), "apps.bindings")
+	defer func() {
+		p.metrics.ObserveBindings(time.Since(start))
+		span.End()
+	}()
+	r = r.WithCtx(ctx)
+
 	all := make(chan result)
 	defer close(all)
 
 	allApps := store.SortApps(p.store.App.AsMap())
+	span.SetAttributes(tracing.Attr("apps", strconv.Itoa(len(allApps))))
 
 	for i := range allApps {
 		go func(app apps.App) {
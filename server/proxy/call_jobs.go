@@ -0,0 +1,191 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// MaxRunningCallJobs limits the number of async calls that run at the same
+// time on each node.
+const MaxRunningCallJobs = 50
+
+// callJobRunner runs the async calls in the background, at most
+// MaxRunningCallJobs at a time. Closing it cancels the running calls, and waits
+// for their results to be stored. The zero value is ready to use.
+type callJobRunner struct {
+	mutex   sync.Mutex
+	running int
+	closed  bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// acquire reserves a slot for a new job. It returns false if the runner is
+// full, or closed. A reserved slot must be either released, or used to run.
+func (j *callJobRunner) acquire() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.closed || j.running >= MaxRunningCallJobs {
+		return false
+	}
+	if j.ctx == nil {
+		j.ctx, j.cancel = context.WithCancel(context.Background())
+	}
+	j.running++
+	j.wg.Add(1)
+	return true
+}
+
+func (j *callJobRunner) release() {
+	j.mutex.Lock()
+	j.running--
+	j.mutex.Unlock()
+	j.wg.Done()
+}
+
+// run runs f in a new goroutine in the acquired slot, with a context that is
+// canceled after timeout or when the runner is closed.
+func (j *callJobRunner) run(timeout time.Duration, f func(context.Context)) {
+	j.mutex.Lock()
+	ctx, cancel := context.WithTimeout(j.ctx, timeout)
+	j.mutex.Unlock()
+	go func() {
+		defer j.release()
+		defer cancel()
+		f(ctx)
+	}()
+}
+
+// close stops accepting new jobs, cancels the running ones, and waits for them
+// to complete.
+func (j *callJobRunner) close() {
+	j.mutex.Lock()
+	j.closed = true
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.mutex.Unlock()
+
+	j.wg.Wait()
+}
+
+// startCallJob creates a CallJob for an async call, and invokes the call in the
+// background. It returns the "job" response right away.
+func (p *Proxy) startCallJob(r *incoming.Request, app *apps.App, creq apps.CallRequest) apps.CallResponse {
+	if !p.callJobs.acquire() {
+		return apps.NewErrorResponse(utils.NewError(utils.ErrTooManyRequests, "too many async calls are running, try again later"))
+	}
+
+	now := time.Now().UnixMilli()
+	job := apps.CallJob{
+		ID:       model.NewId(),
+		AppID:    app.AppID,
+		UserID:   r.ActingUserID(),
+		Path:     creq.Path,
+		Status:   apps.CallJobStatusRunning,
+		CreateAt: now,
+		UpdateAt: now,
+	}
+	if err := p.store.CallJob.Save(job); err != nil {
+		p.callJobs.release()
+		return apps.NewErrorResponse(errors.Wrap(err, "failed to create a call job"))
+	}
+	creq.JobID = job.ID
+
+	// The incoming request's context is canceled as soon as the response is
+	// sent, the job runs with its own.
+	jobRequest := r.Clone()
+	jobRequest.Log = r.Log.With("job_id", job.ID)
+	p.callJobs.run(p.asyncCallTimeout(&creq.Call), func(ctx context.Context) {
+		p.runCallJob(jobRequest.WithCtx(ctx), app, creq)
+	})
+
+	r.Log.Debugf("started call job for %s", creq.Path)
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeJob,
+		Job:  &job,
+	}
+}
+
+func (p *Proxy) runCallJob(r *incoming.Request, app *apps.App, creq apps.CallRequest) {
+	defer func() {
+		if x := recover(); x != nil {
+			r.Log.Errorw("Recovered from a panic in a call job", "error", x)
+			p.finishCallJob(r, creq.JobID, apps.NewErrorResponse(errors.New(fmt.Sprint("panicked: ", x))))
+		}
+	}()
+
+	cresp := p.callApp(r, app, creq)
+	cresp = p.followCallResponses(r, app, creq, cresp)
+	if cresp.Type == apps.CallResponseTypeForm {
+		p.recordUserCallPaths(r, app, nil, cresp.Form)
+		p.recordServedForms(r, app.AppID, nil, cresp.Form)
+	}
+	p.finishCallJob(r, creq.JobID, cresp)
+}
+
+// finishCallJob stores the final response of a job, and notifies the user that
+// started it.
+func (p *Proxy) finishCallJob(r *incoming.Request, id string, cresp apps.CallResponse) {
+	// Update the stored job to preserve the last progress reported by the app.
+	job, err := p.store.CallJob.Update(id, func(job *apps.CallJob) error {
+		if cresp.Type == apps.CallResponseTypeError {
+			job.Status = apps.CallJobStatusFailed
+		} else {
+			job.Status = apps.CallJobStatusComplete
+			job.Progress = 100
+		}
+		job.Response = &cresp
+		job.UpdateAt = time.Now().UnixMilli()
+		return nil
+	})
+	if err != nil {
+		r.Log.WithError(err).Errorf("failed to save the call job result")
+		return
+	}
+
+	p.conf.MattermostAPI().Frontend.PublishWebSocketEvent(
+		config.WebSocketEventCallJobDone,
+		map[string]interface{}{
+			"job_id": job.ID,
+			"app_id": string(job.AppID),
+			"status": string(job.Status),
+		},
+		&model.WebsocketBroadcast{UserId: job.UserID})
+
+	r.Log.Debugf("call job %s: %s", job.Status, cresp)
+}
+
+// GetCallJob returns the status, and the eventual result of an async call.
+// Only the user who started the job can access it.
+func (p *Proxy) GetCallJob(r *incoming.Request, id string) (*apps.CallJob, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+	); err != nil {
+		return nil, err
+	}
+
+	job, err := p.store.CallJob.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != r.ActingUserID() {
+		return nil, utils.NewNotFoundError("call job %s", id)
+	}
+	return job, nil
+}
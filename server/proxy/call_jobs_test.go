@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testCallJobStore struct {
+	mutex sync.Mutex
+	jobs  map[string]apps.CallJob
+	done  chan apps.CallJob
+}
+
+func (s *testCallJobStore) Save(job apps.CallJob) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.jobs[job.ID] = job
+	if job.IsDone() {
+		s.done <- job
+	}
+	return nil
+}
+
+func (s *testCallJobStore) Update(id string, modify func(*apps.CallJob) error) (*apps.CallJob, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, utils.NewNotFoundError(id)
+	}
+	if err := modify(&job); err != nil {
+		return nil, err
+	}
+	s.jobs[id] = job
+	if job.IsDone() {
+		s.done <- job
+	}
+	return &job, nil
+}
+
+func (s *testCallJobStore) Get(id string) (*apps.CallJob, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, utils.NewNotFoundError(id)
+	}
+	return &job, nil
+}
+
+func TestCallJob(t *testing.T) {
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+
+	for name, tc := range map[string]struct {
+		response       apps.CallResponse
+		expectedStatus apps.CallJobStatus
+	}{
+		"complete": {
+			response:       apps.NewTextResponse("exported"),
+			expectedStatus: apps.CallJobStatusComplete,
+		},
+		"failed": {
+			response:       apps.NewErrorResponse(utils.NewInvalidError("export failed")),
+			expectedStatus: apps.CallJobStatusFailed,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			jobs := &testCallJobStore{
+				jobs: map[string]apps.CallJob{},
+				done: make(chan apps.CallJob, 1),
+			}
+			conf, api := config.NewTestService(nil)
+			published := make(chan struct{})
+			api.On("PublishWebSocketEvent", config.WebSocketEventCallJobDone, mock.Anything, &model.WebsocketBroadcast{UserId: "user-id"}).
+				Once().
+				Run(func(mock.Arguments) { close(published) })
+
+			ctrl := gomock.NewController(t)
+			up := mock_upstream.NewMockUpstream(ctrl)
+			var jobID string
+			up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+				DoAndReturn(func(ctx context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+					jobID = creq.JobID
+					// The job runs after the incoming request is done.
+					require.NoError(t, ctx.Err())
+					return io.NopCloser(strings.NewReader(utils.ToJSON(tc.response))), nil
+				})
+
+			p := &Proxy{
+				conf:             conf,
+				store:            &store.Service{CallJob: jobs},
+				builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).
+				WithDestination(app.AppID).
+				WithActingUserID("user-id").
+				WithCtx(ctx)
+
+			creq := apps.CallRequest{Call: *apps.NewCall("/export")}
+			creq.Async = true
+			cresp := p.startCallJob(r, app, creq)
+			cancel()
+			require.Equal(t, apps.CallResponseTypeJob, cresp.Type)
+			require.NotNil(t, cresp.Job)
+			require.Equal(t, apps.CallJobStatusRunning, cresp.Job.Status)
+			require.Equal(t, "user-id", cresp.Job.UserID)
+			require.Equal(t, "/export", cresp.Job.Path)
+
+			job := <-jobs.done
+			<-published
+			require.Equal(t, cresp.Job.ID, jobID)
+			require.Equal(t, tc.expectedStatus, job.Status)
+			require.Equal(t, tc.response.Type, job.Response.Type)
+			require.Equal(t, tc.response.Text, job.Response.Text)
+
+			got, err := p.GetCallJob(r, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, job, *got)
+
+			_, err = p.GetCallJob(r.WithActingUserID("other-user-id"), job.ID)
+			require.ErrorIs(t, err, utils.ErrNotFound)
+		})
+	}
+}
+
+func TestCallJobRunner(t *testing.T) {
+	j := callJobRunner{}
+	for i := 0; i < MaxRunningCallJobs; i++ {
+		require.True(t, j.acquire())
+	}
+	require.False(t, j.acquire())
+	j.release()
+	require.True(t, j.acquire())
+
+	// Closing cancels the running jobs, and waits for them.
+	started := make(chan struct{})
+	canceled := false
+	j.run(time.Hour, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		canceled = true
+	})
+	<-started
+	for i := 0; i < MaxRunningCallJobs-1; i++ {
+		j.release()
+	}
+	j.close()
+	require.True(t, canceled)
+	require.False(t, j.acquire())
+}
+
+func TestAsyncCallPaths(t *testing.T) {
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID:          "test",
+			AsyncCallPaths: []string{"/export"},
+		},
+	}
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{conf: conf}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithActingUserID("user-id")
+
+	creq := apps.CallRequest{Call: apps.Call{Path: "/other", Async: true}}
+	cresp := p.invokeCall(r, app, creq)
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+	require.Contains(t, cresp.Text, "may not be called asynchronously")
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// MaxRecordedCallsPerApp limits the number of the recent calls recorded for
+// each app in developer mode; the oldest are dropped first.
+const MaxRecordedCallsPerApp = 50
+
+// RecordedCall is a call to an app, as recorded in developer mode on this node.
+type RecordedCall struct {
+	ID           string     `json:"id"`
+	AppID        apps.AppID `json:"app_id"`
+	ActingUserID string     `json:"acting_user_id,omitempty"`
+	CreateAt     int64      `json:"create_at"`
+	ElapsedMs    int64      `json:"elapsed_ms"`
+
+	// Context is the context of the call before it was expanded, used to
+	// replay the call.
+	Context apps.Context `json:"context"`
+
+	// Request is the call request as sent to the app, with the expanded
+	// context. The secrets are redacted.
+	Request  apps.CallRequest  `json:"request"`
+	Response apps.CallResponse `json:"response"`
+}
+
+// CallReplay is the result of replaying a recorded call.
+type CallReplay struct {
+	Recorded RecordedCall      `json:"recorded"`
+	Response apps.CallResponse `json:"response"`
+
+	// Diff is the unified diff between the recorded and the new response,
+	// formatted as JSON. It is empty if the responses are the same.
+	Diff string `json:"diff,omitempty"`
+}
+
+// callRecorder keeps the recent calls to each app, in memory.
+type callRecorder struct {
+	mutex sync.Mutex
+	calls map[apps.AppID][]RecordedCall
+}
+
+func (rec *callRecorder) add(c RecordedCall) {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	if rec.calls == nil {
+		rec.calls = map[apps.AppID][]RecordedCall{}
+	}
+	calls := append(rec.calls[c.AppID], c)
+	if len(calls) > MaxRecordedCallsPerApp {
+		calls = calls[len(calls)-MaxRecordedCallsPerApp:]
+	}
+	rec.calls[c.AppID] = calls
+}
+
+// list returns the app's recorded calls, the newest first.
+func (rec *callRecorder) list(appID apps.AppID) []RecordedCall {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	calls := rec.calls[appID]
+	out := make([]RecordedCall, 0, len(calls))
+	for i := len(calls) - 1; i >= 0; i-- {
+		out = append(out, calls[i])
+	}
+	return out
+}
+
+func (rec *callRecorder) get(appID apps.AppID, id string) (RecordedCall, bool) {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	for _, c := range rec.calls[appID] {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return RecordedCall{}, false
+}
+
+// recordCall records a call in developer mode. unexpanded is the context of
+// the call before expansion, and creq the request as sent to the app.
+func (p *Proxy) recordCall(r *incoming.Request, app *apps.App, unexpanded apps.Context, creq apps.CallRequest, cresp apps.CallResponse, start time.Time) {
+	if !p.conf.Get().DeveloperMode {
+		return
+	}
+	creq.Context = creq.Context.Redacted()
+	p.recordedCalls.add(RecordedCall{
+		ID:           model.NewId(),
+		AppID:        app.AppID,
+		ActingUserID: r.ActingUserID(),
+		CreateAt:     start.UnixMilli(),
+		ElapsedMs:    time.Since(start).Milliseconds(),
+		Context:      unexpanded.Redacted(),
+		Request:      creq,
+		Response:     cresp,
+	})
+}
+
+// ListRecordedCalls returns the app's calls recorded on this node in developer
+// mode, the newest first.
+func (p *Proxy) ListRecordedCalls(r *incoming.Request, appID apps.AppID) ([]RecordedCall, error) {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	return p.recordedCalls.list(appID), nil
+}
+
+// ReplayRecordedCall invokes a recorded call again, against the current version
+// of the app, on behalf of the same acting user. The context is expanded anew.
+func (p *Proxy) ReplayRecordedCall(r *incoming.Request, appID apps.AppID, id string) (*CallReplay, error) {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	if !p.conf.Get().DeveloperMode {
+		return nil, utils.NewForbiddenError("replaying calls is only available in developer mode")
+	}
+
+	recorded, ok := p.recordedCalls.get(appID, id)
+	if !ok {
+		return nil, utils.NewNotFoundError("recorded call %s for %s", id, appID)
+	}
+	app, err := p.GetInstalledApp(appID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	creq := apps.CallRequest{
+		Call:          recorded.Request.Call,
+		Context:       recorded.Context,
+		Values:        recorded.Request.Values,
+		RawCommand:    recorded.Request.RawCommand,
+		SelectedField: recorded.Request.SelectedField,
+		Query:         recorded.Request.Query,
+	}
+	// The redacted secrets of the unexpanded context can not be used, the
+	// context is expanded anew.
+	creq.Context.ExpandedContext = apps.ExpandedContext{}
+
+	appRequest := r.WithActingUserID(recorded.ActingUserID).WithDestination(appID)
+	cresp := p.callApp(appRequest, app, creq)
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(utils.Pretty(recorded.Response) + "\n"),
+		B:        difflib.SplitLines(utils.Pretty(cresp) + "\n"),
+		FromFile: "recorded",
+		ToFile:   "replayed",
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CallReplay{
+		Recorded: recorded,
+		Response: cresp,
+		Diff:     diff,
+	}, nil
+}
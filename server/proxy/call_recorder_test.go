@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestRecordAndReplayCall(t *testing.T) {
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "app1",
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+
+	ctrl := gomock.NewController(t)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+
+	up := mock_upstream.NewMockUpstream(ctrl)
+	received := []apps.CallRequest{}
+	for _, text := range []string{"first", "second"} {
+		text := text
+		up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+			DoAndReturn(func(_ context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+				received = append(received, creq)
+				return io.NopCloser(strings.NewReader(utils.ToJSON(apps.NewTextResponse(text)))), nil
+			})
+	}
+
+	conf, api := config.NewTestService(&config.Config{DeveloperMode: true})
+	api.On("HasPermissionTo", "admin", model.PermissionManageSystem).Return(true)
+	p := &Proxy{
+		conf:             conf,
+		store:            &store.Service{App: appStore},
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).
+		WithActingUserID("user-id").
+		WithDestination(app.AppID)
+
+	cc := apps.Context{
+		UserAgentContext: apps.UserAgentContext{
+			AppID:     app.AppID,
+			ChannelID: "channel-id",
+		},
+	}
+	cc.OAuth2.User = map[string]interface{}{"token": "secret"}
+	cresp := p.callApp(r, &app, apps.CallRequest{
+		Call:    *apps.NewCall("/form/submit"),
+		Context: cc,
+		Values:  map[string]interface{}{"name": "value"},
+	})
+	require.Equal(t, "first", cresp.Text)
+
+	admin := r.WithActingUserID("admin")
+	calls, err := p.ListRecordedCalls(admin, app.AppID)
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	recorded := calls[0]
+	require.NotEmpty(t, recorded.ID)
+	require.Equal(t, "user-id", recorded.ActingUserID)
+	require.Equal(t, "/form/submit", recorded.Request.Path)
+	require.Equal(t, "channel-id", recorded.Context.ChannelID)
+	require.Equal(t, "(private)", recorded.Context.OAuth2.User)
+	require.Equal(t, "first", recorded.Response.Text)
+
+	replay, err := p.ReplayRecordedCall(admin, app.AppID, recorded.ID)
+	require.NoError(t, err)
+	require.Equal(t, "second", replay.Response.Text)
+	require.Contains(t, replay.Diff, `-  "text": "first"`)
+	require.Contains(t, replay.Diff, `+  "text": "second"`)
+
+	// The call is replayed with the same values, and the context is expanded
+	// anew.
+	require.Len(t, received, 2)
+	require.Equal(t, received[0].Path, received[1].Path)
+	require.Equal(t, received[0].Values, received[1].Values)
+	require.Nil(t, received[1].Context.OAuth2.User)
+
+	_, err = p.ReplayRecordedCall(admin, app.AppID, "unknown")
+	require.ErrorIs(t, err, utils.ErrNotFound)
+}
+
+func TestCallRecorderLimit(t *testing.T) {
+	rec := callRecorder{}
+	for i := 0; i < MaxRecordedCallsPerApp+5; i++ {
+		rec.add(RecordedCall{AppID: "app1", CreateAt: int64(i)})
+	}
+	rec.add(RecordedCall{AppID: "app2"})
+
+	calls := rec.list("app1")
+	require.Len(t, calls, MaxRecordedCallsPerApp)
+	require.Equal(t, int64(MaxRecordedCallsPerApp+4), calls[0].CreateAt)
+	require.Equal(t, int64(5), calls[len(calls)-1].CreateAt)
+	require.Len(t, rec.list("app2"), 1)
+	require.Empty(t, rec.list("app3"))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+// callTimeout returns the time budget for a call to the app: the call's own
+// Timeout, or the app's CallTimeout, or def if neither is declared. It is
+// capped by the maximum configured by the administrator.
+func (p *Proxy) callTimeout(app *apps.App, call *apps.Call, def time.Duration) time.Duration {
+	timeout := def
+	switch {
+	case call != nil && call.Timeout > 0:
+		timeout = time.Duration(call.Timeout) * time.Millisecond
+	case app.CallTimeout > 0:
+		timeout = time.Duration(app.CallTimeout) * time.Millisecond
+	}
+
+	if max := p.conf.Get().MaxCallTimeout; max > 0 && timeout > max {
+		timeout = max
+	}
+	return timeout
+}
+
+// asyncCallTimeout returns the time budget for an async call: the call's own
+// Timeout if declared, capped by the maximum async call timeout configured by
+// the administrator. The app's CallTimeout does not apply.
+func (p *Proxy) asyncCallTimeout(call *apps.Call) time.Duration {
+	max := p.conf.Get().MaxAsyncCallTimeout
+	if max <= 0 {
+		max = config.DefaultMaxAsyncCallTimeout
+	}
+	if call != nil && call.Timeout > 0 {
+		if timeout := time.Duration(call.Timeout) * time.Millisecond; timeout < max {
+			return timeout
+		}
+	}
+	return max
+}
@@ -0,0 +1,57 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+func TestCallTimeout(t *testing.T) {
+	p := &Proxy{
+		conf: config.NewTestConfigService(&config.Config{
+			MaxCallTimeout: 2 * time.Minute,
+		}),
+	}
+	withAppTimeout := &apps.App{Manifest: apps.Manifest{CallTimeout: 60000}}
+	noAppTimeout := &apps.App{}
+
+	for name, tc := range map[string]struct {
+		app      *apps.App
+		call     *apps.Call
+		expected time.Duration
+	}{
+		"default":             {noAppTimeout, apps.NewCall("/test"), config.DefaultCallTimeout},
+		"default ping":        {noAppTimeout, nil, config.DefaultPingTimeout},
+		"app":                 {withAppTimeout, apps.NewCall("/test"), time.Minute},
+		"app ping":            {withAppTimeout, nil, time.Minute},
+		"call":                {noAppTimeout, &apps.Call{Path: "/test", Timeout: 500}, 500 * time.Millisecond},
+		"call overrides app":  {withAppTimeout, &apps.Call{Path: "/test", Timeout: 500}, 500 * time.Millisecond},
+		"capped call":         {noAppTimeout, &apps.Call{Path: "/test", Timeout: 600000}, 2 * time.Minute},
+		"capped app":          {&apps.App{Manifest: apps.Manifest{CallTimeout: 600000}}, nil, 2 * time.Minute},
+		"negative is ignored": {noAppTimeout, &apps.Call{Path: "/test", Timeout: -1}, config.DefaultCallTimeout},
+	} {
+		t.Run(name, func(t *testing.T) {
+			def := config.DefaultCallTimeout
+			if tc.call == nil {
+				def = config.DefaultPingTimeout
+			}
+			require.Equal(t, tc.expected, p.callTimeout(tc.app, tc.call, def))
+		})
+	}
+}
+
+func TestAsyncCallTimeout(t *testing.T) {
+	p := &Proxy{
+		conf: config.NewTestConfigService(&config.Config{
+			MaxCallTimeout:      2 * time.Minute,
+			MaxAsyncCallTimeout: 10 * time.Minute,
+		}),
+	}
+	require.Equal(t, 10*time.Minute, p.asyncCallTimeout(apps.NewCall("/test")))
+	require.Equal(t, 5*time.Minute, p.asyncCallTimeout(&apps.Call{Path: "/test", Timeout: 300000}))
+	require.Equal(t, 10*time.Minute, p.asyncCallTimeout(&apps.Call{Path: "/test", Timeout: 3600000}))
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// CircuitState is the state of an app's circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets all calls through, up to the bulkhead size.
+	CircuitClosed CircuitState = "closed"
+
+	// CircuitOpen rejects all calls, the app is failing.
+	CircuitOpen CircuitState = "open"
+
+	// CircuitHalfOpen lets a single probe call through, its outcome closes or
+	// re-opens the breaker.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+const (
+	// CircuitFailureThreshold is the number of consecutive failed calls that
+	// opens an app's circuit breaker.
+	CircuitFailureThreshold = 5
+
+	// CircuitOpenDuration is how long an open breaker rejects the calls before
+	// letting a probe call through.
+	CircuitOpenDuration = 30 * time.Second
+
+	// BulkheadSize is the maximum number of concurrent calls to an app. The
+	// calls in excess are rejected rather than queued, so that a slow app does
+	// not tie up the callers.
+	BulkheadSize = 20
+)
+
+var (
+	ErrCircuitOpen  = errors.New("circuit breaker is open")
+	ErrBulkheadFull = errors.New("too many concurrent calls")
+)
+
+// appBreaker is a circuit breaker and a concurrency bulkhead for the calls to
+// a single app.
+type appBreaker struct {
+	log   utils.Logger
+	now   func() time.Time
+	slots chan struct{}
+
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newAppBreaker(log utils.Logger) *appBreaker {
+	return &appBreaker{
+		log:   log,
+		now:   time.Now,
+		slots: make(chan struct{}, BulkheadSize),
+		state: CircuitClosed,
+	}
+}
+
+// acquire admits a call, or returns an error if the breaker is open or the
+// bulkhead is full. If admitted, done must be called with the call's outcome.
+func (b *appBreaker) acquire() (done func(error), err error) {
+	b.mutex.Lock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= CircuitOpenDuration {
+		b.state = CircuitHalfOpen
+		b.probing = false
+	}
+	probe := false
+	switch b.state {
+	case CircuitOpen:
+		b.mutex.Unlock()
+		return nil, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			b.mutex.Unlock()
+			return nil, ErrCircuitOpen
+		}
+		b.probing = true
+		probe = true
+	}
+	b.mutex.Unlock()
+
+	select {
+	case b.slots <- struct{}{}:
+	default:
+		if probe {
+			b.mutex.Lock()
+			b.probing = false
+			b.mutex.Unlock()
+		}
+		return nil, ErrBulkheadFull
+	}
+
+	return func(err error) {
+		<-b.slots
+		b.record(err)
+	}, nil
+}
+
+func (b *appBreaker) record(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !isCircuitFailure(err) {
+		switch b.state {
+		case CircuitOpen:
+			// A call admitted before the breaker opened, wait for the probe.
+		case CircuitHalfOpen:
+			b.log.Infof("circuit breaker closed, the app is responding again")
+			fallthrough
+		default:
+			b.state = CircuitClosed
+			b.failures = 0
+			b.probing = false
+		}
+		return
+	}
+
+	b.failures++
+	switch {
+	case b.state == CircuitHalfOpen:
+		b.open(err)
+	case b.state == CircuitClosed && b.failures >= CircuitFailureThreshold:
+		b.open(err)
+	}
+}
+
+func (b *appBreaker) open(err error) {
+	b.state = CircuitOpen
+	b.openedAt = b.now()
+	b.probing = false
+	b.log.WithError(err).Warnw("circuit breaker opened, calls to the app are rejected",
+		"consecutive_failures", b.failures,
+		"retry_in", CircuitOpenDuration.String())
+}
+
+func (b *appBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= CircuitOpenDuration {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// isCircuitFailure determines if a failed call indicates a problem with the
+// app. A "not found" response comes from a healthy app, and a canceled call
+// was abandoned by the caller.
+func isCircuitFailure(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, utils.ErrNotFound),
+		errors.Is(err, context.Canceled):
+		return false
+	default:
+		return true
+	}
+}
+
+// guardedUpstream wraps an app's upstream with its circuit breaker.
+type guardedUpstream struct {
+	upstream.Upstream
+	breaker *appBreaker
+}
+
+var _ upstream.Upstream = guardedUpstream{}
+
+func (u guardedUpstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	done, err := u.breaker.acquire()
+	if err != nil {
+		return nil, errors.Wrap(err, string(app.AppID))
+	}
+	r, err := u.Upstream.Roundtrip(ctx, app, creq, async)
+	done(err)
+	return r, err
+}
+
+func (u guardedUpstream) GetStatic(ctx context.Context, app apps.App, path string) (io.ReadCloser, int, error) {
+	done, err := u.breaker.acquire()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, string(app.AppID))
+	}
+	r, status, err := u.Upstream.GetStatic(ctx, app, path)
+	done(err)
+	return r, status, err
+}
+
+func (p *Proxy) appBreaker(appID apps.AppID) *appBreaker {
+	if b, ok := p.breakers.Load(appID); ok {
+		return b.(*appBreaker)
+	}
+	b, _ := p.breakers.LoadOrStore(appID, newAppBreaker(p.log.With("app_id", appID)))
+	return b.(*appBreaker)
+}
+
+// CircuitBreakerState returns the state of the app's circuit breaker.
+func (p *Proxy) CircuitBreakerState(appID apps.AppID) CircuitState {
+	b, ok := p.breakers.Load(appID)
+	if !ok {
+		return CircuitClosed
+	}
+	return b.(*appBreaker).State()
+}
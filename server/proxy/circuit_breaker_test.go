@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func newTestAppBreaker() (*appBreaker, *time.Time) {
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	b := newAppBreaker(utils.NewTestLogger())
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func failCalls(t *testing.T, b *appBreaker, n int, err error) {
+	for i := 0; i < n; i++ {
+		done, acquireErr := b.acquire()
+		require.NoError(t, acquireErr)
+		done(err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	appErr := errors.New("internal server error")
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		b, _ := newTestAppBreaker()
+		failCalls(t, b, CircuitFailureThreshold-1, appErr)
+		require.Equal(t, CircuitClosed, b.State())
+
+		failCalls(t, b, 1, appErr)
+		require.Equal(t, CircuitOpen, b.State())
+		_, err := b.acquire()
+		require.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("success resets the failure count", func(t *testing.T) {
+		b, _ := newTestAppBreaker()
+		failCalls(t, b, CircuitFailureThreshold-1, appErr)
+		failCalls(t, b, 1, nil)
+		failCalls(t, b, CircuitFailureThreshold-1, appErr)
+		require.Equal(t, CircuitClosed, b.State())
+	})
+
+	t.Run("not found and canceled are not failures", func(t *testing.T) {
+		b, _ := newTestAppBreaker()
+		failCalls(t, b, CircuitFailureThreshold, utils.NewNotFoundError("/missing"))
+		failCalls(t, b, CircuitFailureThreshold, errors.Wrap(context.Canceled, "call"))
+		require.Equal(t, CircuitClosed, b.State())
+
+		failCalls(t, b, CircuitFailureThreshold, errors.Wrap(context.DeadlineExceeded, "call"))
+		require.Equal(t, CircuitOpen, b.State())
+	})
+
+	t.Run("half-open probe closes", func(t *testing.T) {
+		b, now := newTestAppBreaker()
+		failCalls(t, b, CircuitFailureThreshold, appErr)
+		*now = now.Add(CircuitOpenDuration)
+		require.Equal(t, CircuitHalfOpen, b.State())
+
+		probeDone, err := b.acquire()
+		require.NoError(t, err)
+		_, err = b.acquire()
+		require.ErrorIs(t, err, ErrCircuitOpen, "only one probe at a time")
+
+		probeDone(nil)
+		require.Equal(t, CircuitClosed, b.State())
+		failCalls(t, b, 1, nil)
+	})
+
+	t.Run("half-open probe re-opens", func(t *testing.T) {
+		b, now := newTestAppBreaker()
+		failCalls(t, b, CircuitFailureThreshold, appErr)
+		*now = now.Add(CircuitOpenDuration)
+
+		failCalls(t, b, 1, appErr)
+		require.Equal(t, CircuitOpen, b.State())
+		_, err := b.acquire()
+		require.ErrorIs(t, err, ErrCircuitOpen)
+
+		*now = now.Add(CircuitOpenDuration)
+		require.Equal(t, CircuitHalfOpen, b.State())
+	})
+
+	t.Run("bulkhead", func(t *testing.T) {
+		b, _ := newTestAppBreaker()
+		var dones []func(error)
+		for i := 0; i < BulkheadSize; i++ {
+			done, err := b.acquire()
+			require.NoError(t, err)
+			dones = append(dones, done)
+		}
+		_, err := b.acquire()
+		require.ErrorIs(t, err, ErrBulkheadFull)
+
+		dones[0](nil)
+		done, err := b.acquire()
+		require.NoError(t, err)
+		done(nil)
+		require.Equal(t, CircuitClosed, b.State())
+	})
+}
+
+func TestGuardedUpstream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	up := mock_upstream.NewMockUpstream(ctrl)
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		Times(CircuitFailureThreshold).
+		Return(nil, errors.New("bad gateway"))
+
+	p := &Proxy{log: utils.NewTestLogger()}
+	app := apps.App{Manifest: apps.Manifest{AppID: "test"}}
+	guarded := guardedUpstream{
+		Upstream: up,
+		breaker:  p.appBreaker(app.AppID),
+	}
+
+	for i := 0; i < CircuitFailureThreshold; i++ {
+		_, err := guarded.Roundtrip(context.Background(), app, apps.CallRequest{}, false)
+		require.EqualError(t, err, "bad gateway")
+	}
+	require.Equal(t, CircuitOpen, p.CircuitBreakerState(app.AppID))
+	require.Equal(t, CircuitClosed, p.CircuitBreakerState("other"))
+
+	// The upstream is no longer called.
+	_, err := guarded.Roundtrip(context.Background(), app, apps.CallRequest{}, false)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.EqualError(t, err, "test: circuit breaker is open")
+}
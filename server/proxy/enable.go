@@ -10,10 +10,15 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
-func (p *Proxy) EnableApp(r *incoming.Request, cc apps.Context, appID apps.AppID) (string, error) {
+func (p *Proxy) EnableApp(r *incoming.Request, cc apps.Context, appID apps.AppID) (_ string, err error) {
+	defer func() {
+		p.auditAction(r, store.AuditEventEnable, appID, err)
+	}()
+
 	if err := r.Check(
 		r.RequireSysadminOrPlugin,
 	); err != nil {
@@ -60,7 +65,11 @@ func (p *Proxy) EnableApp(r *incoming.Request, cc apps.Context, appID apps.AppID
 	return message, nil
 }
 
-func (p *Proxy) DisableApp(r *incoming.Request, cc apps.Context, appID apps.AppID) (string, error) {
+func (p *Proxy) DisableApp(r *incoming.Request, cc apps.Context, appID apps.AppID) (_ string, err error) {
+	defer func() {
+		p.auditAction(r, store.AuditEventDisable, appID, err)
+	}()
+
 	if err := r.Check(
 		r.RequireSysadminOrPlugin,
 	); err != nil {
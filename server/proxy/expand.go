@@ -3,6 +3,7 @@ package proxy
 import (
 	"encoding/json"
 	"path"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/mmclient"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type expandFunc func(apps.ExpandLevel) error
@@ -52,6 +54,14 @@ func (p *Proxy) expandContext(r *incoming.Request, app *apps.App, cc *apps.Conte
 		cc = &apps.Context{}
 	}
 
+	ctx, span := tracing.Start(r.Ctx(), "apps.expand",
+		tracing.Attr("app_id", string(app.AppID)))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	r = r.WithCtx(ctx)
+
 	e := &expander{
 		conf:    conf,
 		app:     app,
@@ -65,6 +75,16 @@ func (p *Proxy) expandContext(r *incoming.Request, app *apps.App, cc *apps.Conte
 		BotUserID:         app.BotUserID,
 		DeveloperMode:     conf.DeveloperMode,
 		MattermostSiteURL: conf.MattermostSiteURL,
+		RequestID:         r.RequestID(),
+	}
+	if deadline, ok := r.Ctx().Deadline(); ok {
+		e.ExpandedContext.Deadline = deadline.UnixMilli()
+	}
+
+	// The source app is only set in the requests of the other apps, see
+	// InvokeAppCall.
+	if caller := r.SourceAppID(); caller != "" && caller != app.AppID {
+		e.ExpandedContext.CallerAppID = caller
 	}
 
 	if app.GrantedPermissions.Contains(apps.PermissionActAsBot) && app.BotUserID != "" {
@@ -192,7 +212,10 @@ func (e *expander) expand(expand *apps.Expand) (*apps.Context, error) {
 
 		// Execute the expand function, skip the error unless the field is
 		// required.
-		if err := step.f(level); err != nil {
+		start := time.Now()
+		err = step.f(level)
+		e.proxy.metrics.ObserveExpand(step.name, time.Since(start), err)
+		if err != nil {
 			if e.conf.DeveloperMode {
 				e.r.Log.WithError(err).Debugf("failed to expand field %s", step.name)
 			}
@@ -1,9 +1,11 @@
 package proxy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -290,6 +292,8 @@ func TestExpand(t *testing.T) {
 							if err != nil {
 								require.EqualValues(t, expected, err.Error())
 							} else {
+								require.Equal(t, r.RequestID(), cc.ExpandedContext.RequestID)
+								cc.ExpandedContext.RequestID = ""
 								require.EqualValues(t, expected, cc.ExpandedContext)
 							}
 							require.EqualValues(t, prev, tc.base)
@@ -300,3 +304,27 @@ func TestExpand(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandRequestIDAndDeadline(t *testing.T) {
+	app := &apps.App{
+		DeployType: apps.DeployBuiltin,
+		Manifest: apps.Manifest{
+			AppID: apps.AppID("app1"),
+		},
+	}
+	conf := config.NewTestConfigService(&config.Config{})
+	p := &Proxy{conf: conf}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithDestination(app.AppID)
+
+	cc, err := p.expandContext(r, app, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, r.RequestID(), cc.ExpandedContext.RequestID)
+	require.Zero(t, cc.ExpandedContext.Deadline)
+
+	deadline := time.Now().Add(30 * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	cc, err = p.expandContext(r.WithCtx(ctx), app, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, deadline.UnixMilli(), cc.ExpandedContext.Deadline)
+}
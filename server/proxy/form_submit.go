@@ -0,0 +1,252 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+// servedFormKey returns the key of the forms served to the acting user, scoped
+// to the app and the forms' submit path.
+func servedFormKey(r *incoming.Request, appID apps.AppID, submitPath string) string {
+	hash := sha256.Sum256([]byte(string(appID) + "\n" + r.ActingUserID() + "\n" + submitPath))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// recordServedForms records the forms served to the acting user, in a call
+// response or embedded in the bindings, to validate their submissions, and
+// assigns their IDs. The forms in the bindings that are fetched from a source
+// are recorded when they are fetched.
+func (p *Proxy) recordServedForms(r *incoming.Request, appID apps.AppID, bindings []apps.Binding, forms ...*apps.Form) {
+	if r.ActingUserID() == "" {
+		return
+	}
+	for _, form := range forms {
+		p.recordServedForm(r, appID, form)
+	}
+	for i := range bindings {
+		if form := bindings[i].Form; form != nil && form.Source == nil {
+			p.recordServedForm(r, appID, form)
+		}
+		p.recordServedForms(r, appID, bindings[i].Bindings)
+	}
+}
+
+func (p *Proxy) recordServedForm(r *incoming.Request, appID apps.AppID, form *apps.Form) {
+	if form == nil || form.Submit == nil {
+		return
+	}
+	submitPath, err := cleanCallPath(form.Submit.Path)
+	if err != nil {
+		return
+	}
+	if err = p.store.ServedForm.Add(servedFormKey(r, appID, submitPath), form); err != nil {
+		r.Log.WithError(err).Warnf("failed to record the served form")
+	}
+}
+
+// checkFormSubmission validates the submitted values against the forms served
+// to the acting user with the call's path as the submit path. If the request
+// has a FormID, the values are validated against that form, and the
+// submission is rejected if the form has expired, or was not served for the
+// path. Otherwise, the values must be valid for one of the served forms. It
+// returns an error response if the submission is rejected, or nil. The lookup
+// and refresh calls, made with a selected field, are not validated, nor are
+// the calls to paths no form was served for.
+func (p *Proxy) checkFormSubmission(r *incoming.Request, appID apps.AppID, creq apps.CallRequest) *apps.CallResponse {
+	if creq.SelectedField != "" || creq.Query != "" {
+		return nil
+	}
+	forms, err := p.store.ServedForm.Get(servedFormKey(r, appID, creq.Path))
+	if err != nil {
+		r.Log.WithError(err).Warnf("failed to load the served forms, the submission is not validated")
+		return nil
+	}
+
+	if creq.FormID != "" {
+		var served *apps.Form
+		for i := range forms {
+			if forms[i].ID == creq.FormID {
+				served = &forms[i]
+				break
+			}
+		}
+		if served == nil {
+			r.Log.Debugf("rejected submission of form %s to %s, it has expired or was not served for the path", creq.FormID, creq.Path)
+			return &apps.CallResponse{
+				Type: apps.CallResponseTypeError,
+				Text: "The form has expired, please open it again.",
+			}
+		}
+		forms = []apps.Form{*served}
+	}
+	if len(forms) == 0 {
+		return nil
+	}
+
+	var fieldErrors map[string]string
+	for i := len(forms) - 1; i >= 0; i-- {
+		fieldErrors = validateFormValues(forms[i], creq.Values)
+		if len(fieldErrors) == 0 {
+			return nil
+		}
+	}
+	// The errors are reported for the most recently served form.
+	r.Log.Debugf("rejected invalid submission of form %s, %v field errors", creq.Path, len(fieldErrors))
+	return &apps.CallResponse{
+		Type: apps.CallResponseTypeError,
+		Text: "Invalid form submission, please correct the errors.",
+		Data: map[string]map[string]string{
+			"errors": fieldErrors,
+		},
+	}
+}
+
+// validateFormValues checks the submitted values against the form's fields,
+// and returns the errors by field name.
+func validateFormValues(form apps.Form, values map[string]interface{}) map[string]string {
+	errs := map[string]string{}
+	for _, f := range form.Fields {
+		if f.ReadOnly || f.Type == apps.FieldTypeMarkdown {
+			continue
+		}
+		if msg := validateFieldValue(f, values[f.Name]); msg != "" {
+			errs[f.Name] = msg
+		}
+	}
+	return errs
+}
+
+func validateFieldValue(f apps.Field, v interface{}) string {
+	if isEmptyValue(v) {
+		if f.IsRequired {
+			return "This field is required."
+		}
+		return ""
+	}
+
+	switch f.Type {
+	case apps.FieldTypeText, "":
+		return validateTextValue(f, v)
+	case apps.FieldTypeBool:
+		switch b := v.(type) {
+		case bool:
+			return ""
+		case string:
+			if _, err := strconv.ParseBool(b); err == nil {
+				return ""
+			}
+		}
+		return "Must be true or false."
+	case apps.FieldTypeStaticSelect, apps.FieldTypeDynamicSelect, apps.FieldTypeUser, apps.FieldTypeChannel:
+		return validateSelectValue(f, v)
+	}
+	return ""
+}
+
+func validateTextValue(f apps.Field, v interface{}) string {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case float64:
+		if f.TextSubtype != apps.TextFieldSubtypeNumber {
+			return "Must be text."
+		}
+		s = strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return "Must be text."
+	}
+
+	n := utf8.RuneCountInString(s)
+	if f.TextMinLength > 0 && n < f.TextMinLength {
+		return fmt.Sprintf("Must be at least %d characters long.", f.TextMinLength)
+	}
+	if f.TextMaxLength > 0 && n > f.TextMaxLength {
+		return fmt.Sprintf("Must be at most %d characters long.", f.TextMaxLength)
+	}
+
+	switch f.TextSubtype {
+	case apps.TextFieldSubtypeNumber:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return "Must be a number."
+		}
+	case apps.TextFieldSubtypeEmail:
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "Must be a valid email address."
+		}
+	case apps.TextFieldSubtypeURL:
+		if u, err := url.ParseRequestURI(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return "Must be a valid URL."
+		}
+	}
+	return ""
+}
+
+func validateSelectValue(f apps.Field, v interface{}) string {
+	selected := []interface{}{v}
+	if list, ok := v.([]interface{}); ok {
+		if !f.SelectIsMulti && len(list) > 1 {
+			return "Only one option may be selected."
+		}
+		selected = list
+	}
+
+	for _, opt := range selected {
+		value, ok := selectOptionValue(opt)
+		if !ok {
+			return "Must be an option."
+		}
+		if f.Type == apps.FieldTypeStaticSelect && !hasStaticOption(f, value) {
+			return fmt.Sprintf("%q is not one of the options.", value)
+		}
+	}
+	return ""
+}
+
+// selectOptionValue returns the value of a submitted option, either a
+// SelectOption, or a plain string.
+func selectOptionValue(opt interface{}) (string, bool) {
+	switch o := opt.(type) {
+	case string:
+		return o, true
+	case map[string]interface{}:
+		value, ok := o["value"].(string)
+		return value, ok
+	}
+	return "", false
+}
+
+func hasStaticOption(f apps.Field, value string) bool {
+	for _, o := range f.SelectStaticOptions {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		value, ok := t["value"]
+		return !ok || value == nil || value == ""
+	}
+	return false
+}
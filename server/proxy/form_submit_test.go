@@ -0,0 +1,255 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testServedFormStore struct {
+	forms map[string][]apps.Form
+}
+
+func (s *testServedFormStore) Add(key string, form *apps.Form) error {
+	for _, served := range s.forms[key] {
+		form.ID = served.ID
+		if reflect.DeepEqual(served, *form) {
+			return nil
+		}
+	}
+	form.ID = model.NewId()
+	s.forms[key] = append([]apps.Form{*form}, s.forms[key]...)
+	return nil
+}
+
+func (s *testServedFormStore) Get(key string) ([]apps.Form, error) {
+	return s.forms[key], nil
+}
+
+func option(value string) map[string]interface{} {
+	return map[string]interface{}{"label": value, "value": value}
+}
+
+func TestValidateFormValues(t *testing.T) {
+	form := apps.Form{
+		Submit: apps.NewCall("/submit"),
+		Fields: []apps.Field{
+			{Name: "name", Type: apps.FieldTypeText, IsRequired: true, TextMinLength: 2, TextMaxLength: 5},
+			{Name: "count", Type: apps.FieldTypeText, TextSubtype: apps.TextFieldSubtypeNumber},
+			{Name: "email", Type: apps.FieldTypeText, TextSubtype: apps.TextFieldSubtypeEmail},
+			{Name: "url", Type: apps.FieldTypeText, TextSubtype: apps.TextFieldSubtypeURL},
+			{Name: "flag", Type: apps.FieldTypeBool},
+			{Name: "color", Type: apps.FieldTypeStaticSelect, SelectStaticOptions: []apps.SelectOption{{Value: "red"}, {Value: "blue"}}},
+			{Name: "colors", Type: apps.FieldTypeStaticSelect, SelectIsMulti: true, SelectStaticOptions: []apps.SelectOption{{Value: "red"}, {Value: "blue"}}},
+			{Name: "user", Type: apps.FieldTypeUser, IsRequired: true},
+			{Name: "info", Type: apps.FieldTypeMarkdown, IsRequired: true},
+			{Name: "fixed", Type: apps.FieldTypeText, ReadOnly: true, TextMaxLength: 1},
+		},
+	}
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":   "José",
+			"count":  "12.5",
+			"email":  "jose@example.com",
+			"url":    "https://example.com/path",
+			"flag":   true,
+			"color":  option("red"),
+			"colors": []interface{}{option("red"), option("blue")},
+			"user":   option("user-id"),
+			"fixed":  "ignored",
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		field    string
+		value    interface{}
+		expected string
+	}{
+		"valid":                  {},
+		"missing required":       {"name", nil, "This field is required."},
+		"empty required":         {"name", "", "This field is required."},
+		"empty required option":  {"user", option(""), "This field is required."},
+		"empty optional":         {"count", "", ""},
+		"too short":              {"name", "J", "Must be at least 2 characters long."},
+		"too long":               {"name", "Joséph", "Must be at most 5 characters long."},
+		"not text":               {"name", 12.0, "Must be text."},
+		"number":                 {"count", 3.0, ""},
+		"not a number":           {"count", "twelve", "Must be a number."},
+		"invalid email":          {"email", "jose@", "Must be a valid email address."},
+		"email with name":        {"email", "Jose <jose@example.com>", "Must be a valid email address."},
+		"invalid URL":            {"url", "example.com", "Must be a valid URL."},
+		"bool string":            {"flag", "true", ""},
+		"not a bool":             {"flag", "yes", "Must be true or false."},
+		"not an option":          {"color", option("green"), `"green" is not one of the options.`},
+		"string option":          {"color", "blue", ""},
+		"multiple for single":    {"color", []interface{}{option("red"), option("blue")}, "Only one option may be selected."},
+		"not an option in multi": {"colors", []interface{}{option("red"), option("green")}, `"green" is not one of the options.`},
+		"single for multi":       {"colors", option("blue"), ""},
+		"invalid option":         {"user", 12.0, "Must be an option."},
+	} {
+		t.Run(name, func(t *testing.T) {
+			values := valid()
+			expected := map[string]string{}
+			if tc.field != "" {
+				values[tc.field] = tc.value
+				if tc.expected != "" {
+					expected[tc.field] = tc.expected
+				}
+			}
+			require.Equal(t, expected, validateFormValues(form, values))
+		})
+	}
+}
+
+func TestFormSubmissionValidation(t *testing.T) {
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID:         "app1",
+			UserCallPaths: []string{"/open"},
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+	form := apps.Form{
+		Submit: apps.NewCall("/form/submit"),
+		Fields: []apps.Field{
+			{Name: "name", Type: apps.FieldTypeText, IsRequired: true},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+
+	up := mock_upstream.NewMockUpstream(ctrl)
+	received := []string{}
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+			received = append(received, creq.Path)
+			cresp := apps.NewTextResponse("submitted")
+			if creq.Path == "/open" {
+				cresp = apps.NewFormResponse(form)
+			}
+			return io.NopCloser(strings.NewReader(utils.ToJSON(cresp))), nil
+		}).AnyTimes()
+
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{
+		conf: conf,
+		store: &store.Service{
+			App:          appStore,
+			UserCallPath: &testUserCallPathStore{paths: map[apps.AppID]map[string]map[string]bool{}},
+			ServedForm:   &testServedFormStore{forms: map[string][]apps.Form{}},
+		},
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).
+		WithActingUserID("user-id").
+		WithDestination(app.AppID)
+	call := func(r *incoming.Request, path, formID string, values map[string]interface{}, selectedField string) CallResponse {
+		creq := apps.CallRequest{
+			Call:          *apps.NewCall(path),
+			Values:        values,
+			SelectedField: selectedField,
+			FormID:        formID,
+		}
+		creq.Context.AppID = app.AppID
+		return p.InvokeCall(r, creq)
+	}
+
+	cresp := call(r, "/open", "", nil, "")
+	require.Equal(t, apps.CallResponseTypeForm, cresp.Type)
+	formID := cresp.Form.ID
+	require.NotEmpty(t, formID)
+
+	// An invalid submission is rejected without calling the app.
+	cresp = call(r, "/form/submit", formID, map[string]interface{}{"name": ""}, "")
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+	require.Equal(t, map[string]map[string]string{
+		"errors": {"name": "This field is required."},
+	}, cresp.Data)
+	require.Equal(t, []string{"/open"}, received)
+
+	// Refreshes are not validated.
+	cresp = call(r, "/form/submit", formID, nil, "name")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+
+	// Submissions without the form's ID are validated against the forms served
+	// for the path.
+	cresp = call(r, "/form/submit", "", nil, "")
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+	cresp = call(r, "/form/submit", "", map[string]interface{}{"name": "value"}, "")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+
+	// Forms that have expired, or were not served for the path or to the
+	// user, are rejected.
+	cresp = call(r, "/form/submit", "expired-form-id", map[string]interface{}{"name": "value"}, "")
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+	cresp = call(r, "/other", formID, nil, "")
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+	cresp = call(r.WithActingUserID("other-user-id"), "/form/submit", formID, map[string]interface{}{"name": "value"}, "")
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+
+	// Calls to paths no form was served for are not validated.
+	cresp = call(r, "/other", "", nil, "")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+	cresp = call(r.WithActingUserID("other-user-id"), "/form/submit", "", nil, "")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+
+	cresp = call(r, "/form/submit", formID, map[string]interface{}{"name": "value"}, "")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+	require.Equal(t, []string{"/open", "/form/submit", "/form/submit", "/other", "/form/submit", "/form/submit"}, received)
+
+	// The forms in the bindings are recorded when served, unless they are
+	// fetched from a source.
+	bindings := []apps.Binding{
+		{
+			Location: apps.LocationCommand,
+			Bindings: []apps.Binding{
+				{
+					Location: "create",
+					Form: &apps.Form{
+						Submit: apps.NewCall("/command/create"),
+						Fields: []apps.Field{{Name: "title", Type: apps.FieldTypeText, IsRequired: true}},
+					},
+				},
+				{
+					Location: "edit",
+					Form: &apps.Form{
+						Source: apps.NewCall("/command/edit/form"),
+						Submit: apps.NewCall("/command/edit"),
+					},
+				},
+			},
+		},
+	}
+	p.recordServedForms(r, app.AppID, bindings)
+	commandFormID := bindings[0].Bindings[0].Form.ID
+	require.NotEmpty(t, commandFormID)
+	require.Empty(t, bindings[0].Bindings[1].Form.ID)
+
+	p.recordServedForms(r, app.AppID, bindings)
+	require.Equal(t, commandFormID, bindings[0].Bindings[0].Form.ID, "serving the same form again reuses its ID")
+
+	cresp = call(r, "/command/create", "", nil, "")
+	require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+	cresp = call(r, "/command/create", commandFormID, map[string]interface{}{"title": "value"}, "")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+	cresp = call(r, "/command/edit", "", nil, "")
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+}
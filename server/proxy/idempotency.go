@@ -0,0 +1,102 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// idempotencyPollInterval is how often a repeated call checks whether the call
+// in progress has completed.
+const idempotencyPollInterval = 200 * time.Millisecond
+
+// callIdempotencyKey returns the key to de-duplicate the call by, scoped to the
+// app and the acting user, or "" if the call is not to be de-duplicated. If
+// the client did not provide a key, one is derived for the submissions of
+// bindings and forms, if the app opted in. Commands are never de-duplicated
+// by a derived key, repeating one is deliberate.
+func callIdempotencyKey(r *incoming.Request, app *apps.App, creq apps.CallRequest) string {
+	key := creq.IdempotencyKey
+	if key == "" {
+		if !app.DeduplicateSubmits || !creq.Context.TrackAsSubmit || creq.RawCommand != "" {
+			return ""
+		}
+		uac := creq.Context.UserAgentContext
+		key = utils.ToJSON(struct {
+			Path       string                 `json:"path"`
+			State      interface{}            `json:"state,omitempty"`
+			Values     map[string]interface{} `json:"values,omitempty"`
+			Location   apps.Location          `json:"location,omitempty"`
+			ChannelID  string                 `json:"channel_id,omitempty"`
+			PostID     string                 `json:"post_id,omitempty"`
+			RootPostID string                 `json:"root_post_id,omitempty"`
+		}{
+			Path:       creq.Path,
+			State:      creq.State,
+			Values:     creq.Values,
+			Location:   uac.Location,
+			ChannelID:  uac.ChannelID,
+			PostID:     uac.PostID,
+			RootPostID: uac.RootPostID,
+		})
+	}
+
+	hash := sha256.Sum256([]byte(string(app.AppID) + "\n" + r.ActingUserID() + "\n" + key))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// invokeIdempotent invokes the call, unless a call with the same key is in
+// progress, or has completed within the configured window. In that case it
+// waits for the call to complete, and replays its response. Failed calls are
+// not replayed, so they can be retried.
+func (p *Proxy) invokeIdempotent(r *incoming.Request, app *apps.App, creq apps.CallRequest, invoke func() apps.CallResponse) apps.CallResponse {
+	window := p.conf.Get().IdempotencyWindow
+	key := callIdempotencyKey(r, app, creq)
+	if window <= 0 || key == "" {
+		return invoke()
+	}
+	inProgressTTL := p.callTimeout(app, &creq.Call, config.DefaultCallTimeout) + window
+
+	for {
+		existing, err := p.store.Idempotency.Begin(key, inProgressTTL)
+		if err != nil {
+			r.Log.WithError(err).Warnf("failed to check for repeated calls, invoking the call")
+			return invoke()
+		}
+		if existing == nil {
+			break
+		}
+		if existing.Response != nil {
+			r.Log.Debugf("replayed the response to a repeated call %s", creq.Path)
+			return *existing.Response
+		}
+
+		select {
+		case <-r.Ctx().Done():
+			return apps.NewErrorResponse(errors.Wrap(r.Ctx().Err(), "timed out waiting for a repeated call to complete"))
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+
+	cresp := invoke()
+	var err error
+	if cresp.Type == apps.CallResponseTypeError {
+		err = p.store.Idempotency.Delete(key)
+	} else {
+		err = p.store.Idempotency.Complete(key, cresp, window)
+	}
+	if err != nil {
+		r.Log.WithError(err).Warnf("failed to store the response for repeated calls")
+	}
+	return cresp
+}
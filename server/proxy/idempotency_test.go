@@ -0,0 +1,210 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testIdempotencyStore struct {
+	mutex sync.Mutex
+	calls map[string]store.IdempotentCall
+}
+
+func (s *testIdempotencyStore) Begin(key string, _ time.Duration) (*store.IdempotentCall, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if existing, ok := s.calls[key]; ok {
+		return &existing, nil
+	}
+	s.calls[key] = store.IdempotentCall{}
+	return nil, nil
+}
+
+func (s *testIdempotencyStore) Complete(key string, cresp apps.CallResponse, _ time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls[key] = store.IdempotentCall{Response: &cresp}
+	return nil
+}
+
+func (s *testIdempotencyStore) Get(key string) (*store.IdempotentCall, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if existing, ok := s.calls[key]; ok {
+		return &existing, nil
+	}
+	return nil, nil
+}
+
+func (s *testIdempotencyStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.calls, key)
+	return nil
+}
+
+func TestInvokeIdempotent(t *testing.T) {
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID:              "test",
+			DeduplicateSubmits: true,
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+	submit := func(key string, values map[string]interface{}) apps.CallRequest {
+		creq := apps.CallRequest{
+			Call:           *apps.NewCall("/create"),
+			Values:         values,
+			IdempotencyKey: key,
+		}
+		creq.Context.TrackAsSubmit = true
+		return creq
+	}
+
+	newProxy := func(t *testing.T, response apps.CallResponse, expectedCalls int) *Proxy {
+		ctrl := gomock.NewController(t)
+		up := mock_upstream.NewMockUpstream(ctrl)
+		up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+			DoAndReturn(func(context.Context, apps.App, apps.CallRequest, bool) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(utils.ToJSON(response))), nil
+			}).Times(expectedCalls)
+
+		return &Proxy{
+			conf: config.NewTestConfigService(&config.Config{
+				IdempotencyWindow: time.Minute,
+			}),
+			store:            &store.Service{Idempotency: &testIdempotencyStore{calls: map[string]store.IdempotentCall{}}},
+			builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+		}
+	}
+	invoke := func(p *Proxy, userID string, creq apps.CallRequest) apps.CallResponse {
+		r := incoming.NewRequest(p.conf, utils.NewTestLogger(), nil).WithActingUserID(userID)
+		return p.invokeIdempotent(r, app, creq, func() apps.CallResponse {
+			return p.callApp(r, app, creq)
+		})
+	}
+
+	t.Run("repeated submit is replayed", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 1)
+		creq := submit("", map[string]interface{}{"name": "test"})
+		require.Equal(t, "created", invoke(p, "user-id", creq).Text)
+		require.Equal(t, "created", invoke(p, "user-id", creq).Text)
+	})
+
+	t.Run("different values are not repeated", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 2)
+		invoke(p, "user-id", submit("", map[string]interface{}{"name": "test"}))
+		invoke(p, "user-id", submit("", map[string]interface{}{"name": "other"}))
+	})
+
+	t.Run("different users are not repeated", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 2)
+		invoke(p, "user-id", submit("key", nil))
+		invoke(p, "other-user-id", submit("key", nil))
+	})
+
+	t.Run("client key", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 1)
+		creq := submit("key", map[string]interface{}{"name": "test"})
+		creq.Context.TrackAsSubmit = false
+		invoke(p, "user-id", creq)
+		creq.Values = map[string]interface{}{"name": "other"}
+		require.Equal(t, "created", invoke(p, "user-id", creq).Text)
+	})
+
+	t.Run("not a submit", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 2)
+		creq := submit("", nil)
+		creq.Context.TrackAsSubmit = false
+		invoke(p, "user-id", creq)
+		invoke(p, "user-id", creq)
+	})
+
+	t.Run("errors are not replayed", func(t *testing.T) {
+		p := newProxy(t, apps.NewErrorResponse(utils.NewInvalidError("failed")), 2)
+		invoke(p, "user-id", submit("", nil))
+		invoke(p, "user-id", submit("", nil))
+	})
+
+	t.Run("commands are not de-duplicated", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 2)
+		creq := submit("", nil)
+		creq.RawCommand = "/test create"
+		invoke(p, "user-id", creq)
+		invoke(p, "user-id", creq)
+	})
+
+	t.Run("app did not opt in", func(t *testing.T) {
+		r := incoming.NewRequest(config.NewTestConfigService(nil), utils.NewTestLogger(), nil).WithActingUserID("user-id")
+		optedOut := &apps.App{Manifest: apps.Manifest{AppID: "test"}}
+		require.Empty(t, callIdempotencyKey(r, optedOut, submit("", nil)))
+		require.NotEmpty(t, callIdempotencyKey(r, optedOut, submit("key", nil)))
+	})
+
+	t.Run("replayed calls are not rate limited", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 1)
+		p.conf = config.NewTestConfigService(&config.Config{
+			IdempotencyWindow: time.Minute,
+			RateLimits:        config.RateLimitsConfig{PerUser: 1},
+		})
+		p.store.RateLimit = &testRateLimitStore{taken: map[string]int{}}
+		r := incoming.NewRequest(p.conf, utils.NewTestLogger(), nil).WithActingUserID("user-id")
+		creq := submit("key", nil)
+		require.Equal(t, "created", p.invokeCall(r, app, creq).Text)
+		require.Equal(t, "created", p.invokeCall(r, app, creq).Text)
+
+		cresp := p.invokeCall(r, app, submit("other-key", nil))
+		require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+		require.NotZero(t, cresp.RetryAfter)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 2)
+		p.conf = config.NewTestConfigService(nil)
+		invoke(p, "user-id", submit("key", nil))
+		invoke(p, "user-id", submit("key", nil))
+	})
+
+	t.Run("waits for the call in progress", func(t *testing.T) {
+		p := newProxy(t, apps.NewTextResponse("created"), 0)
+		creq := submit("key", nil)
+		r := incoming.NewRequest(p.conf, utils.NewTestLogger(), nil).WithActingUserID("user-id")
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		first := make(chan apps.CallResponse)
+		go func() {
+			first <- p.invokeIdempotent(r, app, creq, func() apps.CallResponse {
+				close(started)
+				<-release
+				return apps.NewTextResponse("created once")
+			})
+		}()
+		<-started
+
+		second := make(chan apps.CallResponse)
+		go func() {
+			second <- p.invokeIdempotent(r, app, creq, func() apps.CallResponse {
+				return apps.NewTextResponse("created twice")
+			})
+		}()
+		close(release)
+		require.Equal(t, "created once", (<-first).Text)
+		require.Equal(t, "created once", (<-second).Text)
+	})
+}
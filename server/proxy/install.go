@@ -18,12 +18,17 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/apps/path"
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
 // InstallApp installs an App.
 //  - cc is the Context that will be passed down to the App's OnInstall callback.
-func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppID, deployType apps.DeployType, trusted bool, secret string) (*apps.App, string, error) {
+func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppID, deployType apps.DeployType, trusted bool, secret string) (_ *apps.App, _ string, err error) {
+	defer func() {
+		p.auditAction(r, store.AuditEventInstall, appID, err)
+	}()
+
 	if err := r.Check(
 		r.RequireActingUser,
 		r.RequireSysadminOrPlugin,
@@ -52,6 +57,7 @@ func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppI
 		app = &apps.App{}
 	}
 
+	prevSubscriptions := app.Subscriptions
 	app.DeployType = deployType
 	app.Manifest = *m
 	if app.Disabled {
@@ -76,6 +82,11 @@ func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppI
 		defer icon.Close()
 	}
 
+	err = p.validateManifestSubscriptions(app.Subscriptions)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to install")
+	}
+
 	if !p.pingApp(r.Ctx(), app) {
 		return nil, "", errors.Wrapf(err, "failed to install, %s path is not accessible", apps.DefaultPing.Path)
 	}
@@ -90,8 +101,15 @@ func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppI
 		return nil, "", err
 	}
 
+	err = p.reconcileManifestSubscriptions(r, app, prevSubscriptions)
+	if err != nil {
+		p.rollbackManifestSubscriptions(r, app, prevSubscriptions)
+		return nil, "", errors.Wrap(err, "failed to install, subscriptions have been rolled back")
+	}
+
 	err = p.store.App.Save(r, *app)
 	if err != nil {
+		p.rollbackManifestSubscriptions(r, app, prevSubscriptions)
 		return nil, "", err
 	}
 
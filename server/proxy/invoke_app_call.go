@@ -0,0 +1,60 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// InvokeAppCall executes a call to another app, made by the source app on
+// behalf of the acting user. The source app must be granted the call_apps
+// permission, and the destination app must list it in its AppCallers. The
+// source app is passed to the destination app in the context, as CallerAppID.
+func (p *Proxy) InvokeAppCall(r *incoming.Request, creq apps.CallRequest) (out CallResponse) {
+	defer func() {
+		p.auditCall(r, r.Destination(), creq.Path, out.CallResponse)
+	}()
+
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSourceApp,
+	); err != nil {
+		return newErrorCallResponse(nil, err)
+	}
+	if r.Destination() == r.SourceAppID() {
+		return newErrorCallResponse(nil, utils.NewInvalidError("%s can not call itself", r.SourceAppID()))
+	}
+
+	caller, err := p.GetInstalledApp(r.SourceAppID(), true)
+	if err != nil {
+		return newErrorCallResponse(nil, err)
+	}
+	if !caller.GrantedPermissions.Contains(apps.PermissionCallApps) {
+		return newErrorCallResponse(nil, utils.NewForbiddenError("%s does not have permission to %s", caller.AppID, apps.PermissionCallApps))
+	}
+
+	app, err := p.getEnabledDestination(r)
+	if err != nil {
+		return newErrorCallResponse(nil, err)
+	}
+	if creq.Context.AppID != app.AppID {
+		return newErrorCallResponse(app, utils.NewInvalidError("incoming.Request validation error: app_id mismatch"))
+	}
+
+	cleanPath, err := cleanCallPath(creq.Path)
+	if err != nil {
+		return newErrorCallResponse(app, err)
+	}
+	creq.Path = cleanPath
+
+	if !app.AllowsAppCall(caller.AppID, creq.Path) {
+		r.Log.Debugf("rejected call to %s from %s, not allowed by the app", creq.Path, caller.AppID)
+		return newErrorCallResponse(app, utils.NewForbiddenError("%s may not be called by %s", creq.Path, caller.AppID))
+	}
+
+	r.Log.Debugf("%s invoked call %s", caller.AppID, creq.Path)
+	return p.invokeCall(r, app, creq)
+}
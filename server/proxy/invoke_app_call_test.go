@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestInvokeAppCall(t *testing.T) {
+	caller := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "tickets",
+		},
+		DeployType:         apps.DeployBuiltin,
+		GrantedPermissions: apps.Permissions{apps.PermissionCallApps},
+	}
+	notPermitted := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "other",
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+	target := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "oncall",
+			AppCallers: []apps.AppCaller{
+				{AppID: "tickets", Paths: []string{"/page/*"}},
+				{AppID: "other", Paths: []string{"/*"}},
+			},
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+
+	for name, tc := range map[string]struct {
+		caller        apps.AppID
+		path          string
+		expectedError error
+	}{
+		"allowed": {
+			caller: "tickets",
+			path:   "/page/form",
+		},
+		"path not allowed": {
+			caller:        "tickets",
+			path:          "/on_install",
+			expectedError: utils.ErrForbidden,
+		},
+		"no permission": {
+			caller:        "other",
+			path:          "/page/form",
+			expectedError: utils.ErrForbidden,
+		},
+		"not an app": {
+			path:          "/page/form",
+			expectedError: utils.ErrUnauthorized,
+		},
+		"calls itself": {
+			caller:        "oncall",
+			path:          "/page/form",
+			expectedError: utils.ErrInvalid,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			appStore := mock_store.NewMockAppStore(ctrl)
+			for _, app := range []apps.App{caller, notPermitted, target} {
+				app := app
+				appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+			}
+			appStore.EXPECT().Get(gomock.Any()).Return(nil, utils.ErrNotFound).AnyTimes()
+
+			up := mock_upstream.NewMockUpstream(ctrl)
+			if tc.expectedError == nil {
+				up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+					DoAndReturn(func(_ context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+						require.Equal(t, tc.caller, creq.Context.CallerAppID)
+						return io.NopCloser(strings.NewReader(utils.ToJSON(apps.NewTextResponse("paged")))), nil
+					})
+			}
+
+			conf := config.NewTestConfigService(nil)
+			p := &Proxy{
+				conf:             conf,
+				store:            &store.Service{App: appStore},
+				builtinUpstreams: map[apps.AppID]upstream.Upstream{target.AppID: up},
+			}
+			r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).
+				WithActingUserID("user-id").
+				WithDestination(target.AppID)
+			if tc.caller != "" {
+				r = r.WithSourceAppID(tc.caller)
+			}
+
+			creq := apps.CallRequest{
+				Call: *apps.NewCall(tc.path),
+			}
+			creq.Context.AppID = target.AppID
+			cresp := p.InvokeAppCall(r, creq)
+			if tc.expectedError != nil {
+				require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+				require.Contains(t, cresp.Text, tc.expectedError.Error())
+				return
+			}
+			require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+			require.Equal(t, "paged", cresp.Text)
+		})
+	}
+}
@@ -44,6 +44,8 @@ func (p *Proxy) InvokeGetBindings(r *incoming.Request, cc apps.Context) ([]apps.
 		if err != nil {
 			problems = multierror.Append(problems, err)
 		}
+		p.recordUserCallPaths(r, app, bindings)
+		p.recordServedForms(r, app.AppID, bindings)
 		return bindings, problems
 
 	case apps.CallResponseTypeError:
@@ -4,12 +4,17 @@
 package proxy
 
 import (
+	"context"
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 // CallResponse contains everything the CallResponse struct contains, plus some additional
@@ -22,53 +27,88 @@ type CallResponse struct {
 
 	// Used to provide info about the App to client, e.g. the bot user id
 	AppMetadata AppMetadataForClient `json:"app_metadata"`
+
+	// RetryAfter is set when the call was rejected by a rate limit.
+	RetryAfter time.Duration `json:"-"`
 }
 
+// MaxCallResponseDepth is the maximum number of follow-up calls made in
+// response to a single call, when the app responds with the "call" type.
+const MaxCallResponseDepth = 10
+
 type AppMetadataForClient struct {
 	BotUserID   string `json:"bot_user_id,omitempty"`
 	BotUsername string `json:"bot_username,omitempty"`
 }
 
-func (p *Proxy) InvokeCall(r *incoming.Request, creq apps.CallRequest) CallResponse {
-	var app *apps.App
-	respondErr := func(err error) CallResponse {
-		out := CallResponse{
-			CallResponse: apps.NewErrorResponse(err),
-		}
-		if app != nil {
-			out.AppMetadata = AppMetadataForClient{
-				BotUserID:   app.BotUserID,
-				BotUsername: app.BotUsername,
-			}
-		}
-		return out
-	}
+func (p *Proxy) InvokeCall(r *incoming.Request, creq apps.CallRequest) (out CallResponse) {
+	defer func() {
+		p.auditCall(r, r.Destination(), creq.Path, out.CallResponse)
+	}()
 
 	if err := r.Check(
 		r.RequireActingUser,
 	); err != nil {
-		return respondErr(err)
+		return newErrorCallResponse(nil, err)
 	}
 
 	app, err := p.getEnabledDestination(r)
 	if err != nil {
-		return respondErr(err)
+		return newErrorCallResponse(nil, err)
 	}
 	if creq.Context.AppID != app.AppID {
-		return respondErr(utils.NewInvalidError("incoming.Request validation error: app_id mismatch"))
+		return newErrorCallResponse(app, utils.NewInvalidError("incoming.Request validation error: app_id mismatch"))
 	}
 
-	if creq.Path[0] != '/' {
-		return respondErr(utils.NewInvalidError("call path must start with a %q: %q", "/", creq.Path))
-	}
-	cleanPath, err := utils.CleanPath(creq.Path)
+	cleanPath, err := cleanCallPath(creq.Path)
 	if err != nil {
-		return respondErr(errors.Wrap(err, "failed to clean call path"))
+		return newErrorCallResponse(app, err)
 	}
 	creq.Path = cleanPath
 
+	if err = p.checkUserCallPath(r, app, creq.Path); err != nil {
+		return newErrorCallResponse(app, err)
+	}
+	if cresp := p.checkFormSubmission(r, app.AppID, creq); cresp != nil {
+		return CallResponse{
+			CallResponse: *cresp,
+			AppMetadata: AppMetadataForClient{
+				BotUserID:   app.BotUserID,
+				BotUsername: app.BotUsername,
+			},
+		}
+	}
+
+	return p.invokeCall(r, app, creq)
+}
+
+// invokeCall executes a vetted call request, coming from the user agent or
+// from another app.
+func (p *Proxy) invokeCall(r *incoming.Request, app *apps.App, creq apps.CallRequest) CallResponse {
+	if creq.Async && !app.AllowsAsyncCallPath(creq.Path) {
+		return newErrorCallResponse(app, utils.NewForbiddenError("%s may not be called asynchronously, it is not declared in async_call_paths of %s", creq.Path, app.AppID))
+	}
+
+	// The replayed calls do not take from the rate limits.
+	var rateLimitErr error
 	appRequest := r.WithDestination(app.AppID)
-	cresp := p.callApp(appRequest, app, creq, false)
+	cresp := p.invokeIdempotent(appRequest, app, creq, func() apps.CallResponse {
+		if rateLimitErr = p.checkCallRateLimits(r, app.AppID); rateLimitErr != nil {
+			return apps.NewErrorResponse(rateLimitErr)
+		}
+		if creq.Async {
+			return p.startCallJob(appRequest, app, creq)
+		}
+		cresp := p.callApp(appRequest, app, creq)
+		return p.followCallResponses(appRequest, app, creq, cresp)
+	})
+	if rateLimitErr != nil {
+		return newErrorCallResponse(app, rateLimitErr)
+	}
+	if cresp.Type == apps.CallResponseTypeForm {
+		p.recordUserCallPaths(appRequest, app, nil, cresp.Form)
+		p.recordServedForms(appRequest, app.AppID, nil, cresp.Form)
+	}
 
 	return CallResponse{
 		CallResponse: cresp,
@@ -79,6 +119,73 @@ func (p *Proxy) InvokeCall(r *incoming.Request, creq apps.CallRequest) CallRespo
 	}
 }
 
+func newErrorCallResponse(app *apps.App, err error) CallResponse {
+	out := CallResponse{
+		CallResponse: apps.NewErrorResponse(err),
+	}
+	if app != nil {
+		out.AppMetadata = AppMetadataForClient{
+			BotUserID:   app.BotUserID,
+			BotUsername: app.BotUsername,
+		}
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		out.RetryAfter = rateLimitErr.RetryAfter
+	}
+	return out
+}
+
+// followCallResponses executes the follow-up calls while the app responds with
+// the "call" type, and returns the final response. The follow-up calls are made
+// with the original user context, and are expanded according to their own
+// expand rules. The chain is limited to MaxCallResponseDepth calls, and may not
+// repeat a call.
+func (p *Proxy) followCallResponses(r *incoming.Request, app *apps.App, creq apps.CallRequest, cresp apps.CallResponse) apps.CallResponse {
+	seen := map[string]bool{
+		utils.ToJSON(creq.Call): true,
+	}
+	for depth := 1; cresp.Type == apps.CallResponseTypeCall; depth++ {
+		if cresp.Call == nil {
+			return apps.NewErrorResponse(errors.New("call response type requires a call"))
+		}
+		if depth > MaxCallResponseDepth {
+			return apps.NewErrorResponse(errors.Errorf("too many follow-up calls, the limit is %v", MaxCallResponseDepth))
+		}
+
+		next := *cresp.Call
+		cleanPath, err := cleanCallPath(next.Path)
+		if err != nil {
+			return apps.NewErrorResponse(errors.Wrap(err, "invalid follow-up call"))
+		}
+		next.Path = cleanPath
+
+		key := utils.ToJSON(next)
+		if seen[key] {
+			return apps.NewErrorResponse(errors.Errorf("follow-up call loop detected: %s", next.Path))
+		}
+		seen[key] = true
+
+		r.Log.Debugf("following up with call %s, depth %v", next.Path, depth)
+		cresp = p.callApp(r, app, apps.CallRequest{
+			Call:    next,
+			Context: creq.Context,
+		})
+	}
+	return cresp
+}
+
+func cleanCallPath(callPath string) (string, error) {
+	if callPath == "" || callPath[0] != '/' {
+		return "", utils.NewInvalidError("call path must start with a %q: %q", "/", callPath)
+	}
+	cleanPath, err := utils.CleanPath(callPath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to clean call path")
+	}
+	return cleanPath, nil
+}
+
 // <>/<> TODO: need to cleanup creq (Context) here? or assume it's good as is?
 func (p *Proxy) call(r *incoming.Request, app *apps.App, call apps.Call, cc *apps.Context, valuePairs ...interface{}) apps.CallResponse {
 	values := map[string]interface{}{}
@@ -103,17 +210,41 @@ func (p *Proxy) call(r *incoming.Request, app *apps.App, call apps.Call, cc *app
 		Call:    call,
 		Context: *cc,
 		Values:  values,
-	}, false)
+	})
 	return cresp
 }
 
 // callApp in an internal method to execute a call to an upstream app. It does
 // not perform any cleanup of the inputs.
-func (p *Proxy) callApp(r *incoming.Request, app *apps.App, creq apps.CallRequest, notify bool) apps.CallResponse {
+func (p *Proxy) callApp(r *incoming.Request, app *apps.App, creq apps.CallRequest) (cresp apps.CallResponse) {
+	start := time.Now()
+	unexpanded := creq.Context
+	ctx, span := tracing.Start(r.Ctx(), "apps.call",
+		tracing.Attr("app_id", string(app.AppID)),
+		tracing.Attr("path", creq.Path),
+		tracing.Attr("deploy_type", string(app.DeployType)),
+		tracing.Attr("request_id", r.RequestID()))
+	defer func() {
+		p.metrics.ObserveCall(app.AppID, creq.Path, app.DeployType, cresp.Type, time.Since(start))
+		p.recordCall(r, app, unexpanded, creq, cresp, start)
+		span.SetAttributes(tracing.Attr("response_type", string(cresp.Type)))
+		if cresp.Type == apps.CallResponseTypeError {
+			span.RecordError(cresp)
+		}
+		span.End()
+	}()
+
 	// this may be invoked from various places in the code, and the Destination
 	// may or may not be set in the request. Since we have the app explicitly
 	// here, make sure it's set in the request
-	r = r.WithDestination(app.AppID)
+	r = r.WithDestination(app.AppID).WithCtx(ctx)
+
+	// Async calls run within the time budget of their job.
+	if creq.JobID == "" {
+		var cancel context.CancelFunc
+		r = r.WithTimeout(p.callTimeout(app, &creq.Call, config.DefaultCallTimeout), &cancel)
+		defer cancel()
+	}
 
 	up, err := p.upstreamForApp(app)
 	if err != nil {
@@ -127,15 +258,12 @@ func (p *Proxy) callApp(r *incoming.Request, app *apps.App, creq apps.CallReques
 	}
 	creq.Context = *expanded
 
-	if notify {
-		err = upstream.Notify(r.Ctx(), up, *app, creq)
-		if err != nil {
-			return apps.NewErrorResponse(errors.Wrap(err, "upstream call failed"))
-		}
-		return apps.NewTextResponse("OK")
-	}
-
-	cresp, err := upstream.Call(r.Ctx(), up, *app, creq)
+	upstreamCtx, upstreamSpan := tracing.Start(r.Ctx(), "apps.upstream",
+		tracing.Attr("app_id", string(app.AppID)),
+		tracing.Attr("path", creq.Path))
+	cresp, err = upstream.Call(upstreamCtx, up, *app, creq)
+	upstreamSpan.RecordError(err)
+	upstreamSpan.End()
 	if err != nil {
 		return apps.NewErrorResponse(errors.Wrap(err, "upstream call failed"))
 	}
@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
+)
+
+func TestFollowCallResponses(t *testing.T) {
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+	callResponse := func(path string) apps.CallResponse {
+		return apps.CallResponse{
+			Type: apps.CallResponseTypeCall,
+			Call: apps.NewCall(path),
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		responses     map[string]apps.CallResponse
+		expectedType  apps.CallResponseType
+		expectedText  string
+		expectedError string
+	}{
+		"single follow-up": {
+			responses: map[string]apps.CallResponse{
+				"/first":  callResponse("/second"),
+				"/second": apps.NewTextResponse("done"),
+			},
+			expectedType: apps.CallResponseTypeOK,
+			expectedText: "done",
+		},
+		"chain": {
+			responses: map[string]apps.CallResponse{
+				"/first":  callResponse("/second/../third"),
+				"/third":  callResponse("/fourth"),
+				"/fourth": apps.NewTextResponse("done"),
+			},
+			expectedType: apps.CallResponseTypeOK,
+			expectedText: "done",
+		},
+		"loop": {
+			responses: map[string]apps.CallResponse{
+				"/first":  callResponse("/second"),
+				"/second": callResponse("/first"),
+			},
+			expectedType:  apps.CallResponseTypeError,
+			expectedError: "follow-up call loop detected: /first",
+		},
+		"missing call": {
+			responses: map[string]apps.CallResponse{
+				"/first": {Type: apps.CallResponseTypeCall},
+			},
+			expectedType:  apps.CallResponseTypeError,
+			expectedError: "call response type requires a call",
+		},
+		"invalid path": {
+			responses: map[string]apps.CallResponse{
+				"/first": callResponse("second"),
+			},
+			expectedType:  apps.CallResponseTypeError,
+			expectedError: `invalid follow-up call: call path must start with a "/": "second": invalid input`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			up := mock_upstream.NewMockUpstream(ctrl)
+			up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).AnyTimes().
+				DoAndReturn(func(_ context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+					cresp, ok := tc.responses[creq.Path]
+					require.True(t, ok, "unexpected call to %s", creq.Path)
+					return io.NopCloser(strings.NewReader(utils.ToJSON(cresp))), nil
+				})
+
+			conf := config.NewTestConfigService(nil)
+			p := &Proxy{
+				conf:             conf,
+				builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+			}
+			r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithDestination(app.AppID)
+
+			creq := apps.CallRequest{Call: *apps.NewCall("/first")}
+			cresp := p.followCallResponses(r, app, creq, p.callApp(r, app, creq))
+			require.Equal(t, tc.expectedType, cresp.Type)
+			if tc.expectedError != "" {
+				require.EqualError(t, cresp, tc.expectedError)
+			} else {
+				require.Equal(t, tc.expectedText, cresp.Text)
+			}
+		})
+	}
+
+	t.Run("depth limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		up := mock_upstream.NewMockUpstream(ctrl)
+		n := 0
+		up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).Times(MaxCallResponseDepth + 1).
+			DoAndReturn(func(_ context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+				n++
+				cresp := callResponse("/next/" + strings.Repeat("x", n))
+				return io.NopCloser(strings.NewReader(utils.ToJSON(cresp))), nil
+			})
+
+		conf := config.NewTestConfigService(nil)
+		p := &Proxy{
+			conf:             conf,
+			builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+		}
+		r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithDestination(app.AppID)
+
+		creq := apps.CallRequest{Call: *apps.NewCall("/first")}
+		cresp := p.followCallResponses(r, app, creq, p.callApp(r, app, creq))
+		require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+		require.EqualError(t, cresp, "too many follow-up calls, the limit is 10")
+	})
+}
+
+type testSpanExporter struct {
+	mutex sync.Mutex
+	spans map[string]tracing.SpanData
+}
+
+func (e *testSpanExporter) Export(s tracing.SpanData) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans[s.Name] = s
+}
+
+func (e *testSpanExporter) Shutdown() {}
+
+func TestCallAppTracing(t *testing.T) {
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+	e := &testSpanExporter{spans: map[string]tracing.SpanData{}}
+	tracing.SetExporter(e)
+	defer tracing.SetExporter(nil)
+
+	var upstreamSpan tracing.SpanContext
+	ctrl := gomock.NewController(t)
+	up := mock_upstream.NewMockUpstream(ctrl)
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(ctx context.Context, _ apps.App, _ apps.CallRequest, _ bool) (io.ReadCloser, error) {
+			upstreamSpan = tracing.SpanContextFromContext(ctx)
+			return io.NopCloser(strings.NewReader(utils.ToJSON(apps.NewTextResponse("done")))), nil
+		})
+
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{
+		conf:             conf,
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithDestination(app.AppID)
+
+	cresp := p.callApp(r, app, apps.CallRequest{Call: *apps.NewCall("/first")})
+	require.Equal(t, apps.CallResponseTypeOK, cresp.Type)
+
+	require.Len(t, e.spans, 3)
+	call := e.spans["apps.call"]
+	require.Contains(t, call.Attributes, tracing.Attr("path", "/first"))
+	require.Contains(t, call.Attributes, tracing.Attr("response_type", "ok"))
+	for _, name := range []string{"apps.expand", "apps.upstream"} {
+		require.Equal(t, call.SpanContext.TraceID, e.spans[name].SpanContext.TraceID)
+		require.Equal(t, call.SpanContext.SpanID, e.spans[name].Parent)
+	}
+	require.Equal(t, e.spans["apps.upstream"].SpanContext, upstreamSpan)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
@@ -40,7 +41,11 @@ func (p *Proxy) InvokeGetRemoteOAuth2ConnectURL(r *incoming.Request) (string, er
 	return connectURL, nil
 }
 
-func (p *Proxy) InvokeCompleteRemoteOAuth2(r *incoming.Request, urlValues map[string]interface{}) error {
+func (p *Proxy) InvokeCompleteRemoteOAuth2(r *incoming.Request, urlValues map[string]interface{}) (err error) {
+	defer func() {
+		p.auditAction(r, store.AuditEventOAuth2Connect, r.Destination(), err)
+	}()
+
 	app, err := p.getEnabledDestination(r)
 	if err != nil {
 		return err
@@ -62,7 +67,7 @@ func (p *Proxy) InvokeCompleteRemoteOAuth2(r *incoming.Request, urlValues map[st
 		Call:    app.OnOAuth2Complete.WithDefault(apps.DefaultOnOAuth2Complete),
 		Context: apps.Context{},
 		Values:  urlValues,
-	}, false)
+	})
 	if cresp.Type == apps.CallResponseTypeError {
 		return &cresp
 	}
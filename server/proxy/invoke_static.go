@@ -58,9 +58,9 @@ func (p *Proxy) getStatic(r *incoming.Request, app *apps.App, path string) (io.R
 
 // pingApp checks if the app is accessible. Call its ping path with nothing
 // expanded, ignore 404 errors coming back and consider everything else a
-// "success".
+// "success". The ping is not subject to the app's call timeouts.
 func (p *Proxy) pingApp(ctx context.Context, app *apps.App) (reachable bool) {
-	ctx, cancel := context.WithTimeout(ctx, pingAppTimeout)
+	ctx, cancel := context.WithTimeout(ctx, config.DefaultPingTimeout)
 	defer cancel()
 
 	up, err := p.upstreamForApp(app)
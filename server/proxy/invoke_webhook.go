@@ -4,6 +4,7 @@
 package proxy
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
@@ -65,13 +67,15 @@ func (p *Proxy) InvokeRemoteWebhook(r *incoming.Request, httpCallRequest apps.HT
 	call := app.OnRemoteWebhook.WithDefault(apps.DefaultOnRemoteWebhook)
 	call.Path = path.Join(call.Path, httpCallRequest.Path)
 
-	appRequest := r.WithActingUserID("")
+	ctx, cancel := context.WithTimeout(r.Ctx(), p.callTimeout(app, &call, config.DefaultCallTimeout))
+	defer cancel()
+
+	appRequest := r.WithActingUserID("").WithCtx(ctx)
 	cc, err := p.expandContext(appRequest, app, nil, call.Expand)
 	if err != nil {
 		return err
 	}
-
-	return upstream.Notify(r.Ctx(), up, *app, apps.CallRequest{
+	return upstream.Notify(ctx, up, *app, apps.CallRequest{
 		Call:    call,
 		Context: *cc,
 		Values: map[string]interface{}{
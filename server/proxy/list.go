@@ -7,7 +7,6 @@ import (
 	"context"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/mattermost/mattermost-server/v6/model"
 
@@ -15,8 +14,6 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 )
 
-const pingAppTimeout = 1 * time.Second
-
 func (p *Proxy) GetManifest(appID apps.AppID) (*apps.Manifest, error) {
 	return p.store.Manifest.Get(appID)
 }
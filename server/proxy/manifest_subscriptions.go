@@ -0,0 +1,92 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// validateManifestSubscriptions checks the subscriptions declared in a
+// manifest before the app is installed. The teams and channels they reference
+// by ID must exist on this Mattermost server.
+func (p *Proxy) validateManifestSubscriptions(subs []apps.Subscription) error {
+	mm := p.conf.MattermostAPI()
+	for _, sub := range subs {
+		if err := sub.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid subscription to %s", sub.Event)
+		}
+		if sub.TeamID != "" {
+			if _, err := mm.Team.Get(sub.TeamID); err != nil {
+				return errors.Wrapf(err, "subscription to %s: team %s is not found", sub.Subject, sub.TeamID)
+			}
+		}
+		if sub.ChannelID != "" {
+			if _, err := mm.Channel.Get(sub.ChannelID); err != nil {
+				return errors.Wrapf(err, "subscription to %s: channel %s is not found", sub.Subject, sub.ChannelID)
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileManifestSubscriptions makes the app's bot-owned subscriptions match
+// the ones declared in its manifest. prev are the subscriptions declared by the
+// previously installed version of the app, those of them that are no longer
+// declared are removed. Subscriptions the app made by itself, using the bot
+// token, are not affected.
+func (p *Proxy) reconcileManifestSubscriptions(r *incoming.Request, app *apps.App, prev []apps.Subscription) error {
+	if len(prev)+len(app.Subscriptions) == 0 {
+		return nil
+	}
+	if app.BotUserID == "" {
+		return errors.Errorf("%s has no bot account to own the subscriptions", app.AppID)
+	}
+	r = r.WithSourceAppID(app.AppID).WithActingUserID(app.BotUserID)
+
+	declared := map[apps.Event]bool{}
+	for _, sub := range app.Subscriptions {
+		declared[sub.Event] = true
+	}
+
+	var result error
+	for _, sub := range prev {
+		if declared[sub.Event] {
+			continue
+		}
+		err := p.appservices.Unsubscribe(r, sub.Event)
+		if err != nil && errors.Cause(err) != utils.ErrNotFound {
+			result = multierror.Append(result, errors.Wrapf(err, "failed to unsubscribe from %s", sub.Event))
+		}
+	}
+
+	// Subscribe replaces an existing same-scoped subscription, so the ones that
+	// have not changed are simply re-created.
+	for _, sub := range app.Subscriptions {
+		err := p.appservices.Subscribe(r, sub)
+		if err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "failed to subscribe to %s", sub.Event))
+		}
+	}
+
+	if result == nil {
+		r.Log.Debugf("reconciled %v manifest subscriptions for %s", len(app.Subscriptions), app.AppID)
+	}
+	return result
+}
+
+// rollbackManifestSubscriptions restores the subscriptions declared by the
+// previously installed version of the app, after a failed install. Errors are
+// logged, the install has already failed.
+func (p *Proxy) rollbackManifestSubscriptions(r *incoming.Request, app *apps.App, prev []apps.Subscription) {
+	prevApp := *app
+	prevApp.Subscriptions = prev
+	if err := p.reconcileManifestSubscriptions(r, &prevApp, app.Subscriptions); err != nil {
+		r.Log.WithError(err).Errorf("failed to roll back the subscriptions of %s", app.AppID)
+	}
+}
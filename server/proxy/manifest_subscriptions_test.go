@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_appservices"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestReconcileManifestSubscriptions(t *testing.T) {
+	mentioned := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectBotMentioned},
+		Call:  *apps.NewCall("/mentioned"),
+	}
+	joined := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectBotJoinedTeam},
+		Call:  *apps.NewCall("/joined"),
+	}
+	created := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectChannelCreated, TeamID: "team-id"},
+		Call:  *apps.NewCall("/created"),
+	}
+
+	ctrl := gomock.NewController(t)
+	appServices := mock_appservices.NewMockService(ctrl)
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{
+		conf:        conf,
+		appservices: appServices,
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithActingUserID("admin-id")
+
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID:         "test",
+			Subscriptions: []apps.Subscription{mentioned, created},
+		},
+		BotUserID: "bot-id",
+	}
+
+	asBot := gomock.AssignableToTypeOf(r)
+	var actingUserIDs []string
+	var sourceAppIDs []apps.AppID
+	record := func(r *incoming.Request) {
+		actingUserIDs = append(actingUserIDs, r.ActingUserID())
+		sourceAppIDs = append(sourceAppIDs, r.SourceAppID())
+	}
+	gomock.InOrder(
+		appServices.EXPECT().Unsubscribe(asBot, joined.Event).DoAndReturn(func(r *incoming.Request, _ apps.Event) error {
+			record(r)
+			return utils.ErrNotFound
+		}),
+		appServices.EXPECT().Subscribe(asBot, mentioned).DoAndReturn(func(r *incoming.Request, _ apps.Subscription) error {
+			record(r)
+			return nil
+		}),
+		appServices.EXPECT().Subscribe(asBot, created).DoAndReturn(func(r *incoming.Request, _ apps.Subscription) error {
+			record(r)
+			return nil
+		}),
+	)
+
+	err := p.reconcileManifestSubscriptions(r, app, []apps.Subscription{mentioned, joined})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bot-id", "bot-id", "bot-id"}, actingUserIDs)
+	require.Equal(t, []apps.AppID{"test", "test", "test"}, sourceAppIDs)
+
+	app.BotUserID = ""
+	err = p.reconcileManifestSubscriptions(r, app, nil)
+	require.Error(t, err)
+}
+
+func TestValidateManifestSubscriptions(t *testing.T) {
+	conf, api := config.NewTestService(nil)
+	api.On("GetTeam", "team-id").Return(&model.Team{Id: "team-id"}, nil)
+	api.On("GetTeam", "other-team-id").Return(nil, model.NewAppError("GetTeam", "not found", nil, "", http.StatusNotFound))
+	api.On("GetChannel", "channel-id").Return(&model.Channel{Id: "channel-id"}, nil)
+	p := &Proxy{conf: conf}
+
+	created := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectChannelCreated, TeamID: "team-id"},
+		Call:  *apps.NewCall("/created"),
+	}
+	posted := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectPostCreated, ChannelID: "channel-id"},
+		Call:  *apps.NewCall("/posted"),
+	}
+	require.NoError(t, p.validateManifestSubscriptions([]apps.Subscription{created, posted}))
+
+	created.TeamID = "other-team-id"
+	err := p.validateManifestSubscriptions([]apps.Subscription{created, posted})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "team other-team-id is not found")
+}
+
+func TestRollbackManifestSubscriptions(t *testing.T) {
+	mentioned := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectBotMentioned},
+		Call:  *apps.NewCall("/mentioned"),
+	}
+	created := apps.Subscription{
+		Event: apps.Event{Subject: apps.SubjectChannelCreated, TeamID: "team-id"},
+		Call:  *apps.NewCall("/created"),
+	}
+
+	ctrl := gomock.NewController(t)
+	appServices := mock_appservices.NewMockService(ctrl)
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{
+		conf:        conf,
+		appservices: appServices,
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithActingUserID("admin-id")
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID:         "test",
+			Subscriptions: []apps.Subscription{created},
+		},
+		BotUserID: "bot-id",
+	}
+
+	// The subscriptions of the failed install are removed, and the previous
+	// ones restored.
+	asBot := gomock.AssignableToTypeOf(r)
+	gomock.InOrder(
+		appServices.EXPECT().Unsubscribe(asBot, created.Event).Return(nil),
+		appServices.EXPECT().Subscribe(asBot, mentioned).Return(nil),
+	)
+	p.rollbackManifestSubscriptions(r, app, []apps.Subscription{mentioned})
+	require.Equal(t, []apps.Subscription{created}, app.Subscriptions)
+}
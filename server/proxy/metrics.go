@@ -0,0 +1,29 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"io"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/metrics"
+)
+
+// WriteMetrics outputs the proxy's metrics in the Prometheus text format. The
+// notification queue metrics are sampled at the time of the call.
+func (p *Proxy) WriteMetrics(w io.Writer) error {
+	queues := []metrics.NotificationQueue{}
+	for _, s := range p.NotificationQueueStats() {
+		queues = append(queues, metrics.NotificationQueue{
+			AppID:     s.AppID,
+			Queued:    s.Queued,
+			Capacity:  s.Capacity,
+			Active:    s.Active,
+			Workers:   s.Workers,
+			Delivered: s.Delivered,
+			Shed:      s.Shed,
+		})
+	}
+	p.metrics.SetNotificationQueues(queues)
+	return p.metrics.Write(w)
+}
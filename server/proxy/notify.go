@@ -4,10 +4,13 @@
 package proxy
 
 import (
-	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
-	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 )
@@ -173,46 +176,359 @@ func (p *Proxy) NotifyChannelCreated(teamID, channelID string) {
 		})
 }
 
-func (p *Proxy) notify(match func(store.Subscription) bool, event apps.Event, uac apps.UserAgentContext) {
-	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
-	defer cancel()
-	r := p.NewIncomingRequest().WithCtx(ctx)
-	r.Log = r.Log.With(event)
+// NotifyChannelArchived emits "channel_archived" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyChannelArchived(channel *model.Channel, userID string) {
+	before := *channel
+	before.DeleteAt = 0
+	p.notifyChannelChange(apps.SubjectChannelArchived, &before, channel, userID)
+}
 
-	subs, err := p.store.Subscription.Get(event)
+// NotifyChannelRestored emits "channel_restored" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyChannelRestored(channel *model.Channel, userID string) {
+	p.notifyChannelChange(apps.SubjectChannelRestored, nil, channel, userID)
+}
+
+// NotifyChannelUpdated emits "channel_updated" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyChannelUpdated(before, after *model.Channel, userID string) {
+	p.notifyChannelChange(apps.SubjectChannelUpdated, before, after, userID)
+}
+
+// NotifyChannelConverted emits "channel_converted" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyChannelConverted(before, after *model.Channel, userID string) {
+	p.notifyChannelChange(apps.SubjectChannelConverted, before, after, userID)
+}
+
+func (p *Proxy) notifyChannelChange(subject apps.Subject, before, after *model.Channel, userID string) {
+	event := apps.Event{
+		Subject: subject,
+		TeamID:  after.TeamId,
+	}
+	cc := apps.Context{
+		UserAgentContext: apps.UserAgentContext{
+			ChannelID: after.Id,
+			TeamID:    after.TeamId,
+			UserID:    userID,
+		},
+	}
+	cc.ExpandedContext.PreviousChannel = before
+
+	// Public channels are visible to anyone who can list the team's channels,
+	// the subscription's permission. Private channels are only reported to
+	// the subscribers who can read them.
+	var match func(store.Subscription) bool
+	if after.Type == model.ChannelTypePrivate || (before != nil && before.Type == model.ChannelTypePrivate) {
+		mm := p.conf.MattermostAPI()
+		match = func(sub store.Subscription) bool {
+			return mm.User.HasPermissionToChannel(sub.OwnerUserID, after.Id, model.PermissionReadChannel)
+		}
+	}
+
+	p.notifyWithContext(match, event, cc)
+}
+
+// notifyChannelSystemPost emits the channel lifecycle notifications. There are
+// no plugin hooks for channel updates, so they are sourced from the system
+// posts that the server creates in the channel. The channel before the change
+// is the channel as currently stored, with the changed field's previous value
+// read from the post's "old_" prop. The system posts for privacy changes do
+// not record the previous type, it is the opposite of the current one.
+func (p *Proxy) notifyChannelSystemPost(post *model.Post) {
+	var subject apps.Subject
+	switch post.Type {
+	case model.PostTypeChannelDeleted:
+		subject = apps.SubjectChannelArchived
+	case model.PostTypeChannelRestored:
+		subject = apps.SubjectChannelRestored
+	case model.PostTypeDisplaynameChange, model.PostTypeHeaderChange, model.PostTypePurposeChange:
+		subject = apps.SubjectChannelUpdated
+	case model.PostTypeChangeChannelPrivacy, model.PostTypeConvertChannel:
+		subject = apps.SubjectChannelConverted
+	default:
+		return
+	}
+
+	channel, err := p.conf.MattermostAPI().Channel.Get(post.ChannelId)
 	if err != nil {
-		r.Log.WithError(err).Errorf("notify: failed to load subscriptions")
+		p.log.WithError(err).Debugf("notifyChannelSystemPost: failed to get channel")
+		return
+	}
+	subs, err := p.store.Subscription.Get(apps.Event{Subject: subject, TeamID: channel.TeamId})
+	if err != nil {
+		p.log.WithError(err).Errorf("notifyChannelSystemPost: failed to load subscriptions")
+		return
+	}
+	if len(subs) == 0 {
 		return
 	}
 
-	for _, sub := range subs {
-		if match == nil || match(sub) {
-			go p.invokeNotify(r, event, sub, &apps.Context{
-				Subject:          event.Subject,
-				UserAgentContext: uac,
-			})
+	before := *channel
+	switch post.Type {
+	case model.PostTypeChannelDeleted:
+		p.NotifyChannelArchived(channel, post.UserId)
+		return
+	case model.PostTypeChannelRestored:
+		p.NotifyChannelRestored(channel, post.UserId)
+		return
+	case model.PostTypeDisplaynameChange:
+		before.DisplayName = oldChannelField(post, "old_displayname", channel.DisplayName)
+	case model.PostTypeHeaderChange:
+		before.Header = oldChannelField(post, "old_header", channel.Header)
+	case model.PostTypePurposeChange:
+		before.Purpose = oldChannelField(post, "old_purpose", channel.Purpose)
+	case model.PostTypeConvertChannel:
+		// Only ever posted for the conversion of a public channel to private.
+		before.Type = model.ChannelTypeOpen
+	case model.PostTypeChangeChannelPrivacy:
+		before.Type = model.ChannelTypeOpen
+		if channel.Type == model.ChannelTypeOpen {
+			before.Type = model.ChannelTypePrivate
 		}
 	}
+
+	if subject == apps.SubjectChannelConverted {
+		p.NotifyChannelConverted(&before, channel, post.UserId)
+	} else {
+		p.NotifyChannelUpdated(&before, channel, post.UserId)
+	}
 }
 
-func (p *Proxy) invokeNotify(r *incoming.Request, event apps.Event, sub store.Subscription, contextToExpand *apps.Context) {
-	var err error
-	defer func() {
-		if err == nil {
+// oldChannelField returns the value of a channel field before the change,
+// from the prop of the system post that records it. If the prop is missing, the
+// current value is returned.
+func oldChannelField(post *model.Post, prop, current string) string {
+	if v, ok := post.GetProp(prop).(string); ok {
+		return v
+	}
+	return current
+}
+
+// NotifyMessageHasBeenPosted handles plugin's MessageHasBeenPosted callback. It
+// emits "post_created" and "bot_mentioned" notifications to subscribed apps,
+// and the channel lifecycle notifications for the channel system posts.
+//
+// It is invoked for every post, so the bot_mentioned subscriptions are only
+// loaded if the message may mention someone, and nothing else is done unless
+// there is a match.
+func (p *Proxy) NotifyMessageHasBeenPosted(post *model.Post) {
+	if post.IsSystemMessage() {
+		p.notifyChannelSystemPost(post)
+	}
+
+	postSubs, err := p.store.Subscription.Get(apps.Event{
+		Subject:   apps.SubjectPostCreated,
+		ChannelID: post.ChannelId,
+	})
+	if err != nil {
+		p.log.WithError(err).Errorf("NotifyMessageHasBeenPosted: failed to load post_created subscriptions")
+		return
+	}
+
+	var mentionSubs []store.Subscription
+	if mentions := possibleAtMentions(post.Message); len(mentions) > 0 {
+		mentionSubs, err = p.store.Subscription.Get(apps.Event{
+			Subject: apps.SubjectBotMentioned,
+		})
+		if err != nil {
+			p.log.WithError(err).Errorf("NotifyMessageHasBeenPosted: failed to load bot_mentioned subscriptions")
 			return
 		}
-		if p.conf.Get().DeveloperMode {
-			r.Log.WithError(err).Errorf("notify error")
-		} else {
-			r.Log.Debugf("notify")
+		mentionSubs = p.matchBotMentions(post, mentions, mentionSubs)
+	}
+
+	if len(postSubs) == 0 && len(mentionSubs) == 0 {
+		return
+	}
+
+	channel, err := p.conf.MattermostAPI().Channel.Get(post.ChannelId)
+	if err != nil {
+		p.log.WithError(err).Debugf("NotifyMessageHasBeenPosted: failed to get channel")
+		return
+	}
+	uac := apps.UserAgentContext{
+		ChannelID:  post.ChannelId,
+		TeamID:     channel.TeamId,
+		PostID:     post.Id,
+		RootPostID: post.RootId,
+		UserID:     post.UserId,
+	}
+
+	// Do not notify apps of their own bot's posts, to avoid loops.
+	allApps := p.store.App.AsMap()
+	notOwnPost := func(sub store.Subscription) bool {
+		app, ok := allApps[sub.AppID]
+		return ok && app.BotUserID != post.UserId
+	}
+
+	for _, s := range []struct {
+		event apps.Event
+		subs  []store.Subscription
+	}{
+		{
+			event: apps.Event{Subject: apps.SubjectPostCreated, ChannelID: post.ChannelId},
+			subs:  postSubs,
+		}, {
+			event: apps.Event{Subject: apps.SubjectBotMentioned},
+			subs:  mentionSubs,
+		},
+	} {
+		if len(s.subs) == 0 {
+			continue
+		}
+		r := p.NewIncomingRequest()
+		r.Log = r.Log.With(s.event)
+		filter := p.newFilterData(uac)
+		for _, sub := range s.subs {
+			if notOwnPost(sub) && filter.match(sub.Filter) {
+				p.invokeNotify(r, s.event, sub, &apps.Context{
+					Subject:          s.event.Subject,
+					UserAgentContext: uac,
+				})
+			}
+		}
+	}
+}
+
+// NotifyReactionHasBeenAdded handles plugin's ReactionHasBeenAdded callback. It
+// emits "reaction_added" notifications to subscribed apps.
+func (p *Proxy) NotifyReactionHasBeenAdded(reaction *model.Reaction) {
+	p.notifyReaction(reaction, apps.SubjectReactionAdded)
+}
+
+// NotifyReactionHasBeenRemoved handles plugin's ReactionHasBeenRemoved
+// callback. It emits "reaction_removed" notifications to subscribed apps.
+func (p *Proxy) NotifyReactionHasBeenRemoved(reaction *model.Reaction) {
+	p.notifyReaction(reaction, apps.SubjectReactionRemoved)
+}
+
+func (p *Proxy) notifyReaction(reaction *model.Reaction, subject apps.Subject) {
+	mm := p.conf.MattermostAPI()
+
+	// Reactions passed to ReactionHasBeenRemoved may not have the channel ID
+	// set, get it from the post.
+	var post *model.Post
+	if reaction.ChannelId == "" {
+		var err error
+		post, err = mm.Post.GetPost(reaction.PostId)
+		if err != nil {
+			p.log.WithError(err).Debugf("notifyReaction: failed to get post")
+			return
 		}
-	}()
+		clone := *reaction
+		clone.ChannelId = post.ChannelId
+		reaction = &clone
+	}
 
-	app, err := p.GetInstalledApp(sub.AppID, true)
+	event := apps.Event{
+		Subject:   subject,
+		ChannelID: reaction.ChannelId,
+	}
+	subs, err := p.store.Subscription.Get(event)
 	if err != nil {
+		p.log.WithError(err).Errorf("notifyReaction: failed to load subscriptions")
 		return
 	}
+	if len(subs) == 0 {
+		return
+	}
+
+	channel, err := mm.Channel.Get(reaction.ChannelId)
+	if err != nil {
+		p.log.WithError(err).Debugf("notifyReaction: failed to get channel")
+		return
+	}
+	if post == nil {
+		post, err = mm.Post.GetPost(reaction.PostId)
+		if err != nil {
+			p.log.WithError(err).Debugf("notifyReaction: failed to get post")
+			return
+		}
+	}
+
+	cc := apps.Context{
+		UserAgentContext: apps.UserAgentContext{
+			ChannelID:  reaction.ChannelId,
+			TeamID:     channel.TeamId,
+			PostID:     reaction.PostId,
+			RootPostID: post.RootId,
+			UserID:     reaction.UserId,
+		},
+	}
+	cc.ExpandedContext.Reaction = reaction
+
+	// Do not notify apps of their own bot's reactions, to avoid loops.
+	allApps := p.store.App.AsMap()
+	p.notifyWithContext(
+		func(sub store.Subscription) bool {
+			app, ok := allApps[sub.AppID]
+			return ok && app.BotUserID != reaction.UserId
+		},
+		event,
+		cc,
+	)
+}
+
+// matchBotMentions returns the bot_mentioned subscriptions of the apps whose
+// bots are mentioned in the post, and are members of the post's channel.
+func (p *Proxy) matchBotMentions(post *model.Post, mentions []string, subs []store.Subscription) []store.Subscription {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	allApps := p.store.App.AsMap()
+	var matched []store.Subscription
+	for _, sub := range subs {
+		app, ok := allApps[sub.AppID]
+		if !ok || app.BotUsername == "" {
+			continue
+		}
+		for _, m := range mentions {
+			if m != app.BotUsername {
+				continue
+			}
+			_, err := p.conf.MattermostAPI().Channel.GetMember(post.ChannelId, app.BotUserID)
+			if err == nil {
+				matched = append(matched, sub)
+			}
+			break
+		}
+	}
+	return matched
+}
+
+func (p *Proxy) notify(match func(store.Subscription) bool, event apps.Event, uac apps.UserAgentContext) {
+	p.notifyWithContext(match, event, apps.Context{
+		UserAgentContext: uac,
+	})
+}
+
+// notifyWithContext is like notify, but accepts the entire context to pass
+// to the apps, for the events that carry data that can not be expanded later.
+func (p *Proxy) notifyWithContext(match func(store.Subscription) bool, event apps.Event, cc apps.Context) {
+	r := p.NewIncomingRequest()
+	r.Log = r.Log.With(event)
 
+	subs, err := p.store.Subscription.Get(event)
+	if err != nil {
+		r.Log.WithError(err).Errorf("notify: failed to load subscriptions")
+		return
+	}
+
+	filter := p.newFilterData(cc.UserAgentContext)
+	for _, sub := range subs {
+		if (match == nil || match(sub)) && filter.match(sub.Filter) {
+			subCC := cc
+			subCC.Subject = event.Subject
+			p.invokeNotify(r, event, sub, &subCC)
+		}
+	}
+}
+
+// invokeNotify queues a notification for delivery to the subscribed app.
+func (p *Proxy) invokeNotify(r *incoming.Request, event apps.Event, sub store.Subscription, contextToExpand *apps.Context) {
 	if contextToExpand == nil {
 		contextToExpand = &apps.Context{
 			UserAgentContext: apps.UserAgentContext{
@@ -222,81 +538,78 @@ func (p *Proxy) invokeNotify(r *incoming.Request, event apps.Event, sub store.Su
 		}
 	}
 
-	appRequest := r.WithDestination(sub.AppID)
-	appRequest = appRequest.WithActingUserID(sub.OwnerUserID)
-	creq := apps.CallRequest{
-		Call:    sub.Call,
-		Context: *contextToExpand,
+	n := store.Notification{
+		ID:           model.NewId(),
+		Event:        event,
+		Subscription: sub,
+		Context:      *contextToExpand,
+		CreatedAt:    time.Now().UnixMilli(),
 	}
-	r.Log = r.Log.With(creq)
-	cresp := p.callApp(appRequest, app, creq, true)
-	if cresp.Type == apps.CallResponseTypeError {
-		err = cresp
+	p.notifications.enqueue(sub.AppID, queuedNotification{r: r, n: n})
+}
+
+// deliverQueuedNotification is invoked by the app's notification queue worker.
+// If the delivery fails, the notification is persisted to be retried later.
+func (p *Proxy) deliverQueuedNotification(job queuedNotification) {
+	err := p.deliverNotification(job.r, job.n)
+	if err != nil {
+		p.handleFailedNotification(job.r, job.n, err)
 	}
-	r.Log = r.Log.With(cresp)
 }
 
-// func (p *Proxy) NotifyMessageHasBeenPosted(post *model.Post, cc apps.Context) error {
-// 	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
-// 	defer cancel()
-
-// 	mm := p.conf.MattermostAPI()
-// 	r := incoming.NewRequest(p.conf, utils.NewPluginLogger(mm), p.sessionService).WithCtx(ctx)
-
-// 	subs, err := p.store.Subscription.Get(apps.SubjectPostCreated, cc.TeamID, cc.ChannelID)
-// 	if err != nil && err != utils.ErrNotFound {
-// 		return errors.Wrap(err, "failed to get post_created subscriptions")
-// 	}
-
-// 	appMentions := []string{}
-// 	allApps := p.store.App.AsMap()
-// 	for _, m := range possibleAtMentions(post.Message) {
-// 		for _, app := range allApps {
-// 			if app.BotUsername == m {
-// 				appMentions = append(appMentions, m)
-// 			}
-// 		}
-// 	}
-// 	if len(appMentions) > 0 {
-// 		mentionSubs, err := p.store.Subscription.Get(apps.SubjectBotMentioned, cc.TeamID, cc.ChannelID)
-// 		if err != nil && err != utils.ErrNotFound {
-// 			return errors.Wrap(err, "failed to get bot_mentioned subscriptions")
-// 		}
-// 		for _, sub := range mentionSubs {
-// 			for _, mention := range appMentions {
-// 				app, ok := allApps[sub.AppID]
-// 				if ok && mention == app.BotUsername {
-// 					subs = append(subs, sub)
-// 				}
-// 			}
-// 		}
-// 	}
-
-// 	if len(subs) == 0 {
-// 		return nil
-// 	}
-
-// 	return p.notify(r, cc, subs)
-// }
-
-// var atMentionRegexp = regexp.MustCompile(`\B@[[:alnum:]][[:alnum:]\.\-_:]*`)
-
-// // possibleAtMentions is copied over from mattermost-server/app.possibleAtMentions
-// func possibleAtMentions(message string) []string {
-// 	var names []string
-
-// 	if !strings.Contains(message, "@") {
-// 		return names
-// 	}
-
-// 	alreadyMentioned := make(map[string]bool)
-// 	for _, match := range atMentionRegexp.FindAllString(message, -1) {
-// 		name := model.NormalizeUsername(match[1:])
-// 		if !alreadyMentioned[name] && model.IsValidUsernameAllowRemote(name) {
-// 			names = append(names, name)
-// 			alreadyMentioned[name] = true
-// 		}
-// 	}
-
-// 	return names
-// }
+// shedNotification handles a notification rejected by a full queue, or left
+// over on shutdown. If persist is set, it is stored to be delivered by the
+// retry job, otherwise it is dropped.
+func (p *Proxy) shedNotification(job queuedNotification, persist bool) {
+	log := job.r.Log.With("notification_id", job.n.ID, "app_id", job.n.Subscription.AppID)
+	if !persist {
+		log.Warnf("notification dropped, the app's notification queue is full")
+		return
+	}
+
+	job.n.NextAttemptAt = time.Now().Add(notificationRetryBaseDelay).UnixMilli()
+	err := p.store.Notification.SaveRetry(job.n)
+	if err == nil {
+		log.Debugf("notification deferred for a retry")
+		return
+	}
+
+	// Rather than lose the notification, move it to the dead-letter list
+	// where it can be replayed.
+	log.WithError(err).Warnf("failed to save a shed notification for a retry")
+	job.n.NextAttemptAt = 0
+	job.n.LastError = "shed by a full notification queue"
+	if err = p.store.Notification.SaveDeadLetter(job.n); err != nil {
+		log.WithError(err).Errorf("failed to save a shed notification to the dead-letter list")
+		return
+	}
+	log.Warnf("notification moved to the dead-letter list")
+}
+
+// NotificationQueueStats returns the current state of the per-app
+// notification queues.
+func (p *Proxy) NotificationQueueStats() []NotificationQueueStats {
+	return p.notifications.stats()
+}
+
+var atMentionRegexp = regexp.MustCompile(`\B@[[:alnum:]][[:alnum:]\.\-_:]*`)
+
+// possibleAtMentions is copied over from mattermost-server/app.possibleAtMentions
+func possibleAtMentions(message string) []string {
+	var names []string
+
+	if !strings.Contains(message, "@") {
+		return names
+	}
+
+	alreadyMentioned := make(map[string]bool)
+	for _, match := range atMentionRegexp.FindAllString(message, -1) {
+		name := model.NormalizeUsername(match[1:])
+		if !alreadyMentioned[name] && model.IsValidUsernameAllowRemote(name) {
+			names = append(names, name)
+			alreadyMentioned[name] = true
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// filterData lazily loads the user and the channel of an event to evaluate the
+// subscription filters. It is shared by all subscriptions to the event, so that
+// the data is loaded at most once.
+type filterData struct {
+	userID    string
+	channelID string
+
+	getUser      func(userID string) (*model.User, error)
+	getChannel   func(channelID string) (*model.Channel, error)
+	isTeamMember func(teamID, userID string) bool
+
+	user          *model.User
+	userLoaded    bool
+	channel       *model.Channel
+	channelLoaded bool
+	teamMember    map[string]bool
+}
+
+func (p *Proxy) newFilterData(uac apps.UserAgentContext) *filterData {
+	mm := p.conf.MattermostAPI()
+	return &filterData{
+		userID:     uac.UserID,
+		channelID:  uac.ChannelID,
+		getUser:    mm.User.Get,
+		getChannel: mm.Channel.Get,
+		isTeamMember: func(teamID, userID string) bool {
+			member, err := mm.Team.GetMember(teamID, userID)
+			return err == nil && member != nil && member.DeleteAt == 0
+		},
+	}
+}
+
+// match returns true if the event satisfies the filter, or if there is no
+// filter.
+func (d *filterData) match(f *apps.SubscriptionFilter) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.ChannelTypes) > 0 {
+		channel := d.loadChannel()
+		if channel == nil || !containsChannelType(f.ChannelTypes, channel.Type) {
+			return false
+		}
+	}
+
+	if !f.NeedsUser() {
+		return true
+	}
+	user := d.loadUser()
+	if user == nil {
+		return false
+	}
+	if f.IsBot != nil && *f.IsBot != user.IsBot {
+		return false
+	}
+	if len(f.Roles) > 0 && !hasAnyRole(user, f.Roles) {
+		return false
+	}
+	if len(f.ExcludeRoles) > 0 && hasAnyRole(user, f.ExcludeRoles) {
+		return false
+	}
+	if len(f.TeamIDs) > 0 {
+		member := false
+		for _, teamID := range f.TeamIDs {
+			if d.loadTeamMember(teamID) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *filterData) loadUser() *model.User {
+	if !d.userLoaded {
+		d.userLoaded = true
+		if d.userID != "" {
+			d.user, _ = d.getUser(d.userID)
+		}
+	}
+	return d.user
+}
+
+func (d *filterData) loadChannel() *model.Channel {
+	if !d.channelLoaded {
+		d.channelLoaded = true
+		if d.channelID != "" {
+			d.channel, _ = d.getChannel(d.channelID)
+		}
+	}
+	return d.channel
+}
+
+func (d *filterData) loadTeamMember(teamID string) bool {
+	if d.teamMember == nil {
+		d.teamMember = map[string]bool{}
+	}
+	member, ok := d.teamMember[teamID]
+	if !ok {
+		member = d.isTeamMember(teamID, d.userID)
+		d.teamMember[teamID] = member
+	}
+	return member
+}
+
+func containsChannelType(types []model.ChannelType, t model.ChannelType) bool {
+	for _, tt := range types {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyRole(user *model.User, roles []string) bool {
+	for _, role := range roles {
+		if user.IsInRole(role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestFilterDataMatch(t *testing.T) {
+	teamID := model.NewId()
+	otherTeamID := model.NewId()
+	users := map[string]*model.User{
+		"user":  {Id: "user", Roles: model.SystemUserRoleId},
+		"admin": {Id: "admin", Roles: model.SystemUserRoleId + " " + model.SystemAdminRoleId},
+		"guest": {Id: "guest", Roles: model.SystemGuestRoleId},
+		"bot":   {Id: "bot", Roles: model.SystemUserRoleId, IsBot: true},
+	}
+	channels := map[string]*model.Channel{
+		"open":    {Id: "open", Type: model.ChannelTypeOpen},
+		"private": {Id: "private", Type: model.ChannelTypePrivate},
+	}
+
+	for name, tc := range map[string]struct {
+		filter    *apps.SubscriptionFilter
+		userID    string
+		channelID string
+		expected  bool
+	}{
+		"no filter": {
+			filter:   nil,
+			expected: true,
+		},
+		"empty filter": {
+			filter:   &apps.SubscriptionFilter{},
+			expected: true,
+		},
+		"channel type matches": {
+			filter:    &apps.SubscriptionFilter{ChannelTypes: []model.ChannelType{model.ChannelTypeOpen}},
+			channelID: "open",
+			expected:  true,
+		},
+		"channel type does not match": {
+			filter:    &apps.SubscriptionFilter{ChannelTypes: []model.ChannelType{model.ChannelTypeOpen}},
+			channelID: "private",
+			expected:  false,
+		},
+		"channel type without a channel": {
+			filter:   &apps.SubscriptionFilter{ChannelTypes: []model.ChannelType{model.ChannelTypeOpen}},
+			expected: false,
+		},
+		"not a bot": {
+			filter:   &apps.SubscriptionFilter{IsBot: model.NewBool(false)},
+			userID:   "user",
+			expected: true,
+		},
+		"excluded bot": {
+			filter:   &apps.SubscriptionFilter{IsBot: model.NewBool(false)},
+			userID:   "bot",
+			expected: false,
+		},
+		"bots only": {
+			filter:   &apps.SubscriptionFilter{IsBot: model.NewBool(true)},
+			userID:   "bot",
+			expected: true,
+		},
+		"user criteria without a user": {
+			filter:   &apps.SubscriptionFilter{IsBot: model.NewBool(false)},
+			expected: false,
+		},
+		"role matches": {
+			filter:   &apps.SubscriptionFilter{Roles: []string{model.SystemAdminRoleId}},
+			userID:   "admin",
+			expected: true,
+		},
+		"role does not match": {
+			filter:   &apps.SubscriptionFilter{Roles: []string{model.SystemAdminRoleId}},
+			userID:   "user",
+			expected: false,
+		},
+		"excluded guest": {
+			filter:   &apps.SubscriptionFilter{IsBot: model.NewBool(false), ExcludeRoles: []string{model.SystemGuestRoleId}},
+			userID:   "guest",
+			expected: false,
+		},
+		"not excluded": {
+			filter:   &apps.SubscriptionFilter{IsBot: model.NewBool(false), ExcludeRoles: []string{model.SystemGuestRoleId}},
+			userID:   "user",
+			expected: true,
+		},
+		"team member": {
+			filter:   &apps.SubscriptionFilter{TeamIDs: []string{otherTeamID, teamID}},
+			userID:   "user",
+			expected: true,
+		},
+		"not a team member": {
+			filter:   &apps.SubscriptionFilter{TeamIDs: []string{otherTeamID}},
+			userID:   "user",
+			expected: false,
+		},
+		"all criteria must match": {
+			filter: &apps.SubscriptionFilter{
+				ChannelTypes: []model.ChannelType{model.ChannelTypeOpen},
+				Roles:        []string{model.SystemAdminRoleId},
+			},
+			userID:    "user",
+			channelID: "open",
+			expected:  false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := &filterData{
+				userID:    tc.userID,
+				channelID: tc.channelID,
+				getUser: func(userID string) (*model.User, error) {
+					if u := users[userID]; u != nil {
+						return u, nil
+					}
+					return nil, utils.ErrNotFound
+				},
+				getChannel: func(channelID string) (*model.Channel, error) {
+					if c := channels[channelID]; c != nil {
+						return c, nil
+					}
+					return nil, utils.ErrNotFound
+				},
+				isTeamMember: func(id, userID string) bool {
+					return id == teamID
+				},
+			}
+			require.Equal(t, tc.expected, d.match(tc.filter))
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+// NotificationQueueStats is a snapshot of an app's notification queue.
+type NotificationQueueStats struct {
+	AppID apps.AppID `json:"app_id"`
+
+	// Queued is the number of notifications waiting for a worker, out of
+	// Capacity.
+	Queued   int `json:"queued"`
+	Capacity int `json:"capacity"`
+
+	// Active is the number of notifications being delivered, out of Workers.
+	Active  int `json:"active"`
+	Workers int `json:"workers"`
+
+	// Delivered and Shed count the notifications processed, and rejected by
+	// the queue since it was created.
+	Delivered uint64 `json:"delivered"`
+	Shed      uint64 `json:"shed"`
+}
+
+type queuedNotification struct {
+	r *incoming.Request
+	n store.Notification
+}
+
+// notificationQueue is a bounded queue of notifications for a single app,
+// served by a fixed number of workers.
+type notificationQueue struct {
+	jobs chan queuedNotification
+
+	active    int32
+	delivered uint64
+	shed      uint64
+}
+
+// notificationQueues maintains the per-app notification queues, so that a
+// burst of events results in a bounded number of concurrent outgoing requests
+// per app, and a slow app does not hold up the notifications to others.
+type notificationQueues struct {
+	// deliver is invoked by a worker for each queued notification. shed is
+	// invoked for the notifications rejected by a full queue, or left in the
+	// queue on shutdown; persist indicates that the notification should be
+	// stored for a later retry rather than dropped.
+	deliver func(queuedNotification)
+	shed    func(_ queuedNotification, persist bool)
+
+	// mutex guards conf, queues and closed. Sending to a queue is non-blocking
+	// and is always done with mutex held, so that queues can be closed safely.
+	mutex  sync.Mutex
+	conf   config.NotificationsConfig
+	queues map[apps.AppID]*notificationQueue
+	closed bool
+
+	stop    chan struct{}
+	workers sync.WaitGroup
+}
+
+func newNotificationQueues(deliver func(queuedNotification), shed func(queuedNotification, bool)) *notificationQueues {
+	return &notificationQueues{
+		deliver: deliver,
+		shed:    shed,
+		conf: config.NotificationsConfig{
+			Workers:    config.DefaultNotificationWorkers,
+			QueueSize:  config.DefaultNotificationQueueSize,
+			ShedPolicy: config.DefaultShedPolicy,
+		},
+		queues: map[apps.AppID]*notificationQueue{},
+		stop:   make(chan struct{}),
+	}
+}
+
+// configure applies the new configuration. If the queue or the worker pool
+// sizes change, the existing queues are drained by their workers, and new
+// queues are created as needed.
+func (q *notificationQueues) configure(conf config.NotificationsConfig) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed || conf == q.conf {
+		return
+	}
+	resize := conf.Workers != q.conf.Workers || conf.QueueSize != q.conf.QueueSize
+	q.conf = conf
+	if !resize {
+		return
+	}
+	for _, queue := range q.queues {
+		close(queue.jobs)
+	}
+	q.queues = map[apps.AppID]*notificationQueue{}
+}
+
+// enqueue adds the notification to the app's queue. If the queue is full, the
+// configured shed policy is applied.
+func (q *notificationQueues) enqueue(appID apps.AppID, job queuedNotification) {
+	shed, persist := q.push(appID, job)
+	if shed != nil {
+		q.shed(*shed, persist)
+	}
+}
+
+func (q *notificationQueues) push(appID apps.AppID, job queuedNotification) (shed *queuedNotification, persist bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return &job, true
+	}
+	queue := q.queues[appID]
+	if queue == nil {
+		queue = q.newQueue()
+		q.queues[appID] = queue
+	}
+
+	select {
+	case queue.jobs <- job:
+		return nil, false
+	default:
+	}
+
+	atomic.AddUint64(&queue.shed, 1)
+	if q.conf.ShedPolicy != config.ShedDropOldest {
+		return &job, q.conf.ShedPolicy == config.ShedRetryLater
+	}
+
+	// Only the senders hold the mutex, so once the oldest job is taken out,
+	// there is room for the new one.
+	select {
+	case oldest := <-queue.jobs:
+		shed = &oldest
+	default:
+	}
+	queue.jobs <- job
+	return shed, false
+}
+
+func (q *notificationQueues) newQueue() *notificationQueue {
+	size := q.conf.QueueSize
+	if size < 1 {
+		size = 1
+	}
+	queue := &notificationQueue{
+		jobs: make(chan queuedNotification, size),
+	}
+	for i := 0; i < q.conf.Workers; i++ {
+		q.workers.Add(1)
+		go q.work(queue)
+	}
+	return queue
+}
+
+func (q *notificationQueues) work(queue *notificationQueue) {
+	defer q.workers.Done()
+	for job := range queue.jobs {
+		select {
+		case <-q.stop:
+			// Shutting down, keep the remaining notifications for a retry.
+			q.shed(job, true)
+			continue
+		default:
+		}
+
+		atomic.AddInt32(&queue.active, 1)
+		q.deliver(job)
+		atomic.AddInt32(&queue.active, -1)
+		atomic.AddUint64(&queue.delivered, 1)
+	}
+}
+
+// close stops accepting new notifications, waits for the ones in flight to
+// complete, and sheds the rest for a later retry.
+func (q *notificationQueues) close() {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.stop)
+	for _, queue := range q.queues {
+		close(queue.jobs)
+	}
+	q.queues = nil
+	q.mutex.Unlock()
+
+	q.workers.Wait()
+}
+
+func (q *notificationQueues) stats() []NotificationQueueStats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	out := []NotificationQueueStats{}
+	for appID, queue := range q.queues {
+		out = append(out, NotificationQueueStats{
+			AppID:     appID,
+			Queued:    len(queue.jobs),
+			Capacity:  cap(queue.jobs),
+			Active:    int(atomic.LoadInt32(&queue.active)),
+			Workers:   q.conf.Workers,
+			Delivered: atomic.LoadUint64(&queue.delivered),
+			Shed:      atomic.LoadUint64(&queue.shed),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AppID < out[j].AppID })
+	return out
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+func TestNotificationQueuesShed(t *testing.T) {
+	for _, tc := range []struct {
+		policy          config.ShedPolicy
+		expectedShed    []string
+		expectedPersist bool
+		expectedQueued  []string
+	}{
+		{
+			policy:          config.ShedRetryLater,
+			expectedShed:    []string{"3"},
+			expectedPersist: true,
+			expectedQueued:  []string{"1", "2"},
+		},
+		{
+			policy:          config.ShedDropNewest,
+			expectedShed:    []string{"3"},
+			expectedPersist: false,
+			expectedQueued:  []string{"1", "2"},
+		},
+		{
+			policy:          config.ShedDropOldest,
+			expectedShed:    []string{"1"},
+			expectedPersist: false,
+			expectedQueued:  []string{"2", "3"},
+		},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			mutex := sync.Mutex{}
+			delivered := []string{}
+			shed := []string{}
+			persisted := []bool{}
+
+			q := newNotificationQueues(
+				func(job queuedNotification) {
+					if job.n.ID == "0" {
+						close(started)
+						<-release
+					}
+					mutex.Lock()
+					delivered = append(delivered, job.n.ID)
+					mutex.Unlock()
+				},
+				func(job queuedNotification, persist bool) {
+					mutex.Lock()
+					shed = append(shed, job.n.ID)
+					persisted = append(persisted, persist)
+					mutex.Unlock()
+				},
+			)
+			q.configure(config.NotificationsConfig{
+				Workers:    1,
+				QueueSize:  2,
+				ShedPolicy: tc.policy,
+			})
+
+			enqueue := func(id string) {
+				q.enqueue("test", queuedNotification{n: store.Notification{ID: id}})
+			}
+			// The only worker picks up "0", and blocks on it.
+			enqueue("0")
+			<-started
+			enqueue("1")
+			enqueue("2")
+			enqueue("3")
+
+			stats := q.stats()
+			require.Len(t, stats, 1)
+			require.Equal(t, NotificationQueueStats{
+				AppID:    apps.AppID("test"),
+				Queued:   2,
+				Capacity: 2,
+				Active:   1,
+				Workers:  1,
+				Shed:     1,
+			}, stats[0])
+
+			mutex.Lock()
+			require.Equal(t, tc.expectedShed, shed)
+			require.Equal(t, []bool{tc.expectedPersist}, persisted)
+			mutex.Unlock()
+
+			// Reconfiguring with a new size drains the existing queue.
+			q.configure(config.NotificationsConfig{
+				Workers:    1,
+				QueueSize:  10,
+				ShedPolicy: tc.policy,
+			})
+			close(release)
+			q.workers.Wait()
+			require.Equal(t, append([]string{"0"}, tc.expectedQueued...), delivered)
+			require.Empty(t, q.stats())
+
+			// Once closed, everything is shed for a retry.
+			q.close()
+			enqueue("4")
+			require.Equal(t, append(tc.expectedShed, "4"), shed)
+			require.Equal(t, []bool{tc.expectedPersist, true}, persisted)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// NotificationRetryInterval is how often the failed notifications are
+	// checked for being due a retry.
+	NotificationRetryInterval = 30 * time.Second
+
+	// NotificationMaxAttempts is the number of delivery attempts, after which
+	// a notification is moved to the app's dead-letter list.
+	NotificationMaxAttempts = 8
+
+	notificationRetryBaseDelay = 30 * time.Second
+	notificationRetryMaxDelay  = time.Hour
+)
+
+// errNotRetryable marks the delivery errors that would not be fixed by
+// retrying, like an expand failure, or a missing call path.
+var errNotRetryable = errors.New("not retryable")
+
+// notificationBackoff returns the delay before the next delivery attempt,
+// doubling with every attempt made.
+func notificationBackoff(attempts int) time.Duration {
+	delay := notificationRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= notificationRetryMaxDelay {
+			return notificationRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// deliverNotification makes the notification call to the app, and waits for
+// it to be acknowledged.
+func (p *Proxy) deliverNotification(r *incoming.Request, n store.Notification) error {
+	app, err := p.GetInstalledApp(n.Subscription.AppID, true)
+	if err != nil {
+		return errors.Wrap(errNotRetryable, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.callTimeout(app, &n.Subscription.Call, config.DefaultCallTimeout))
+	defer cancel()
+	r = r.WithCtx(ctx)
+	r = r.WithDestination(app.AppID)
+	r = r.WithActingUserID(n.Subscription.OwnerUserID)
+
+	up, err := p.upstreamForApp(app)
+	if err != nil {
+		return errors.Wrapf(err, "no available upstream for %s", app.AppID)
+	}
+
+	creq := apps.CallRequest{
+		Call:    n.Subscription.Call,
+		Context: n.Context,
+	}
+	expanded, err := p.expandContext(r, app, &creq.Context, creq.Expand)
+	if err != nil {
+		return errors.Wrap(errNotRetryable, "failed to expand context: "+err.Error())
+	}
+	creq.Context = *expanded
+	err = applyNotificationData(&creq, n)
+	if err != nil {
+		return errors.Wrap(errNotRetryable, err.Error())
+	}
+
+	err = upstream.NotifyAndWait(r.Ctx(), up, *app, creq)
+	switch {
+	case errors.Cause(err) == utils.ErrNotFound:
+		return errors.Wrap(errNotRetryable, "upstream call failed: "+err.Error())
+	case err != nil:
+		return errors.Wrap(err, "upstream call failed")
+	}
+	return nil
+}
+
+// applyNotificationData adds the event data that can not be expanded by ID,
+// and is passed along in the notification, to the expanded context.
+func applyNotificationData(creq *apps.CallRequest, n store.Notification) error {
+	creq.Context.ExpandedContext.Reaction = n.Context.ExpandedContext.Reaction
+
+	if prev := n.Context.ExpandedContext.PreviousChannel; prev != nil && creq.Expand != nil {
+		_, level, err := apps.ParseExpandLevel(creq.Expand.PreviousChannel)
+		if err != nil {
+			return err
+		}
+		creq.Context.ExpandedContext.PreviousChannel = apps.StripChannel(prev, level)
+	}
+	return nil
+}
+
+// handleFailedNotification schedules the notification for a retry, or moves
+// it to the app's dead-letter list once it is not retryable, or the retries
+// have been exhausted. Notifications for the apps that are no longer installed,
+// or are disabled are dropped.
+func (p *Proxy) handleFailedNotification(r *incoming.Request, n store.Notification, deliveryErr error) {
+	log := r.Log.With("notification_id", n.ID, "app_id", n.Subscription.AppID)
+	n.Attempts++
+	n.LastError = deliveryErr.Error()
+
+	if app, err := p.store.App.Get(n.Subscription.AppID); err != nil || app.Disabled {
+		log.WithError(deliveryErr).Debugf("notification dropped, app is not installed or disabled")
+		_ = p.store.Notification.DeleteRetry(n.Subscription.AppID, n.ID)
+		return
+	}
+
+	if errors.Cause(deliveryErr) != errNotRetryable && n.Attempts < NotificationMaxAttempts {
+		delay := notificationBackoff(n.Attempts)
+		n.NextAttemptAt = time.Now().Add(delay).UnixMilli()
+		err := p.store.Notification.SaveRetry(n)
+		if err == nil {
+			log.WithError(deliveryErr).Debugf("notification failed, attempt %v, will retry in %s", n.Attempts, delay)
+			return
+		}
+		// Rather than lose the notification, move it to the dead-letter list
+		// where it can be replayed.
+		log.WithError(err).Warnf("failed to save notification for a retry")
+	}
+
+	n.NextAttemptAt = 0
+	if err := p.store.Notification.SaveDeadLetter(n); err != nil {
+		log.WithError(err).Errorf("failed to save notification to the dead-letter list")
+		return
+	}
+	_ = p.store.Notification.DeleteRetry(n.Subscription.AppID, n.ID)
+	log.WithError(deliveryErr).Warnf("notification failed after %v attempt(s), moved to the dead-letter list", n.Attempts)
+}
+
+// RetryFailedNotifications re-delivers the failed notifications that are due a
+// retry. It is invoked periodically, by a single node in the cluster.
+func (p *Proxy) RetryFailedNotifications() {
+	now := time.Now().UnixMilli()
+	for appID := range p.store.App.AsMap() {
+		retries, err := p.store.Notification.ListDueRetries(appID, now)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to list notifications pending a retry for %s", appID)
+			continue
+		}
+
+		for _, n := range retries {
+			r := p.NewIncomingRequest()
+			r.Log = r.Log.With(n.Event)
+			err = p.deliverNotification(r, n)
+			if err != nil {
+				p.handleFailedNotification(r, n, err)
+				continue
+			}
+			_ = p.store.Notification.DeleteRetry(appID, n.ID)
+			r.Log.Debugf("notification %s delivered on attempt %v", n.ID, n.Attempts+1)
+		}
+	}
+}
+
+// ListFailedNotifications returns the app's dead-letter list, oldest first.
+func (p *Proxy) ListFailedNotifications(r *incoming.Request, appID apps.AppID) ([]store.Notification, error) {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	return p.store.Notification.ListDeadLetters(appID)
+}
+
+// ReplayFailedNotifications attempts to deliver the app's dead-lettered
+// notifications once more, removing the ones that succeed from the list. If id
+// is empty, all of the app's dead letters are replayed.
+func (p *Proxy) ReplayFailedNotifications(r *incoming.Request, appID apps.AppID, id string) (delivered, failed int, err error) {
+	if err = r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	toReplay, err := p.deadLetters(appID, id)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, n := range toReplay {
+		deliveryErr := p.deliverNotification(r, n)
+		if deliveryErr != nil {
+			failed++
+			n.Attempts++
+			n.LastError = deliveryErr.Error()
+			if err = p.store.Notification.SaveDeadLetter(n); err != nil {
+				return delivered, failed, err
+			}
+			continue
+		}
+
+		delivered++
+		if err = p.store.Notification.DeleteDeadLetter(appID, n.ID); err != nil {
+			return delivered, failed, err
+		}
+	}
+
+	r.Log.Infof("replayed failed notifications for %s: %v delivered, %v failed", appID, delivered, failed)
+	return delivered, failed, nil
+}
+
+// PurgeFailedNotifications deletes the app's dead-lettered notifications. If id
+// is empty, the entire list is purged.
+func (p *Proxy) PurgeFailedNotifications(r *incoming.Request, appID apps.AppID, id string) (int, error) {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return 0, err
+	}
+
+	toPurge, err := p.deadLetters(appID, id)
+	if err != nil {
+		return 0, err
+	}
+	for i, n := range toPurge {
+		if err = p.store.Notification.DeleteDeadLetter(appID, n.ID); err != nil {
+			return i, err
+		}
+	}
+
+	r.Log.Infof("purged %v failed notifications for %s", len(toPurge), appID)
+	return len(toPurge), nil
+}
+
+func (p *Proxy) deadLetters(appID apps.AppID, id string) ([]store.Notification, error) {
+	if id == "" {
+		return p.store.Notification.ListDeadLetters(appID)
+	}
+	n, err := p.store.Notification.GetDeadLetter(appID, id)
+	if err != nil {
+		return nil, err
+	}
+	return []store.Notification{*n}, nil
+}
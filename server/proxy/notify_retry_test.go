@@ -0,0 +1,246 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_upstream"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestNotificationBackoff(t *testing.T) {
+	for attempts, expected := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		8:  time.Hour,
+		20: time.Hour,
+	} {
+		require.Equal(t, expected, notificationBackoff(attempts), "attempts: %v", attempts)
+	}
+}
+
+func TestApplyNotificationData(t *testing.T) {
+	reaction := &model.Reaction{UserId: "user", PostId: "post", EmojiName: "smile"}
+	prev := &model.Channel{Id: "channel", TeamId: "team", DisplayName: "Old name", Header: "header"}
+	n := store.Notification{}
+	n.Context.ExpandedContext.Reaction = reaction
+	n.Context.ExpandedContext.PreviousChannel = prev
+
+	for name, tc := range map[string]struct {
+		expand   *apps.Expand
+		expected *model.Channel
+	}{
+		"no expand": {},
+		"none":      {expand: &apps.Expand{Channel: apps.ExpandAll}},
+		"id": {
+			expand:   &apps.Expand{PreviousChannel: apps.ExpandID},
+			expected: &model.Channel{Id: "channel", TeamId: "team"},
+		},
+		"all": {
+			expand:   &apps.Expand{PreviousChannel: apps.ExpandAll},
+			expected: prev,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			creq := apps.CallRequest{
+				Call: apps.Call{Expand: tc.expand},
+			}
+			err := applyNotificationData(&creq, n)
+			require.NoError(t, err)
+			require.Equal(t, reaction, creq.Context.ExpandedContext.Reaction)
+			require.Equal(t, tc.expected, creq.Context.ExpandedContext.PreviousChannel)
+		})
+	}
+
+	creq := apps.CallRequest{
+		Call: apps.Call{Expand: &apps.Expand{PreviousChannel: "garbage"}},
+	}
+	require.Error(t, applyNotificationData(&creq, n))
+}
+
+type testNotificationStore struct {
+	retries     map[string]store.Notification
+	deadLetters []store.Notification
+}
+
+func (s *testNotificationStore) SaveRetry(n store.Notification) error {
+	s.retries[n.ID] = n
+	return nil
+}
+
+func (s *testNotificationStore) ListDueRetries(appID apps.AppID, now int64) ([]store.Notification, error) {
+	due := []store.Notification{}
+	for _, n := range s.retries {
+		if n.Subscription.AppID == appID && n.NextAttemptAt <= now {
+			due = append(due, n)
+		}
+	}
+	return due, nil
+}
+
+func (s *testNotificationStore) DeleteRetry(_ apps.AppID, id string) error {
+	delete(s.retries, id)
+	return nil
+}
+
+func (s *testNotificationStore) DeleteRetries(appID apps.AppID) error {
+	for id, n := range s.retries {
+		if n.Subscription.AppID == appID {
+			delete(s.retries, id)
+		}
+	}
+	return nil
+}
+
+func (s *testNotificationStore) SaveDeadLetter(n store.Notification) error {
+	for i := range s.deadLetters {
+		if s.deadLetters[i].ID == n.ID {
+			s.deadLetters[i] = n
+			return nil
+		}
+	}
+	s.deadLetters = append(s.deadLetters, n)
+	return nil
+}
+
+func (s *testNotificationStore) GetDeadLetter(appID apps.AppID, id string) (*store.Notification, error) {
+	for _, n := range s.deadLetters {
+		if n.ID == id {
+			return &n, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (s *testNotificationStore) ListDeadLetters(apps.AppID) ([]store.Notification, error) {
+	return s.deadLetters, nil
+}
+
+func (s *testNotificationStore) DeleteDeadLetter(_ apps.AppID, id string) error {
+	modified := []store.Notification{}
+	for _, n := range s.deadLetters {
+		if n.ID != id {
+			modified = append(modified, n)
+		}
+	}
+	s.deadLetters = modified
+	return nil
+}
+
+func TestNotificationRetryDeadLetterReplay(t *testing.T) {
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "app1",
+		},
+		DeployType: apps.DeployBuiltin,
+	}
+
+	ctrl := gomock.NewController(t)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+	appStore.EXPECT().AsMap().Return(map[apps.AppID]apps.App{app.AppID: app}).AnyTimes()
+
+	up := mock_upstream.NewMockUpstream(ctrl)
+	deliveryErr := errors.New("app is down")
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		Return(nil, deliveryErr).
+		Times(NotificationMaxAttempts)
+
+	conf, api := config.NewTestService(&config.Config{})
+	api.On("HasPermissionTo", "admin", model.PermissionManageSystem).Return(true)
+	notifications := &testNotificationStore{retries: map[string]store.Notification{}}
+	p := &Proxy{
+		conf: conf,
+		log:  utils.NewTestLogger(),
+		store: &store.Service{
+			App:          appStore,
+			Notification: notifications,
+		},
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+	}
+	r := p.NewIncomingRequest()
+
+	n := store.Notification{
+		ID:    "notification1",
+		Event: apps.Event{Subject: apps.SubjectUserCreated},
+		Subscription: store.Subscription{
+			Call:        *apps.NewCall("/notify"),
+			AppID:       app.AppID,
+			OwnerUserID: "user1",
+		},
+		CreatedAt: time.Now().UnixMilli(),
+	}
+
+	admin := r.WithActingUserID("admin")
+
+	// The first delivery attempt, made from the queue, fails.
+	require.Error(t, p.deliverNotification(r.WithActingUserID("user1"), n))
+	p.handleFailedNotification(r, n, deliveryErr)
+
+	for attempt := 2; attempt <= NotificationMaxAttempts; attempt++ {
+		require.Contains(t, notifications.retries, n.ID, "attempt %v", attempt)
+		pending := notifications.retries[n.ID]
+		require.Equal(t, attempt-1, pending.Attempts)
+		require.Greater(t, pending.NextAttemptAt, time.Now().UnixMilli())
+		require.Contains(t, pending.LastError, deliveryErr.Error())
+
+		// Not due yet, not retried.
+		p.RetryFailedNotifications()
+
+		// Once due, retried.
+		pending.NextAttemptAt = 0
+		notifications.retries[n.ID] = pending
+		p.RetryFailedNotifications()
+	}
+
+	require.Empty(t, notifications.retries)
+	require.Len(t, notifications.deadLetters, 1)
+	dead := notifications.deadLetters[0]
+	require.Equal(t, n.ID, dead.ID)
+	require.Equal(t, NotificationMaxAttempts, dead.Attempts)
+	require.Zero(t, dead.NextAttemptAt)
+
+	listed, err := p.ListFailedNotifications(admin, app.AppID)
+	require.NoError(t, err)
+	require.Equal(t, []store.Notification{dead}, listed)
+
+	// A failed replay keeps the notification in the dead-letter list.
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		Return(nil, deliveryErr)
+	delivered, failed, err := p.ReplayFailedNotifications(admin, app.AppID, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, delivered)
+	require.Equal(t, 1, failed)
+	require.Len(t, notifications.deadLetters, 1)
+	require.Equal(t, NotificationMaxAttempts+1, notifications.deadLetters[0].Attempts)
+
+	// A successful replay removes it.
+	var received apps.CallRequest
+	up.EXPECT().Roundtrip(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+			received = creq
+			return io.NopCloser(strings.NewReader("{}")), nil
+		})
+	delivered, failed, err = p.ReplayFailedNotifications(admin, app.AppID, n.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, 0, failed)
+	require.Empty(t, notifications.deadLetters)
+	require.Equal(t, "/notify", received.Path)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+// UserChangesInterval is how often the users are checked for changes, to emit
+// the user lifecycle notifications.
+const UserChangesInterval = 2 * time.Minute
+
+const userChangesPageSize = 1000
+
+// userStatesMaxAge is how old the users' state recorded by the previous run
+// can be to still be compared to. Older state, recorded before the job was
+// idle, would report the changes in a burst.
+const userStatesMaxAge = 5 * UserChangesInterval
+
+// userChangeSubjects are the subjects that DetectUserChanges notifies of.
+var userChangeSubjects = []apps.Subject{
+	apps.SubjectUserDeactivated,
+	apps.SubjectUserReactivated,
+	apps.SubjectUserUpdated,
+	apps.SubjectUserRoleChanged,
+}
+
+func newUserState(user *model.User) store.UserState {
+	return store.UserState{
+		UpdateAt: user.UpdateAt,
+		DeleteAt: user.DeleteAt,
+		Roles:    user.Roles,
+	}
+}
+
+// userChangeSubject returns the subject to notify for the change of a user's
+// state, or "" if there is nothing to notify.
+func userChangeSubject(prev, current store.UserState) apps.Subject {
+	switch {
+	case prev.DeleteAt == 0 && current.DeleteAt != 0:
+		return apps.SubjectUserDeactivated
+	case prev.DeleteAt != 0 && current.DeleteAt == 0:
+		return apps.SubjectUserReactivated
+	case prev.Roles != current.Roles:
+		return apps.SubjectUserRoleChanged
+	case prev.UpdateAt != current.UpdateAt:
+		return apps.SubjectUserUpdated
+	default:
+		return ""
+	}
+}
+
+// NotifyUserDeactivated emits "user_deactivated" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyUserDeactivated(userID string) {
+	p.notifyUser(apps.SubjectUserDeactivated, userID)
+}
+
+// NotifyUserReactivated emits "user_reactivated" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyUserReactivated(userID string) {
+	p.notifyUser(apps.SubjectUserReactivated, userID)
+}
+
+// NotifyUserUpdated emits "user_updated" notifications to subscribed apps.
+func (p *Proxy) NotifyUserUpdated(userID string) {
+	p.notifyUser(apps.SubjectUserUpdated, userID)
+}
+
+// NotifyUserRoleChanged emits "user_role_changed" notifications to subscribed
+// apps.
+func (p *Proxy) NotifyUserRoleChanged(userID string) {
+	p.notifyUser(apps.SubjectUserRoleChanged, userID)
+}
+
+func (p *Proxy) notifyUser(subject apps.Subject, userID string) {
+	p.notify(nil,
+		apps.Event{
+			Subject: subject,
+		},
+		apps.UserAgentContext{
+			UserID: userID,
+		},
+	)
+}
+
+// DetectUserChanges compares the current state of all users to the state
+// recorded in the KV store by the previous run, and emits the user lifecycle
+// notifications. The server has no plugin hooks for these events. It is
+// invoked periodically, by a single node in the cluster.
+//
+// Deactivated users, and users whose roles have changed also have their own
+// subscriptions re-validated, see CheckSubscriptionOwners.
+//
+// Nothing is done unless there are subscriptions to the user lifecycle
+// subjects, or users who own subscriptions. The first run after that only
+// records the users' state.
+func (p *Proxy) DetectUserChanges() {
+	notify := p.hasUserChangeSubscriptions()
+	owners := p.store.Subscription.Owners()
+	if !notify && len(owners) == 0 {
+		return
+	}
+
+	mm := p.conf.MattermostAPI()
+	states := map[string]store.UserState{}
+	for page := 0; ; page++ {
+		users, err := mm.User.List(&model.UserGetOptions{
+			Page:    page,
+			PerPage: userChangesPageSize,
+		})
+		if err != nil {
+			p.log.WithError(err).Errorf("DetectUserChanges: failed to list users")
+			return
+		}
+		for _, user := range users {
+			states[user.Id] = newUserState(user)
+		}
+		if len(users) < userChangesPageSize {
+			break
+		}
+	}
+
+	prevStates, err := p.store.UserState.Load(userStatesMaxAge)
+	if err != nil {
+		p.log.WithError(err).Errorf("DetectUserChanges: failed to load the previous state of the users")
+		return
+	}
+	if err = p.store.UserState.Save(states); err != nil {
+		// Do not notify, the same changes would be reported again by the next
+		// run.
+		p.log.WithError(err).Errorf("DetectUserChanges: failed to save the state of the users")
+		return
+	}
+	if prevStates == nil {
+		p.log.Debugf("DetectUserChanges: recorded the state of %v users", len(states))
+		return
+	}
+
+	for userID, current := range states {
+		prev, ok := prevStates[userID]
+		if !ok {
+			// New users are reported by the UserHasBeenCreated hook.
+			continue
+		}
+		subject := userChangeSubject(prev, current)
+		if subject == "" {
+			continue
+		}
+		if notify {
+			p.notifyUser(subject, userID)
+		}
+		if owners[userID] && (subject == apps.SubjectUserDeactivated || subject == apps.SubjectUserRoleChanged) {
+			p.removeInvalidSubscriptions(userID)
+		}
+	}
+}
+
+// hasUserChangeSubscriptions checks the in-memory subscription index for
+// subscriptions to any of the subjects that DetectUserChanges notifies of.
+func (p *Proxy) hasUserChangeSubscriptions() bool {
+	for _, subject := range userChangeSubjects {
+		subs, err := p.store.Subscription.Get(apps.Event{Subject: subject})
+		if err != nil {
+			p.log.WithError(err).Errorf("DetectUserChanges: failed to get %s subscriptions", subject)
+			continue
+		}
+		if len(subs) > 0 {
+			return true
+		}
+	}
+	return false
+}
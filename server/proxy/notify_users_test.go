@@ -0,0 +1,48 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+func TestUserChangeSubject(t *testing.T) {
+	base := store.UserState{UpdateAt: 1, Roles: "system_user"}
+	for name, tc := range map[string]struct {
+		prev, current store.UserState
+		expected      apps.Subject
+	}{
+		"unchanged": {
+			prev:     base,
+			current:  base,
+			expected: "",
+		},
+		"updated": {
+			prev:     base,
+			current:  store.UserState{UpdateAt: 2, Roles: "system_user"},
+			expected: apps.SubjectUserUpdated,
+		},
+		"role changed": {
+			prev:     base,
+			current:  store.UserState{UpdateAt: 2, Roles: "system_user system_admin"},
+			expected: apps.SubjectUserRoleChanged,
+		},
+		"deactivated": {
+			prev:     base,
+			current:  store.UserState{UpdateAt: 2, DeleteAt: 2, Roles: "system_user"},
+			expected: apps.SubjectUserDeactivated,
+		},
+		"reactivated": {
+			prev:     store.UserState{UpdateAt: 2, DeleteAt: 2, Roles: "system_user"},
+			current:  store.UserState{UpdateAt: 3, Roles: "system_user"},
+			expected: apps.SubjectUserReactivated,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, userChangeSubject(tc.prev, tc.current))
+		})
+	}
+}
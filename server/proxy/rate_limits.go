@@ -0,0 +1,77 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// RateLimitError is returned when a call exceeds one of the configured rate
+// limits.
+type RateLimitError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many calls %s, retry in %s", e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+// Cause makes the error map to utils.ErrTooManyRequests, and to HTTP 429.
+func (e *RateLimitError) Cause() error {
+	return utils.ErrTooManyRequests
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return utils.ErrTooManyRequests
+}
+
+// checkCallRateLimits takes a token from each of the configured rate limit
+// buckets for the call, only if none of them is empty. The buckets are shared
+// by the cluster. If the limits can not be checked, the call is allowed.
+func (p *Proxy) checkCallRateLimits(r *incoming.Request, appID apps.AppID) error {
+	limits := p.conf.Get().RateLimits
+	userID := r.ActingUserID()
+
+	names := []string{}
+	buckets := []store.RateLimit{}
+	for _, l := range []struct {
+		name      string
+		key       string
+		perMinute int
+	}{
+		{"for the user with " + string(appID), "au." + string(appID) + "." + userID, limits.PerAppUser},
+		{"for the user", "u." + userID, limits.PerUser},
+		{"for " + string(appID), "a." + string(appID), limits.PerApp},
+	} {
+		if l.perMinute <= 0 {
+			continue
+		}
+		names = append(names, l.name)
+		buckets = append(buckets, store.RateLimit{Key: l.key, PerMinute: l.perMinute})
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	rejected, retryAfter, err := p.store.RateLimit.Take(r, buckets)
+	if err != nil {
+		r.Log.WithError(err).Warnf("failed to check the call rate limits, allowed the call")
+		return nil
+	}
+	if retryAfter > 0 {
+		r.Log.Debugf("call rejected, exceeded the rate limit %s", names[rejected])
+		return &RateLimitError{
+			Limit:      names[rejected],
+			RetryAfter: retryAfter,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+type testRateLimitStore struct {
+	taken map[string]int
+}
+
+func (s *testRateLimitStore) Take(_ *incoming.Request, limits []store.RateLimit) (int, time.Duration, error) {
+	for i, l := range limits {
+		if s.taken[l.Key] >= l.PerMinute {
+			return i, 10 * time.Second, nil
+		}
+	}
+	for _, l := range limits {
+		s.taken[l.Key]++
+	}
+	return 0, 0, nil
+}
+
+func TestCheckCallRateLimits(t *testing.T) {
+	for name, tc := range map[string]struct {
+		limits        config.RateLimitsConfig
+		userID        string
+		appID         apps.AppID
+		expectedLimit string
+	}{
+		"no limits": {
+			userID: "user1",
+			appID:  "app1",
+		},
+		"per app": {
+			limits:        config.RateLimitsConfig{PerApp: 2},
+			userID:        "user2",
+			appID:         "app1",
+			expectedLimit: "for app1",
+		},
+		"per app, other app": {
+			limits: config.RateLimitsConfig{PerApp: 2},
+			userID: "user1",
+			appID:  "app2",
+		},
+		"per user": {
+			limits:        config.RateLimitsConfig{PerUser: 2},
+			userID:        "user1",
+			appID:         "app2",
+			expectedLimit: "for the user",
+		},
+		"per app user": {
+			limits:        config.RateLimitsConfig{PerAppUser: 2, PerUser: 10, PerApp: 10},
+			userID:        "user1",
+			appID:         "app1",
+			expectedLimit: "for the user with app1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			conf := config.NewTestConfigService(&config.Config{RateLimits: tc.limits})
+			p := &Proxy{
+				conf: conf,
+				store: &store.Service{RateLimit: &testRateLimitStore{
+					// user1 already made 2 calls to app1.
+					taken: map[string]int{
+						"au.app1.user1": 2,
+						"u.user1":       2,
+						"a.app1":        2,
+					},
+				}},
+			}
+			r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithActingUserID(tc.userID)
+
+			err := p.checkCallRateLimits(r, tc.appID)
+			if tc.expectedLimit == "" {
+				require.NoError(t, err)
+				return
+			}
+			var rateLimitErr *RateLimitError
+			require.ErrorAs(t, err, &rateLimitErr)
+			require.Equal(t, tc.expectedLimit, rateLimitErr.Limit)
+			require.Equal(t, 10*time.Second, rateLimitErr.RetryAfter)
+			require.ErrorIs(t, err, utils.ErrTooManyRequests)
+			require.Equal(t, http.StatusTooManyRequests, httputils.ErrorToStatus(err))
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// ScheduledCallsInterval is how often the scheduled calls are checked for
+// being due. It is the resolution of the cron expressions.
+const ScheduledCallsInterval = time.Minute
+
+// scheduledCallsConcurrency limits the number of the scheduled calls that are
+// invoked at the same time.
+const scheduledCallsConcurrency = 10
+
+// RunScheduledCalls invokes the scheduled calls that are due. It is invoked
+// periodically, by a single node in the cluster, so each call runs once. The
+// due calls are invoked concurrently, and all of them complete before it
+// returns, so that the next run does not overlap.
+func (p *Proxy) RunScheduledCalls() {
+	now := time.Now()
+	due := []apps.Schedule{}
+	for appID := range p.store.App.AsMap() {
+		schedules, err := p.store.Schedule.List(appID)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to list scheduled calls for %s", appID)
+			continue
+		}
+		for _, s := range schedules {
+			if s.NextRunAt > now.UnixMilli() {
+				// Ordered by NextRunAt, none of the rest are due.
+				break
+			}
+			due = append(due, s)
+		}
+	}
+
+	sem := make(chan struct{}, scheduledCallsConcurrency)
+	wg := sync.WaitGroup{}
+	for _, s := range due {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(s apps.Schedule) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			p.runScheduledCall(s, now)
+		}(s)
+	}
+	wg.Wait()
+}
+
+func (p *Proxy) runScheduledCall(s apps.Schedule, now time.Time) {
+	r := p.NewIncomingRequest()
+	r.Log = r.Log.With("app_id", s.AppID, "schedule_id", s.ID)
+
+	app, err := p.GetInstalledApp(s.AppID, false)
+	switch {
+	case errors.Is(err, utils.ErrNotFound):
+		r.Log.Debugf("app is no longer installed, removed its scheduled call")
+		_ = p.store.Schedule.DeleteAll(s.AppID)
+		return
+	case err != nil:
+		r.Log.WithError(err).Errorf("failed to load the app for a scheduled call")
+		return
+	}
+
+	s.LastError = ""
+	if app.Disabled {
+		// Skip the runs while the app is disabled.
+		s.LastError = "skipped, the app is disabled"
+	} else {
+		err = p.invokeScheduledCall(r, app, s)
+		if err != nil {
+			r.Log.WithError(err).Warnf("scheduled call failed")
+			s.LastError = err.Error()
+		}
+	}
+	s.LastRunAt = now.UnixMilli()
+
+	next := s.Next(now)
+	if next.IsZero() {
+		err = p.store.Schedule.Delete(s.AppID, s.ID)
+	} else {
+		s.NextRunAt = next.UnixMilli()
+		err = p.store.Schedule.Update(s)
+	}
+	switch {
+	case errors.Is(err, utils.ErrNotFound):
+		r.Log.Debugf("scheduled call was canceled while it ran")
+	case err != nil:
+		r.Log.WithError(err).Errorf("failed to update a scheduled call")
+	}
+}
+
+func (p *Proxy) invokeScheduledCall(r *incoming.Request, app *apps.App, s apps.Schedule) error {
+	if app.BotUserID == "" {
+		return errors.Errorf("%s has no bot account", app.AppID)
+	}
+	r = r.WithActingUserID(app.BotUserID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.callTimeout(app, &s.Call, config.DefaultCallTimeout))
+	defer cancel()
+	r = r.WithCtx(ctx)
+
+	cresp := p.call(r, app, s.Call, nil,
+		"schedule_id", s.ID,
+		"scheduled_at", s.NextRunAt)
+	if cresp.Type == apps.CallResponseTypeError {
+		return cresp
+	}
+	return nil
+}
+
+// ListSchedules returns the app's scheduled calls, or the scheduled calls of
+// all installed apps if appID is empty.
+func (p *Proxy) ListSchedules(r *incoming.Request, appID apps.AppID) ([]apps.Schedule, error) {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	if appID != "" {
+		return p.store.Schedule.List(appID)
+	}
+
+	all := []apps.Schedule{}
+	for id := range p.store.App.AsMap() {
+		schedules, err := p.store.Schedule.List(id)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, schedules...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].NextRunAt < all[j].NextRunAt
+	})
+	return all, nil
+}
+
+// CancelSchedule deletes an app's scheduled call.
+func (p *Proxy) CancelSchedule(r *incoming.Request, appID apps.AppID, id string) error {
+	if err := r.Check(
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return err
+	}
+	if err := p.store.Schedule.Delete(appID, id); err != nil {
+		return err
+	}
+	r.Log.Infof("canceled scheduled call %s for %s", id, appID)
+	return nil
+}
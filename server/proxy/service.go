@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-api/cluster"
+	"github.com/mattermost/mattermost-server/v6/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/apps/appclient"
@@ -18,6 +19,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/metrics"
 	"github.com/mattermost/mattermost-plugin-apps/server/mmclient"
 	"github.com/mattermost/mattermost-plugin-apps/server/session"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
@@ -27,6 +29,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/upstream/upopenfaas"
 	"github.com/mattermost/mattermost-plugin-apps/upstream/upplugin"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type Proxy struct {
@@ -40,6 +43,21 @@ type Proxy struct {
 	upstreams      sync.Map // key: apps.AppID, value upstream.Upstream
 	sessionService session.Service
 	appservices    appservices.Service
+	notifications  *notificationQueues
+	metrics        *metrics.Metrics
+
+	// recordedCalls are the recent calls to the apps, recorded in developer
+	// mode.
+	recordedCalls callRecorder
+
+	// callJobs runs the async calls.
+	callJobs callJobRunner
+
+	// auditLog stores the audit entries in the background.
+	auditLog auditWriter
+
+	// breakers guard the calls to the apps, see guardedUpstream.
+	breakers sync.Map // key: apps.AppID, value: *appBreaker
 
 	// expandClientOverride is set by the tests to use the mock client
 	expandClientOverride mmclient.Client
@@ -56,6 +74,18 @@ type Admin interface {
 	InstallApp(_ *incoming.Request, _ apps.Context, _ apps.AppID, _ apps.DeployType, trustedApp bool, secret string) (*apps.App, string, error)
 	UpdateAppListing(*incoming.Request, appclient.UpdateAppListingRequest) (*apps.Manifest, error)
 	UninstallApp(*incoming.Request, apps.Context, apps.AppID, bool) (string, error)
+
+	ListFailedNotifications(*incoming.Request, apps.AppID) ([]store.Notification, error)
+	ReplayFailedNotifications(_ *incoming.Request, _ apps.AppID, id string) (delivered, failed int, err error)
+	PurgeFailedNotifications(_ *incoming.Request, _ apps.AppID, id string) (int, error)
+
+	ListSchedules(*incoming.Request, apps.AppID) ([]apps.Schedule, error)
+	CancelSchedule(_ *incoming.Request, _ apps.AppID, id string) error
+
+	ListAudit(*incoming.Request, store.AuditFilter) ([]store.AuditEntry, error)
+
+	ListRecordedCalls(*incoming.Request, apps.AppID) ([]RecordedCall, error)
+	ReplayRecordedCall(_ *incoming.Request, _ apps.AppID, id string) (*CallReplay, error)
 }
 
 // API implements user-level operations, usually invoked from httpin handlers.
@@ -65,6 +95,8 @@ type API interface {
 	// REST API methods used by user agents (mobile, desktop, web).
 	GetApp(*incoming.Request) (*apps.App, error)
 	GetBindings(*incoming.Request, apps.Context) ([]apps.Binding, error)
+	GetCallJob(_ *incoming.Request, id string) (*apps.CallJob, error)
+	InvokeAppCall(*incoming.Request, apps.CallRequest) CallResponse
 	InvokeCall(*incoming.Request, apps.CallRequest) CallResponse
 	InvokeCompleteRemoteOAuth2(_ *incoming.Request, urlValues map[string]interface{}) error
 	InvokeGetBindings(*incoming.Request, apps.Context) ([]apps.Binding, error)
@@ -77,11 +109,22 @@ type API interface {
 // multiple apps. Notify functions create their own app requests.
 type Notifier interface {
 	NotifyUserCreated(userID string)
+	NotifyUserDeactivated(userID string)
+	NotifyUserReactivated(userID string)
+	NotifyUserUpdated(userID string)
+	NotifyUserRoleChanged(userID string)
 	NotifyUserJoinedChannel(channelID, userID string)
 	NotifyUserLeftChannel(channelID, userID string)
 	NotifyUserJoinedTeam(teamID, userID string)
 	NotifyUserLeftTeam(teamID, userID string)
 	NotifyChannelCreated(teamID, channelID string)
+	NotifyChannelArchived(channel *model.Channel, userID string)
+	NotifyChannelRestored(channel *model.Channel, userID string)
+	NotifyChannelUpdated(before, after *model.Channel, userID string)
+	NotifyChannelConverted(before, after *model.Channel, userID string)
+	NotifyMessageHasBeenPosted(post *model.Post)
+	NotifyReactionHasBeenAdded(reaction *model.Reaction)
+	NotifyReactionHasBeenRemoved(reaction *model.Reaction)
 }
 
 // Internal implements go API used by other plugin-apps packages. When relevant,
@@ -90,9 +133,25 @@ type Notifier interface {
 type Internal interface {
 	AddBuiltinUpstream(apps.AppID, upstream.Upstream)
 	CanDeploy(apps.DeployType) (allowed, usable bool)
+	CheckSubscriptionOwners()
+	CircuitBreakerState(apps.AppID) CircuitState
+	DetectUserChanges()
 	NewIncomingRequest() *incoming.Request
+	NotificationQueueStats() []NotificationQueueStats
+	PruneAudit()
+	RecordPostBindingPaths(*model.Post)
+	RetryFailedNotifications()
+	RunScheduledCalls()
 	SynchronizeInstalledApps() error
 
+	// WriteMetrics outputs the proxy's metrics in the Prometheus text format.
+	WriteMetrics(io.Writer) error
+
+	// Close stops the notification delivery, the queued notifications are
+	// stored for a later retry. The running async calls are canceled, and
+	// their results stored. The pending audit entries are stored.
+	Close()
+
 	GetInstalledApp(_ apps.AppID, checkEnabled bool) (*apps.App, error)
 	GetInstalledApps() []apps.App
 	PingInstalledApps(context.Context) (installed []apps.App, reachable map[apps.AppID]bool)
@@ -113,7 +172,7 @@ type Service interface {
 var _ Service = (*Proxy)(nil)
 
 func NewService(conf config.Service, store *store.Service, mutex *cluster.Mutex, httpOut httpout.Service, session session.Service, appservices appservices.Service, log utils.Logger) *Proxy {
-	return &Proxy{
+	p := &Proxy{
 		builtinUpstreams: map[apps.AppID]upstream.Upstream{},
 		conf:             conf,
 		store:            store,
@@ -121,8 +180,11 @@ func NewService(conf config.Service, store *store.Service, mutex *cluster.Mutex,
 		httpOut:          httpOut,
 		sessionService:   session,
 		appservices:      appservices,
+		metrics:          metrics.New(),
 		log:              log,
 	}
+	p.notifications = newNotificationQueues(p.deliverQueuedNotification, p.shedNotification)
+	return p
 }
 
 func (p *Proxy) Configure(conf config.Config, log utils.Logger) error {
@@ -140,9 +202,23 @@ func (p *Proxy) Configure(conf config.Config, log utils.Logger) error {
 	p.initUpstream(apps.DeployOpenFAAS, conf, log, func() (upstream.Upstream, error) {
 		return upopenfaas.MakeUpstream(p.httpOut, conf.DeveloperMode)
 	})
+
+	p.notifications.configure(conf.Notifications)
+
+	if err := tracing.Configure(conf.TracingOTLPEndpoint); err != nil {
+		log.WithError(err).Warnf("failed to configure tracing, disabled")
+		tracing.Shutdown()
+	}
 	return nil
 }
 
+func (p *Proxy) Close() {
+	p.callJobs.close()
+	p.notifications.close()
+	p.auditLog.close()
+	tracing.Shutdown()
+}
+
 // CanDeploy returns the availability of deployType. allowed indicates that the
 // type can be used in the current configuration. usable indicates that it is
 // configured and can be accessed, or deployed to.
@@ -218,7 +294,10 @@ func (p *Proxy) upstreamForApp(app *apps.App) (upstream.Upstream, error) {
 	if !ok {
 		return nil, utils.NewInvalidError("invalid Upstream for: %s", app.DeployType)
 	}
-	return up, nil
+	return guardedUpstream{
+		Upstream: up,
+		breaker:  p.appBreaker(app.AppID),
+	}, nil
 }
 
 func (p *Proxy) initUpstream(typ apps.DeployType, newConfig config.Config, log utils.Logger, makef func() (upstream.Upstream, error)) {
@@ -0,0 +1,66 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+// SubscriptionOwnersInterval is how often the owners of all subscriptions are
+// re-validated.
+const SubscriptionOwnersInterval = time.Hour
+
+// CheckSubscriptionOwners removes the subscriptions whose owners have been
+// deactivated, or have lost the permission to subscribe to the event. It is
+// invoked periodically, by a single node in the cluster.
+func (p *Proxy) CheckSubscriptionOwners() {
+	p.removeInvalidSubscriptions("")
+}
+
+// removeInvalidSubscriptions re-validates the subscriptions owned by userID,
+// or all subscriptions if userID is empty, and notifies the apps of the ones
+// removed.
+func (p *Proxy) removeInvalidSubscriptions(userID string) {
+	r := p.NewIncomingRequest().WithSourcePluginID(p.conf.Get().PluginManifest.Id)
+
+	allApps := p.store.App.AsMap()
+	removed, err := p.appservices.RemoveInvalidSubscriptions(r, func(sub store.Subscription) bool {
+		if userID != "" && sub.OwnerUserID != userID {
+			return false
+		}
+		// Disabling an app deactivates its bot, leave the subscriptions of
+		// disabled apps until they are re-enabled.
+		app, ok := allApps[sub.AppID]
+		return ok && !app.Disabled
+	})
+	if err != nil {
+		r.Log.WithError(err).Errorf("failed to remove invalid subscriptions")
+	}
+
+	for _, rs := range removed {
+		log := r.Log.With(rs.Event, "app_id", rs.AppID, "owner_user_id", rs.OwnerUserID)
+		log.Infof("removed subscription: %s", rs.Reason)
+
+		app, ok := allApps[rs.AppID]
+		if !ok || app.OnSubscriptionRemoved == nil {
+			continue
+		}
+		cc := &apps.Context{
+			UserAgentContext: apps.UserAgentContext{
+				TeamID:    rs.TeamID,
+				ChannelID: rs.ChannelID,
+			},
+		}
+		cresp := p.call(r, &app, *app.OnSubscriptionRemoved, cc,
+			"subscription", rs.Subscription,
+			"owner_user_id", rs.OwnerUserID,
+			"reason", rs.Reason)
+		if cresp.Type == apps.CallResponseTypeError {
+			log.WithError(cresp).Warnf("on_subscription_removed call failed")
+		}
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
-	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
@@ -21,7 +20,7 @@ const PrevVersion = "prev_version"
 // SynchronizeInstalledApps synchronizes installed apps with known manifests,
 // performing OnVersionChanged call on the App as needed.
 func (p *Proxy) SynchronizeInstalledApps() error {
-	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), p.conf.Get().MaxCallTimeout)
 	defer cancel()
 
 	mm := p.conf.MattermostAPI()
@@ -47,25 +46,37 @@ func (p *Proxy) SynchronizeInstalledApps() error {
 		m := listed[app.AppID]
 
 		// Store the new manifest to update the current mappings of the App
+		prevSubscriptions := app.Subscriptions
 		app.Manifest = m
 		err := p.store.App.Save(r, app)
 		if err != nil {
 			return err
 		}
 
-		// Call OnVersionChanged the function of the app. It should be called only once
-		if app.OnVersionChanged != nil {
-			err := p.callOnce(func() error {
+		if app.OnVersionChanged == nil && len(prevSubscriptions)+len(app.Subscriptions) == 0 {
+			continue
+		}
+
+		// Reconcile the subscriptions, and call the OnVersionChanged function
+		// of the app. It should be done only once
+		err = p.callOnce(func() error {
+			err := p.reconcileManifestSubscriptions(r, &app, prevSubscriptions)
+			if err != nil {
+				r.Log.WithError(err).Warnw("failed to reconcile manifest subscriptions",
+					"app_id", app.AppID)
+			}
+
+			if app.OnVersionChanged != nil {
 				resp := p.call(r, &app, *app.OnVersionChanged, nil, PrevVersion, app.Version)
 				if resp.Type == apps.CallResponseTypeError {
 					return errors.Wrapf(resp, "call %s failed", app.OnVersionChanged.Path)
 				}
-				return nil
-			})
-			if err != nil {
-				r.Log.WithError(err).Errorw("failed in callOnce:OnVersionChanged",
-					"app_id", app.AppID)
 			}
+			return nil
+		})
+		if err != nil {
+			r.Log.WithError(err).Errorw("failed in callOnce:OnVersionChanged",
+				"app_id", app.AppID)
 		}
 	}
 
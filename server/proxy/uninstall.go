@@ -10,9 +10,14 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
 )
 
-func (p *Proxy) UninstallApp(r *incoming.Request, cc apps.Context, appID apps.AppID, force bool) (string, error) {
+func (p *Proxy) UninstallApp(r *incoming.Request, cc apps.Context, appID apps.AppID, force bool) (_ string, err error) {
+	defer func() {
+		p.auditAction(r, store.AuditEventUninstall, appID, err)
+	}()
+
 	if err := r.Check(
 		r.RequireActingUser,
 		r.RequireSysadminOrPlugin,
@@ -73,6 +78,21 @@ func (p *Proxy) UninstallApp(r *incoming.Request, cc apps.Context, appID apps.Ap
 		return "", errors.Wrapf(err, "failed to clear subscriptions for %s, the app is left disabled", appID)
 	}
 
+	if _, err = p.PurgeFailedNotifications(r, appID, ""); err != nil {
+		return "", errors.Wrapf(err, "failed to clear failed notifications for %s, the app is left disabled", appID)
+	}
+	if err = p.store.Notification.DeleteRetries(appID); err != nil {
+		return "", errors.Wrapf(err, "failed to clear notifications pending a retry for %s, the app is left disabled", appID)
+	}
+
+	if err = p.store.Schedule.DeleteAll(appID); err != nil {
+		return "", errors.Wrapf(err, "failed to clear scheduled calls for %s, the app is left disabled", appID)
+	}
+
+	if err = p.store.UserCallPath.Delete(appID); err != nil {
+		return "", errors.Wrapf(err, "failed to clear user call paths for %s, the app is left disabled", appID)
+	}
+
 	// Delete the main record of the app.
 	if err = p.store.App.Delete(r, app.AppID); err != nil {
 		return "", errors.Wrapf(err, "can't delete app %s, the app is left disabled", appID)
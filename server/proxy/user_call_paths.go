@@ -0,0 +1,129 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// checkUserCallPath rejects the calls made by the users to the paths that
+// are neither declared in the app's manifest, nor have been served to the
+// user in the app's bindings and forms. It only applies to the apps that
+// opted in with RestrictUserCalls.
+func (p *Proxy) checkUserCallPath(r *incoming.Request, app *apps.App, callPath string) error {
+	if !app.RestrictUserCalls || app.AllowsUserCallPath(callPath) {
+		return nil
+	}
+	served, err := p.store.UserCallPath.Has(app.AppID, r.ActingUserID(), callPath)
+	if err != nil {
+		return err
+	}
+	if !served {
+		r.Log.Debugf("rejected call to %s, not a user call path", callPath)
+		return utils.NewForbiddenError("%s may not be called by users of %s", callPath, app.AppID)
+	}
+	return nil
+}
+
+// recordUserCallPaths records the call paths of the bindings and forms served
+// to the acting user, so that the user can invoke them.
+func (p *Proxy) recordUserCallPaths(r *incoming.Request, app *apps.App, bindings []apps.Binding, forms ...*apps.Form) {
+	if r.ActingUserID() == "" {
+		return
+	}
+	p.addUserCallPaths(r, app, r.ActingUserID(), bindings, forms...)
+}
+
+// addUserCallPaths records the call paths for the user, or for all users if
+// userID is empty.
+func (p *Proxy) addUserCallPaths(r *incoming.Request, app *apps.App, userID string, bindings []apps.Binding, forms ...*apps.Form) {
+	if !app.RestrictUserCalls {
+		return
+	}
+	paths := map[string]bool{}
+	addBindingCallPaths(paths, bindings)
+	for _, f := range forms {
+		addFormCallPaths(paths, f)
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	var list []string
+	for callPath := range paths {
+		list = append(list, callPath)
+	}
+	if err := p.store.UserCallPath.Add(app.AppID, userID, list); err != nil {
+		r.Log.WithError(err).Warnf("failed to record the user call paths")
+	}
+}
+
+// RecordPostBindingPaths records the call paths of the in-post bindings for
+// all users, if the post is created or updated by an app's bot.
+func (p *Proxy) RecordPostBindingPaths(post *model.Post) {
+	prop := post.GetProp(apps.PropAppBindings)
+	if prop == nil {
+		return
+	}
+
+	var app *apps.App
+	for _, installed := range p.store.App.AsMap() {
+		if installed.BotUserID != "" && installed.BotUserID == post.UserId {
+			app = &installed
+			break
+		}
+	}
+	if app == nil {
+		// Only the apps may create in-post bindings that can be invoked.
+		return
+	}
+
+	r := p.NewIncomingRequest().WithDestination(app.AppID)
+	bindings := []apps.Binding{}
+	data, err := json.Marshal(prop)
+	if err == nil {
+		err = json.Unmarshal(data, &bindings)
+	}
+	if err != nil {
+		r.Log.WithError(err).Debugf("failed to decode in-post bindings")
+		return
+	}
+	p.addUserCallPaths(r, app, "", bindings)
+}
+
+func addBindingCallPaths(paths map[string]bool, bindings []apps.Binding) {
+	for _, b := range bindings {
+		addCallPath(paths, b.Submit)
+		addFormCallPaths(paths, b.Form)
+		addBindingCallPaths(paths, b.Bindings)
+	}
+}
+
+func addFormCallPaths(paths map[string]bool, f *apps.Form) {
+	if f == nil {
+		return
+	}
+	addCallPath(paths, f.Source)
+	addCallPath(paths, f.Submit)
+	for _, field := range f.Fields {
+		addCallPath(paths, field.SelectDynamicLookup)
+	}
+}
+
+func addCallPath(paths map[string]bool, call *apps.Call) {
+	if call == nil {
+		return
+	}
+	cleanPath, err := cleanCallPath(call.Path)
+	if err != nil {
+		return
+	}
+	paths[cleanPath] = true
+}
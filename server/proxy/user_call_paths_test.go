@@ -0,0 +1,153 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testUserCallPathStore struct {
+	// paths are keyed by the app ID, then by the user ID, "" for all users.
+	paths map[apps.AppID]map[string]map[string]bool
+}
+
+func (s *testUserCallPathStore) Has(appID apps.AppID, userID, path string) (bool, error) {
+	return s.paths[appID][userID][path] || s.paths[appID][""][path], nil
+}
+
+func (s *testUserCallPathStore) Add(appID apps.AppID, userID string, paths []string) error {
+	if s.paths[appID] == nil {
+		s.paths[appID] = map[string]map[string]bool{}
+	}
+	if s.paths[appID][userID] == nil {
+		s.paths[appID][userID] = map[string]bool{}
+	}
+	for _, path := range paths {
+		s.paths[appID][userID][path] = true
+	}
+	return nil
+}
+
+func (s *testUserCallPathStore) Delete(appID apps.AppID) error {
+	delete(s.paths, appID)
+	return nil
+}
+
+func (s *testUserCallPathStore) Reload(apps.AppID) {}
+
+func TestUserCallPaths(t *testing.T) {
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID:             "test",
+			RestrictUserCalls: true,
+			UserCallPaths:     []string{"/declared", "/admin/*"},
+		},
+		BotUserID: "bot-user-id",
+	}
+
+	ctrl := gomock.NewController(t)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().AsMap().Return(map[apps.AppID]apps.App{app.AppID: app}).AnyTimes()
+
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{
+		conf: conf,
+		log:  utils.NewTestLogger(),
+		store: &store.Service{
+			App:          appStore,
+			UserCallPath: &testUserCallPathStore{paths: map[apps.AppID]map[string]map[string]bool{}},
+		},
+	}
+	r := incoming.NewRequest(conf, utils.NewTestLogger(), nil).WithActingUserID("user-id")
+
+	p.recordUserCallPaths(r, &app, []apps.Binding{
+		{
+			Location: apps.LocationCommand,
+			Bindings: []apps.Binding{
+				{
+					Location: "submit",
+					Submit:   apps.NewCall("/binding/submit"),
+				},
+				{
+					Location: "form",
+					Form: &apps.Form{
+						Source: apps.NewCall("/binding/../form/source"),
+					},
+				},
+			},
+		},
+	})
+	p.recordUserCallPaths(r, &app, nil, &apps.Form{
+		Submit: apps.NewCall("/form/submit"),
+		Fields: []apps.Field{
+			{
+				Name:                "select",
+				Type:                apps.FieldTypeDynamicSelect,
+				SelectDynamicLookup: apps.NewCall("/form/lookup"),
+			},
+		},
+	})
+
+	postBinding := []apps.Binding{
+		{
+			AppID:    app.AppID,
+			Location: "embedded",
+			Bindings: []apps.Binding{
+				{
+					Location: "button",
+					Submit:   apps.NewCall("/post/button"),
+				},
+			},
+		},
+	}
+	userPost := &model.Post{UserId: "user-id"}
+	userPost.AddProp(apps.PropAppBindings, []apps.Binding{{Submit: apps.NewCall("/on_install")}})
+	p.RecordPostBindingPaths(userPost)
+	botPost := &model.Post{UserId: app.BotUserID}
+	botPost.AddProp(apps.PropAppBindings, postBinding)
+	p.RecordPostBindingPaths(botPost)
+
+	for callPath, allowed := range map[string]bool{
+		"/binding/submit": true,
+		"/form/source":    true,
+		"/form/submit":    true,
+		"/form/lookup":    true,
+		"/post/button":    true,
+		"/declared":       true,
+		"/admin/settings": true,
+		"/admin":          false,
+		"/on_install":     false,
+		"/webhook/hook":   false,
+	} {
+		t.Run(callPath, func(t *testing.T) {
+			err := p.checkUserCallPath(r, &app, callPath)
+			if allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, utils.ErrForbidden)
+			}
+		})
+	}
+
+	t.Run("other user", func(t *testing.T) {
+		other := r.WithActingUserID("other-user-id")
+		require.NoError(t, p.checkUserCallPath(other, &app, "/post/button"))
+		require.ErrorIs(t, p.checkUserCallPath(other, &app, "/binding/submit"), utils.ErrForbidden)
+	})
+
+	t.Run("not restricted", func(t *testing.T) {
+		unrestricted := app
+		unrestricted.RestrictUserCalls = false
+		require.NoError(t, p.checkUserCallPath(r, &unrestricted, "/webhook/hook"))
+	})
+}
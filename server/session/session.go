@@ -12,6 +12,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/sessionutils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 const (
@@ -40,8 +41,16 @@ func NewService(mm *pluginapi.Client, store *store.Service) Service {
 	}
 }
 
-func (s *service) GetOrCreate(r *incoming.Request, userID string) (*model.Session, error) {
+func (s *service) GetOrCreate(r *incoming.Request, userID string) (_ *model.Session, err error) {
 	appID := r.Destination()
+	ctx, span := tracing.Start(r.Ctx(), "apps.session.get_or_create",
+		tracing.Attr("app_id", string(appID)))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	r = r.WithCtx(ctx)
+
 	session, err := s.store.Session.Get(appID, userID)
 
 	if err == nil && !session.IsExpired() {
@@ -0,0 +1,422 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+type AuditEvent string
+
+const (
+	AuditEventCall          = AuditEvent("call")
+	AuditEventInstall       = AuditEvent("install")
+	AuditEventUninstall     = AuditEvent("uninstall")
+	AuditEventEnable        = AuditEvent("enable")
+	AuditEventDisable       = AuditEvent("disable")
+	AuditEventOAuth2Connect = AuditEvent("oauth2_connect")
+)
+
+// AuditEntry records a call to an app, or an app lifecycle action, and who
+// made it.
+type AuditEntry struct {
+	// ID is the unique ID of the entry, assigned by the store.
+	ID       string     `json:"id"`
+	CreateAt int64      `json:"create_at"`
+	Event    AuditEvent `json:"event"`
+	AppID    apps.AppID `json:"app_id"`
+	UserID   string     `json:"user_id,omitempty"`
+
+	// CallerAppID is set for the calls made by another app.
+	CallerAppID apps.AppID `json:"caller_app_id,omitempty"`
+	Path        string     `json:"path,omitempty"`
+
+	// Result is the call response type, or "ok" or "error" for the other
+	// events. Error is the error message, if any.
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AuditFilter selects the audit entries to list. Empty fields match all
+// entries; Since and Until are inclusive, in Unix milliseconds.
+type AuditFilter struct {
+	AppID  apps.AppID
+	UserID string
+	Since  int64
+	Until  int64
+
+	// Limit is the maximum number of entries returned, the newest first.
+	Limit int
+}
+
+func (f AuditFilter) matches(k auditKey) bool {
+	return (f.AppID == "" || k.appID == f.AppID) &&
+		(f.UserID == "" || k.userID == f.UserID) &&
+		(f.Since == 0 || k.createAt >= f.Since) &&
+		(f.Until == 0 || k.createAt <= f.Until)
+}
+
+// AuditStore is the audit log, shared by all nodes in the cluster. Each entry
+// is stored under its own key, and the keys are indexed by the hour the
+// entries were created in, so that listing and pruning only read the indexes
+// of the hours they need, not all of the plugin's keys. The keys include the
+// fields the entries are filtered by, so that listing only reads the entries
+// it returns. The entries expire after Config.AuditLog.Retention.
+//
+// The audit log is kept in the plugin's KV store, and is listed through the
+// plugin. The entries are not written to the server's audit log, the plugin
+// API does not provide access to it.
+type AuditStore interface {
+	// Append stores the entry with a new ID. It does nothing if the audit log
+	// is disabled.
+	Append(AuditEntry) error
+
+	// List returns the entries that match the filter, the newest first. At
+	// most Config.AuditLog.MaxEntries are returned.
+	List(AuditFilter) ([]AuditEntry, error)
+
+	// Prune deletes the oldest entries past Config.AuditLog.MaxEntries, and
+	// drops the indexes of the expired ones. It returns the number of entries
+	// deleted.
+	Prune() (int, error)
+}
+
+// auditBucketSize is the time span of the entries indexed together.
+const auditBucketSize = time.Hour
+
+// auditCASAttempts limits the number of attempts to update the indexes
+// concurrently updated by other nodes.
+const auditCASAttempts = 5
+
+type auditStore struct {
+	*Service
+
+	// lastBucket is the most recent bucket this node has added to the list of
+	// the buckets, it is accessed atomically.
+	lastBucket int64
+	now        func() time.Time
+}
+
+var _ AuditStore = (*auditStore)(nil)
+
+func makeAuditStore(s *Service) *auditStore {
+	return &auditStore{
+		Service: s,
+		now:     time.Now,
+	}
+}
+
+// auditKey is the parsed key of an audit entry:
+// "aud.<create_at>.<user_id>.<id>.<app_id>". The creation time is zero-padded
+// so that the keys sort in the order of the entries. The app ID goes last, it
+// may contain '.'.
+type auditKey struct {
+	key      string
+	createAt int64
+	userID   string
+	appID    apps.AppID
+}
+
+func makeAuditKey(e AuditEntry) string {
+	return fmt.Sprintf("%s%013d.%s.%s.%s", KVAuditPrefix, e.CreateAt, e.UserID, e.ID, e.AppID)
+}
+
+func parseAuditKey(key string) (auditKey, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(key, KVAuditPrefix), ".", 4)
+	if len(parts) != 4 {
+		return auditKey{}, false
+	}
+	createAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return auditKey{}, false
+	}
+	return auditKey{
+		key:      key,
+		createAt: createAt,
+		userID:   parts[1],
+		appID:    apps.AppID(parts[3]),
+	}, true
+}
+
+// auditBucket returns the start of the bucket of the entries created at
+// createAt, both in Unix milliseconds.
+func auditBucket(createAt int64) int64 {
+	return createAt - createAt%auditBucketSize.Milliseconds()
+}
+
+func auditIndexKey(bucket int64) string {
+	return fmt.Sprintf("%s%013d", KVAuditIndexPrefix, bucket)
+}
+
+func (s *auditStore) Append(e AuditEntry) error {
+	conf := s.conf.Get().AuditLog
+	if conf.MaxEntries <= 0 {
+		return nil
+	}
+	e.ID = model.NewId()
+	key := makeAuditKey(e)
+	_, err := s.conf.MattermostAPI().KV.Set(key, e, pluginapi.SetExpiry(conf.Retention))
+	if err != nil {
+		return err
+	}
+
+	bucket := auditBucket(e.CreateAt)
+	err = s.updateIndex(bucket, func(keys []string) []string {
+		return append(keys, key)
+	})
+	if err != nil {
+		return err
+	}
+	if atomic.LoadInt64(&s.lastBucket) == bucket {
+		return nil
+	}
+	err = s.updateBuckets(func(buckets []int64) []int64 {
+		for _, b := range buckets {
+			if b == bucket {
+				return buckets
+			}
+		}
+		buckets = append(buckets, bucket)
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+		return buckets
+	})
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt64(&s.lastBucket, bucket)
+	return nil
+}
+
+func (s *auditStore) List(f AuditFilter) ([]AuditEntry, error) {
+	conf := s.conf.Get().AuditLog
+	if conf.MaxEntries <= 0 {
+		return []AuditEntry{}, nil
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > conf.MaxEntries {
+		limit = conf.MaxEntries
+	}
+
+	buckets, err := s.loadBuckets()
+	if err != nil {
+		return nil, err
+	}
+
+	mm := s.conf.MattermostAPI()
+	out := []AuditEntry{}
+	expireBefore := s.now().Add(-conf.Retention).UnixMilli()
+	for i := len(buckets) - 1; i >= 0; i-- {
+		bucketEnd := buckets[i] + auditBucketSize.Milliseconds()
+		if bucketEnd <= expireBefore || (f.Since != 0 && bucketEnd <= f.Since) {
+			break
+		}
+		if f.Until != 0 && buckets[i] > f.Until {
+			continue
+		}
+
+		keys, err := s.loadIndex(buckets[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if !f.matches(k) {
+				continue
+			}
+			e := AuditEntry{}
+			if err = mm.KV.Get(k.key, &e); err != nil {
+				return nil, err
+			}
+			if e.ID == "" {
+				// Expired, or pruned since indexed.
+				continue
+			}
+			out = append(out, e)
+			if len(out) >= limit {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *auditStore) Prune() (int, error) {
+	conf := s.conf.Get().AuditLog
+	if conf.MaxEntries <= 0 {
+		return 0, nil
+	}
+	buckets, err := s.loadBuckets()
+	if err != nil {
+		return 0, err
+	}
+
+	mm := s.conf.MattermostAPI()
+	dropped := map[int64]bool{}
+	kept, pruned := 0, 0
+	expireBefore := s.now().Add(-conf.Retention).UnixMilli()
+	for i := len(buckets) - 1; i >= 0; i-- {
+		bucket := buckets[i]
+		if bucket+auditBucketSize.Milliseconds() <= expireBefore {
+			// The index expires with its entries.
+			dropped[bucket] = true
+			continue
+		}
+
+		keys, err := s.loadIndex(bucket)
+		if err != nil {
+			return pruned, err
+		}
+		if kept+len(keys) <= conf.MaxEntries {
+			kept += len(keys)
+			continue
+		}
+
+		deleted := map[string]bool{}
+		for _, k := range keys[conf.MaxEntries-kept:] {
+			if err = mm.KV.Delete(k.key); err != nil {
+				return pruned, err
+			}
+			deleted[k.key] = true
+			pruned++
+		}
+		kept = conf.MaxEntries
+		err = s.updateIndex(bucket, func(keys []string) []string {
+			remaining := []string{}
+			for _, key := range keys {
+				if !deleted[key] {
+					remaining = append(remaining, key)
+				}
+			}
+			return remaining
+		})
+		if err != nil {
+			return pruned, err
+		}
+		if len(deleted) == len(keys) {
+			dropped[bucket] = true
+		}
+	}
+
+	if len(dropped) == 0 {
+		return pruned, nil
+	}
+	err = s.updateBuckets(func(buckets []int64) []int64 {
+		remaining := []int64{}
+		for _, b := range buckets {
+			if !dropped[b] {
+				remaining = append(remaining, b)
+			}
+		}
+		return remaining
+	})
+	return pruned, err
+}
+
+// loadIndex returns the parsed keys of the entries in a bucket, the newest
+// first.
+func (s *auditStore) loadIndex(bucket int64) ([]auditKey, error) {
+	keys := []string{}
+	if err := s.conf.MattermostAPI().KV.Get(auditIndexKey(bucket), &keys); err != nil {
+		return nil, err
+	}
+	out := []auditKey{}
+	for _, key := range keys {
+		if k, ok := parseAuditKey(key); ok {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].key > out[j].key
+	})
+	return out, nil
+}
+
+// loadBuckets returns the buckets that have entries indexed, the oldest first.
+func (s *auditStore) loadBuckets() ([]int64, error) {
+	buckets := []int64{}
+	if err := s.conf.MattermostAPI().KV.Get(KVAuditBucketsKey, &buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// updateIndex updates the keys of the entries indexed in a bucket. The index
+// expires after the entries it lists, and is deleted if it becomes empty.
+func (s *auditStore) updateIndex(bucket int64, update func([]string) []string) error {
+	expiry := s.conf.Get().AuditLog.Retention + auditBucketSize
+	return s.updateKey(auditIndexKey(bucket), expiry, func(data []byte) ([]byte, error) {
+		keys := []string{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &keys); err != nil {
+				return nil, errors.Wrap(err, "failed to decode audit log index")
+			}
+		}
+		if keys = update(keys); len(keys) == 0 {
+			return nil, nil
+		}
+		return json.Marshal(keys)
+	})
+}
+
+// updateBuckets updates the list of the buckets that have entries indexed.
+func (s *auditStore) updateBuckets(update func([]int64) []int64) error {
+	return s.updateKey(KVAuditBucketsKey, 0, func(data []byte) ([]byte, error) {
+		buckets := []int64{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &buckets); err != nil {
+				return nil, errors.Wrap(err, "failed to decode audit log buckets")
+			}
+		}
+		if buckets = update(buckets); len(buckets) == 0 {
+			return nil, nil
+		}
+		return json.Marshal(buckets)
+	})
+}
+
+// updateKey stores the value returned by update for the current value of the
+// key, retrying if the key is concurrently updated. A nil value deletes the
+// key.
+func (s *auditStore) updateKey(key string, expiry time.Duration, update func([]byte) ([]byte, error)) error {
+	mm := s.conf.MattermostAPI()
+	for i := 0; i < auditCASAttempts; i++ {
+		var prev []byte
+		if err := mm.KV.Get(key, &prev); err != nil {
+			return err
+		}
+		data, err := update(prev)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, prev) {
+			return nil
+		}
+		opts := []pluginapi.KVSetOption{pluginapi.SetAtomic(prev)}
+		if expiry > 0 && data != nil {
+			opts = append(opts, pluginapi.SetExpiry(expiry))
+		}
+		ok, err := mm.KV.Set(key, data, opts...)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.Errorf("failed to update %s, too many concurrent updates", key)
+}
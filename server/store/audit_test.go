@@ -0,0 +1,95 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+func TestAuditStore(t *testing.T) {
+	s, kv := newTestKVService(&config.Config{
+		AuditLog: config.AuditLogConfig{
+			MaxEntries: 3,
+			Retention:  24 * time.Hour,
+		},
+	})
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	a := makeAuditStore(s)
+	a.now = func() time.Time { return now }
+	at := func(d time.Duration) int64 {
+		return now.Add(d).UnixMilli()
+	}
+
+	entries, err := a.List(AuditFilter{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	for _, e := range []AuditEntry{
+		{CreateAt: at(-3 * time.Hour), Event: AuditEventInstall, AppID: "app.1", UserID: "admin", Path: "/install"},
+		{CreateAt: at(-2 * time.Hour), Event: AuditEventCall, AppID: "app.1", UserID: "user1", Path: "/one"},
+		{CreateAt: at(-time.Hour), Event: AuditEventCall, AppID: "app2", UserID: "user1", Path: "/two"},
+		{CreateAt: at(-time.Hour + time.Minute), Event: AuditEventCall, AppID: "app.1", UserID: "user2", Path: "/three"},
+	} {
+		require.NoError(t, a.Append(e))
+	}
+	// The entries, the indexes of the 3 hours, and the list of the hours.
+	require.Len(t, kv, 8)
+
+	paths := func(entries []AuditEntry) []string {
+		out := []string{}
+		for _, e := range entries {
+			require.NotEmpty(t, e.ID)
+			out = append(out, e.Path)
+		}
+		return out
+	}
+
+	// At most MaxEntries are listed.
+	entries, err = a.List(AuditFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/three", "/two", "/one"}, paths(entries))
+
+	for name, tc := range map[string]struct {
+		f        AuditFilter
+		expected []string
+	}{
+		"app":   {AuditFilter{AppID: "app.1"}, []string{"/three", "/one", "/install"}},
+		"user":  {AuditFilter{UserID: "user1"}, []string{"/two", "/one"}},
+		"since": {AuditFilter{Since: at(-time.Hour)}, []string{"/three", "/two"}},
+		"until": {AuditFilter{Until: at(-time.Hour)}, []string{"/two", "/one", "/install"}},
+		"limit": {AuditFilter{Limit: 1}, []string{"/three"}},
+		"none":  {AuditFilter{AppID: "app.1", UserID: "user1", Since: at(-time.Hour)}, []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			entries, err := a.List(tc.f)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, paths(entries))
+		})
+	}
+
+	// The oldest entries past MaxEntries are pruned, with the index of their
+	// hour.
+	n, err := a.Prune()
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Len(t, kv, 6)
+	entries, err = a.List(AuditFilter{AppID: "app.1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/three", "/one"}, paths(entries))
+
+	// The indexes of the expired entries are dropped, the expired entries are
+	// not listed.
+	now = now.Add(23 * time.Hour)
+	n, err = a.Prune()
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	buckets, err := a.loadBuckets()
+	require.NoError(t, err)
+	require.Equal(t, []int64{auditBucket(at(-24 * time.Hour))}, buckets)
+	entries, err = a.List(AuditFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/three", "/two"}, paths(entries))
+}
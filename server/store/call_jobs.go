@@ -0,0 +1,94 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// CallJobTTL is how long the asynchronous call jobs, and their results, are
+// kept.
+const CallJobTTL = 24 * time.Hour
+
+const callJobCASAttempts = 5
+
+// CallJobStore persists the status and the results of asynchronous calls.
+type CallJobStore interface {
+	Save(apps.CallJob) error
+	Get(id string) (*apps.CallJob, error)
+
+	// Update atomically modifies a stored job, and returns the result. The
+	// errors returned by modify are returned as is, and the job is not
+	// updated.
+	Update(id string, modify func(*apps.CallJob) error) (*apps.CallJob, error)
+}
+
+type callJobStore struct {
+	*Service
+}
+
+var _ CallJobStore = (*callJobStore)(nil)
+
+func (s *callJobStore) Save(job apps.CallJob) error {
+	_, err := s.conf.MattermostAPI().KV.Set(KVCallJobPrefix+job.ID, job, pluginapi.SetExpiry(CallJobTTL))
+	return err
+}
+
+func (s *callJobStore) Get(id string) (*apps.CallJob, error) {
+	job := apps.CallJob{}
+	err := s.conf.MattermostAPI().KV.Get(KVCallJobPrefix+id, &job)
+	if err != nil {
+		return nil, err
+	}
+	if job.ID == "" {
+		return nil, utils.NewNotFoundError("call job %s", id)
+	}
+	return &job, nil
+}
+
+// Update uses its own compare-and-set loop rather than
+// KV.SetAtomicWithRetries, which would drop the key's expiry.
+func (s *callJobStore) Update(id string, modify func(*apps.CallJob) error) (*apps.CallJob, error) {
+	mm := s.conf.MattermostAPI()
+	key := KVCallJobPrefix + id
+	for i := 0; i < callJobCASAttempts; i++ {
+		var prev []byte
+		err := mm.KV.Get(key, &prev)
+		if err != nil {
+			return nil, err
+		}
+		if len(prev) == 0 {
+			return nil, utils.NewNotFoundError("call job %s", id)
+		}
+
+		job := apps.CallJob{}
+		if err = json.Unmarshal(prev, &job); err != nil {
+			return nil, err
+		}
+		if err = modify(&job); err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(job)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := mm.KV.Set(key, data, pluginapi.SetAtomic(prev), pluginapi.SetExpiry(CallJobTTL))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &job, nil
+		}
+	}
+	return nil, errors.Errorf("failed to update call job %s, too many concurrent updates", id)
+}
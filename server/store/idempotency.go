@@ -0,0 +1,93 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// IdempotentCall is the record of a call made with an idempotency key. It has
+// no Response while the call is in progress.
+type IdempotentCall struct {
+	Response *apps.CallResponse `json:"response,omitempty"`
+	CreateAt int64              `json:"create_at"`
+}
+
+// IdempotencyStore de-duplicates the calls made with an idempotency key,
+// across the cluster. The keys are expected to be already scoped to the app
+// and the user, and hashed.
+type IdempotencyStore interface {
+	// Begin records that a call with the key is in progress, for up to ttl.
+	// If a call with the key is already in progress, or completed, its
+	// record is returned instead, and nothing is changed.
+	Begin(key string, ttl time.Duration) (existing *IdempotentCall, err error)
+
+	// Complete stores the response to the call, to be replayed for ttl.
+	Complete(key string, cresp apps.CallResponse, ttl time.Duration) error
+
+	Get(key string) (*IdempotentCall, error)
+	Delete(key string) error
+}
+
+type idempotencyStore struct {
+	*Service
+}
+
+var _ IdempotencyStore = (*idempotencyStore)(nil)
+
+func (s *idempotencyStore) Begin(key string, ttl time.Duration) (*IdempotentCall, error) {
+	ok, err := s.conf.MattermostAPI().KV.Set(KVIdempotencyPrefix+key,
+		IdempotentCall{CreateAt: time.Now().UnixMilli()},
+		pluginapi.SetAtomic(nil),
+		pluginapi.SetExpiry(ttl))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, nil
+	}
+
+	existing, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		// Expired in the meantime, the caller may retry.
+		return &IdempotentCall{}, nil
+	}
+	return existing, nil
+}
+
+func (s *idempotencyStore) Complete(key string, cresp apps.CallResponse, ttl time.Duration) error {
+	_, err := s.conf.MattermostAPI().KV.Set(KVIdempotencyPrefix+key,
+		IdempotentCall{
+			Response: &cresp,
+			CreateAt: time.Now().UnixMilli(),
+		},
+		pluginapi.SetExpiry(ttl))
+	return err
+}
+
+// Get returns nil if there is no record of the key.
+func (s *idempotencyStore) Get(key string) (*IdempotentCall, error) {
+	var data []byte
+	err := s.conf.MattermostAPI().KV.Get(KVIdempotencyPrefix+key, &data)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	call := IdempotentCall{}
+	if err = json.Unmarshal(data, &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+func (s *idempotencyStore) Delete(key string) error {
+	return s.conf.MattermostAPI().KV.Delete(KVIdempotencyPrefix + key)
+}
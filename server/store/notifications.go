@@ -0,0 +1,267 @@
+// Copyright (c) 2020-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// MaxDeadLettersPerApp limits the size of each app's dead-letter list; the
+// oldest entries are dropped first.
+const MaxDeadLettersPerApp = 100
+
+// Notification is a subscription notification that failed to be delivered.
+// It is stored either as pending a retry, or in the app's dead-letter list once
+// the retries are exhausted.
+type Notification struct {
+	ID           string       `json:"id"`
+	Event        apps.Event   `json:"event"`
+	Subscription Subscription `json:"subscription"`
+
+	// Context is the unexpanded context of the notification, it is expanded
+	// anew for every delivery attempt.
+	Context apps.Context `json:"context"`
+
+	Attempts      int    `json:"attempts"`
+	CreatedAt     int64  `json:"created_at"`
+	NextAttemptAt int64  `json:"next_attempt_at,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+// NotificationStore persists the failed subscription notifications.
+type NotificationStore interface {
+	// SaveRetry stores the notification pending a retry, and records it in
+	// the app's index of the pending retries. If the index can not be
+	// updated, the stored notification is removed and an error is returned.
+	SaveRetry(Notification) error
+
+	// ListDueRetries returns the app's notifications pending a retry that are
+	// due by now, in Unix milliseconds. It reads the app's index of the
+	// pending retries, not all stored keys.
+	ListDueRetries(_ apps.AppID, now int64) ([]Notification, error)
+	DeleteRetry(_ apps.AppID, id string) error
+
+	// DeleteRetries deletes all of the app's notifications pending a retry,
+	// and its index.
+	DeleteRetries(apps.AppID) error
+
+	SaveDeadLetter(Notification) error
+	GetDeadLetter(_ apps.AppID, id string) (*Notification, error)
+	ListDeadLetters(apps.AppID) ([]Notification, error)
+	DeleteDeadLetter(_ apps.AppID, id string) error
+}
+
+type notificationStore struct {
+	*Service
+}
+
+var _ NotificationStore = (*notificationStore)(nil)
+
+// retryIndex maps the IDs of the notifications pending a retry to the time of
+// their next attempt, in Unix milliseconds.
+type retryIndex map[string]int64
+
+func retryKey(id string) string {
+	return KVNotificationRetryPrefix + id
+}
+
+// retryIndexKey is the key of the app's index of the notifications pending a
+// retry. Each app has its own, so that the failures of one app do not contend
+// with the others' for a single key.
+func retryIndexKey(appID apps.AppID) string {
+	return KVNotificationRetryPrefix + "index." + string(appID)
+}
+
+// deadLetterKey is the key of the app's entire dead-letter list. It is not used
+// as a prefix, so app IDs that contain '.' do not collide.
+func deadLetterKey(appID apps.AppID) string {
+	return KVNotificationDeadLetterPrefix + string(appID)
+}
+
+func (s *notificationStore) SaveRetry(n Notification) error {
+	mm := s.conf.MattermostAPI()
+	_, err := mm.KV.Set(retryKey(n.ID), n)
+	if err != nil {
+		return err
+	}
+	err = s.updateRetryIndex(n.Subscription.AppID, func(index retryIndex) {
+		index[n.ID] = n.NextAttemptAt
+	})
+	if err != nil {
+		_ = mm.KV.Delete(retryKey(n.ID))
+		return err
+	}
+	return nil
+}
+
+func (s *notificationStore) ListDueRetries(appID apps.AppID, now int64) ([]Notification, error) {
+	mm := s.conf.MattermostAPI()
+	index := retryIndex{}
+	err := mm.KV.Get(retryIndexKey(appID), &index)
+	if err != nil {
+		return nil, err
+	}
+
+	due := []Notification{}
+	missing := []string{}
+	for id, nextAttemptAt := range index {
+		if nextAttemptAt > now {
+			continue
+		}
+		n := Notification{}
+		err = mm.KV.Get(retryKey(id), &n)
+		if err != nil {
+			return nil, err
+		}
+		if n.ID == "" {
+			missing = append(missing, id)
+			continue
+		}
+		due = append(due, n)
+	}
+
+	if len(missing) > 0 {
+		err = s.updateRetryIndex(appID, func(index retryIndex) {
+			for _, id := range missing {
+				delete(index, id)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt < due[j].CreatedAt
+	})
+	return due, nil
+}
+
+func (s *notificationStore) DeleteRetry(appID apps.AppID, id string) error {
+	err := s.conf.MattermostAPI().KV.Delete(retryKey(id))
+	if err != nil {
+		return err
+	}
+	return s.updateRetryIndex(appID, func(index retryIndex) {
+		delete(index, id)
+	})
+}
+
+func (s *notificationStore) DeleteRetries(appID apps.AppID) error {
+	mm := s.conf.MattermostAPI()
+	index := retryIndex{}
+	err := mm.KV.Get(retryIndexKey(appID), &index)
+	if err != nil {
+		return err
+	}
+	for id := range index {
+		err = mm.KV.Delete(retryKey(id))
+		if err != nil {
+			return err
+		}
+	}
+	return mm.KV.Delete(retryIndexKey(appID))
+}
+
+// errRetryIndexUnchanged aborts the atomic update of an index that is neither
+// stored nor to be stored.
+var errRetryIndexUnchanged = errors.New("retry index unchanged")
+
+func (s *notificationStore) updateRetryIndex(appID apps.AppID, modify func(retryIndex)) error {
+	unchanged := false
+	err := s.conf.MattermostAPI().KV.SetAtomicWithRetries(retryIndexKey(appID), func(oldValue []byte) (interface{}, error) {
+		index := retryIndex{}
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &index); err != nil {
+				return nil, err
+			}
+		}
+		modify(index)
+		switch {
+		case len(index) > 0:
+			return index, nil
+		case len(oldValue) > 0:
+			// Delete the key.
+			return nil, nil
+		default:
+			unchanged = true
+			return nil, errRetryIndexUnchanged
+		}
+	})
+	if unchanged {
+		return nil
+	}
+	return err
+}
+
+// SaveDeadLetter adds the notification to the end of the app's dead-letter
+// list, or updates it in place if it is already listed.
+func (s *notificationStore) SaveDeadLetter(n Notification) error {
+	return s.updateDeadLetters(n.Subscription.AppID, func(all []Notification) []Notification {
+		for i := range all {
+			if all[i].ID == n.ID {
+				all[i] = n
+				return all
+			}
+		}
+		all = append(all, n)
+		if len(all) > MaxDeadLettersPerApp {
+			all = all[len(all)-MaxDeadLettersPerApp:]
+		}
+		return all
+	})
+}
+
+func (s *notificationStore) GetDeadLetter(appID apps.AppID, id string) (*Notification, error) {
+	all, err := s.ListDeadLetters(appID)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range all {
+		if n.ID == id {
+			return &n, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("notification %s for %s", id, appID)
+}
+
+// ListDeadLetters returns the app's dead letters, oldest first.
+func (s *notificationStore) ListDeadLetters(appID apps.AppID) ([]Notification, error) {
+	all := []Notification{}
+	err := s.conf.MattermostAPI().KV.Get(deadLetterKey(appID), &all)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (s *notificationStore) DeleteDeadLetter(appID apps.AppID, id string) error {
+	return s.updateDeadLetters(appID, func(all []Notification) []Notification {
+		modified := []Notification{}
+		for _, n := range all {
+			if n.ID != id {
+				modified = append(modified, n)
+			}
+		}
+		return modified
+	})
+}
+
+func (s *notificationStore) updateDeadLetters(appID apps.AppID, modify func([]Notification) []Notification) error {
+	return s.conf.MattermostAPI().KV.SetAtomicWithRetries(deadLetterKey(appID), func(oldValue []byte) (interface{}, error) {
+		all := []Notification{}
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &all); err != nil {
+				return nil, err
+			}
+		}
+		return modify(all), nil
+	})
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestNotificationStoreRetries(t *testing.T) {
+	service, kv := newTestKVService(&config.Config{})
+	s := &notificationStore{Service: service}
+
+	due, err := s.ListDueRetries("app1", 1000)
+	require.NoError(t, err)
+	require.Empty(t, due)
+
+	retry := func(appID, id string, createdAt, nextAttemptAt int64) Notification {
+		return Notification{
+			ID:            id,
+			Subscription:  Subscription{AppID: apps.AppID(appID)},
+			CreatedAt:     createdAt,
+			NextAttemptAt: nextAttemptAt,
+		}
+	}
+	require.NoError(t, s.SaveRetry(retry("app1", "n1", 2, 1000)))
+	require.NoError(t, s.SaveRetry(retry("app1", "n2", 1, 500)))
+	require.NoError(t, s.SaveRetry(retry("app1", "n3", 3, 2000)))
+	require.NoError(t, s.SaveRetry(retry("app2", "n4", 1, 500)))
+
+	ids := func(ns []Notification) []string {
+		out := []string{}
+		for _, n := range ns {
+			out = append(out, n.ID)
+		}
+		return out
+	}
+	due, err = s.ListDueRetries("app1", 1000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n2", "n1"}, ids(due))
+	due, err = s.ListDueRetries("app2", 1000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n4"}, ids(due))
+
+	// Rescheduled.
+	require.NoError(t, s.SaveRetry(retry("app1", "n1", 2, 3000)))
+	require.NoError(t, s.DeleteRetry("app1", "n2"))
+	require.NotContains(t, kv, retryKey("n2"))
+	due, err = s.ListDueRetries("app1", 2000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n3"}, ids(due))
+
+	// The retries deleted without updating the index are dropped from it.
+	delete(kv, retryKey("n3"))
+	due, err = s.ListDueRetries("app1", 5000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n1"}, ids(due))
+	index := retryIndex{}
+	require.NoError(t, service.conf.MattermostAPI().KV.Get(retryIndexKey("app1"), &index))
+	require.Equal(t, retryIndex{"n1": 3000}, index)
+
+	// The last retry deleted deletes the index.
+	require.NoError(t, s.DeleteRetry("app2", "n4"))
+	require.NotContains(t, kv, retryIndexKey("app2"))
+
+	require.NoError(t, s.DeleteRetries("app1"))
+	require.Empty(t, kv)
+}
+
+func TestNotificationStoreDeadLetters(t *testing.T) {
+	service, _ := newTestKVService(&config.Config{})
+	s := &notificationStore{Service: service}
+
+	deadLetter := func(appID, id string) Notification {
+		return Notification{ID: id, Subscription: Subscription{AppID: apps.AppID(appID)}}
+	}
+	// "foo" and "foo.bar" must not see each other's dead letters.
+	require.NoError(t, s.SaveDeadLetter(deadLetter("foo", "n1")))
+	require.NoError(t, s.SaveDeadLetter(deadLetter("foo.bar", "n2")))
+
+	foo, err := s.ListDeadLetters("foo")
+	require.NoError(t, err)
+	require.Equal(t, []Notification{deadLetter("foo", "n1")}, foo)
+	_, err = s.GetDeadLetter("foo", "n2")
+	require.ErrorIs(t, err, utils.ErrNotFound)
+	require.NoError(t, s.DeleteDeadLetter("foo", "n2"))
+	fooBar, err := s.ListDeadLetters("foo.bar")
+	require.NoError(t, err)
+	require.Len(t, fooBar, 1)
+
+	// Updated in place.
+	updated := deadLetter("foo", "n1")
+	updated.Attempts = 9
+	require.NoError(t, s.SaveDeadLetter(updated))
+	n, err := s.GetDeadLetter("foo", "n1")
+	require.NoError(t, err)
+	require.Equal(t, 9, n.Attempts)
+
+	// The oldest are dropped.
+	for i := 0; i < MaxDeadLettersPerApp; i++ {
+		require.NoError(t, s.SaveDeadLetter(deadLetter("foo", fmt.Sprintf("d%03d", i))))
+	}
+	foo, err = s.ListDeadLetters("foo")
+	require.NoError(t, err)
+	require.Len(t, foo, MaxDeadLettersPerApp)
+	require.Equal(t, "d000", foo[0].ID)
+
+	require.NoError(t, s.DeleteDeadLetter("foo", "d000"))
+	foo, err = s.ListDeadLetters("foo")
+	require.NoError(t, err)
+	require.Len(t, foo, MaxDeadLettersPerApp-1)
+}
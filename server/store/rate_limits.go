@@ -0,0 +1,243 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+// RateLimit identifies a token bucket, and its rate.
+type RateLimit struct {
+	Key       string
+	PerMinute int
+}
+
+// RateLimitStore implements token buckets shared by all nodes in the cluster.
+type RateLimitStore interface {
+	// Take takes a token from each of the buckets, only if all of them have
+	// one available. A bucket holds up to PerMinute tokens, and is refilled at
+	// the rate of PerMinute tokens per minute. If a bucket is empty, no token
+	// is taken from any of them, and the index of the empty bucket is returned
+	// with the time until a token becomes available in it.
+	Take(_ *incoming.Request, limits []RateLimit) (rejected int, retryAfter time.Duration, err error)
+}
+
+type rateLimitStore struct {
+	*Service
+	now func() time.Time
+
+	// emptyUntil caches the time until which the buckets are known to be
+	// empty, so that the calls rejected by the node do not access the KV
+	// store.
+	mutex      sync.Mutex
+	emptyUntil map[string]time.Time
+}
+
+var _ RateLimitStore = (*rateLimitStore)(nil)
+
+// rateLimitCASAttempts limits the number of attempts to update a bucket
+// concurrently updated by other requests.
+const rateLimitCASAttempts = 5
+
+// rateLimitTTL is how long an unused bucket is kept. A minute is enough for
+// any bucket to refill.
+const rateLimitTTL = 2 * time.Minute
+
+// maxCachedEmptyRateLimits is the number of cached empty buckets past which
+// the ones that have refilled are dropped from the cache.
+const maxCachedEmptyRateLimits = 1000
+
+type rateLimitBucket struct {
+	Tokens    float64 `json:"tokens"`
+	UpdatedAt int64   `json:"updated_at"`
+}
+
+// refill adds the tokens accrued since the bucket was last updated.
+func (b *rateLimitBucket) refill(perMinute int, now time.Time) {
+	capacity := float64(perMinute)
+	if b.UpdatedAt == 0 {
+		b.Tokens = capacity
+	} else if elapsed := now.UnixMilli() - b.UpdatedAt; elapsed > 0 {
+		b.Tokens += float64(elapsed) * ratePerMs(perMinute)
+	}
+	if b.Tokens > capacity {
+		b.Tokens = capacity
+	}
+	b.UpdatedAt = now.UnixMilli()
+}
+
+// waitTime returns the time until a token is available in the refilled
+// bucket, or 0 if there is one.
+func (b *rateLimitBucket) waitTime(perMinute int) time.Duration {
+	if b.Tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1-b.Tokens)/ratePerMs(perMinute)) * time.Millisecond
+}
+
+// take refills the bucket as of now, and takes a token from it if available.
+func (b *rateLimitBucket) take(perMinute int, now time.Time) (retryAfter time.Duration) {
+	b.refill(perMinute, now)
+	if retryAfter = b.waitTime(perMinute); retryAfter > 0 {
+		return retryAfter
+	}
+	b.Tokens--
+	return 0
+}
+
+// refund returns a token taken from the bucket.
+func (b *rateLimitBucket) refund(perMinute int, now time.Time) {
+	b.refill(perMinute, now)
+	b.Tokens++
+	if capacity := float64(perMinute); b.Tokens > capacity {
+		b.Tokens = capacity
+	}
+}
+
+func ratePerMs(perMinute int) float64 {
+	return float64(perMinute) / float64(time.Minute.Milliseconds())
+}
+
+type storedRateLimitBucket struct {
+	rateLimitBucket
+	data []byte
+}
+
+func (s *rateLimitStore) Take(r *incoming.Request, limits []RateLimit) (int, time.Duration, error) {
+	now := s.now()
+
+	// Reject right away if a bucket is known to be empty.
+	s.mutex.Lock()
+	for i, l := range limits {
+		if until := s.emptyUntil[l.Key]; now.Before(until) {
+			s.mutex.Unlock()
+			return i, until.Sub(now), nil
+		}
+	}
+	s.mutex.Unlock()
+
+	// Check all buckets before taking any tokens.
+	stored := make([]*storedRateLimitBucket, len(limits))
+	for i, l := range limits {
+		if l.PerMinute <= 0 {
+			continue
+		}
+		b, err := s.load(l.Key)
+		if err != nil {
+			return 0, 0, err
+		}
+		check := b.rateLimitBucket
+		check.refill(l.PerMinute, now)
+		if retryAfter := check.waitTime(l.PerMinute); retryAfter > 0 {
+			s.setEmpty(l.Key, now.Add(retryAfter))
+			return i, retryAfter, nil
+		}
+		stored[i] = b
+	}
+
+	for i, l := range limits {
+		if stored[i] == nil {
+			continue
+		}
+		retryAfter, err := s.update(l.Key, stored[i], func(b *rateLimitBucket) time.Duration {
+			return b.take(l.PerMinute, now)
+		})
+		if err == nil && retryAfter == 0 {
+			continue
+		}
+
+		// The bucket was emptied concurrently, return the tokens taken from
+		// the other buckets.
+		for j := 0; j < i; j++ {
+			if stored[j] == nil {
+				continue
+			}
+			perMinute := limits[j].PerMinute
+			_, refundErr := s.update(limits[j].Key, nil, func(b *rateLimitBucket) time.Duration {
+				b.refund(perMinute, now)
+				return 0
+			})
+			if refundErr != nil {
+				r.Log.WithError(refundErr).Warnf("failed to refund a rate limit token")
+			}
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		s.setEmpty(l.Key, now.Add(retryAfter))
+		return i, retryAfter, nil
+	}
+	return 0, 0, nil
+}
+
+func (s *rateLimitStore) setEmpty(key string, until time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.emptyUntil == nil {
+		s.emptyUntil = map[string]time.Time{}
+	}
+	if len(s.emptyUntil) >= maxCachedEmptyRateLimits {
+		now := s.now()
+		for k, t := range s.emptyUntil {
+			if !now.Before(t) {
+				delete(s.emptyUntil, k)
+			}
+		}
+	}
+	s.emptyUntil[key] = until
+}
+
+func (s *rateLimitStore) load(key string) (*storedRateLimitBucket, error) {
+	b := &storedRateLimitBucket{}
+	if err := s.conf.MattermostAPI().KV.Get(KVRateLimitPrefix+key, &b.data); err != nil {
+		return nil, err
+	}
+	if len(b.data) > 0 {
+		if err := json.Unmarshal(b.data, &b.rateLimitBucket); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// update modifies the bucket, starting with the stored value if it has been
+// loaded already. It does not store the bucket if modify returns a non-zero
+// retryAfter.
+func (s *rateLimitStore) update(key string, stored *storedRateLimitBucket, modify func(*rateLimitBucket) time.Duration) (time.Duration, error) {
+	for i := 0; i < rateLimitCASAttempts; i++ {
+		if stored == nil {
+			var err error
+			if stored, err = s.load(key); err != nil {
+				return 0, err
+			}
+		}
+		bucket := stored.rateLimitBucket
+		if retryAfter := modify(&bucket); retryAfter > 0 {
+			return retryAfter, nil
+		}
+
+		data, err := json.Marshal(bucket)
+		if err != nil {
+			return 0, err
+		}
+		ok, err := s.conf.MattermostAPI().KV.Set(KVRateLimitPrefix+key, data,
+			pluginapi.SetAtomic(stored.data), pluginapi.SetExpiry(rateLimitTTL))
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return 0, nil
+		}
+		stored = nil
+	}
+	return 0, errors.Errorf("failed to update rate limit %s, too many concurrent updates", key)
+}
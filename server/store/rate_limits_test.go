@@ -0,0 +1,75 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestRateLimitBucketTake(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	b := rateLimitBucket{}
+
+	// A new bucket is full.
+	for i := 0; i < 3; i++ {
+		require.Zero(t, b.take(3, now))
+	}
+	require.Equal(t, 20*time.Second, b.take(3, now))
+
+	// A token is refilled every 20 seconds.
+	require.Equal(t, 5*time.Second, b.take(3, now.Add(15*time.Second)))
+	require.Zero(t, b.take(3, now.Add(20*time.Second)))
+	require.Equal(t, 20*time.Second, b.take(3, now.Add(20*time.Second)))
+
+	// Refills up to the capacity.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.Zero(t, b.take(3, now))
+	}
+	require.NotZero(t, b.take(3, now))
+}
+
+func TestRateLimitStoreTake(t *testing.T) {
+	s, kv := newTestKVService(&config.Config{})
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	rl := &rateLimitStore{Service: s, now: func() time.Time { return now }}
+	r := incoming.NewRequest(s.conf, utils.NewTestLogger(), nil)
+
+	narrow := RateLimit{Key: "narrow", PerMinute: 3}
+	broad := RateLimit{Key: "broad", PerMinute: 2}
+	take := func(limits ...RateLimit) (int, time.Duration) {
+		rejected, retryAfter, err := rl.Take(r, limits)
+		require.NoError(t, err)
+		return rejected, retryAfter
+	}
+
+	_, retryAfter := take(narrow, broad)
+	require.Zero(t, retryAfter)
+	_, retryAfter = take(narrow, broad)
+	require.Zero(t, retryAfter)
+
+	// The broad bucket is empty, no token is taken from the narrow one.
+	rejected, retryAfter := take(narrow, broad)
+	require.Equal(t, 1, rejected)
+	require.Equal(t, 30*time.Second, retryAfter)
+	_, retryAfter = take(narrow)
+	require.Zero(t, retryAfter)
+	rejected, retryAfter = take(narrow)
+	require.Equal(t, 0, rejected)
+	require.Equal(t, 20*time.Second, retryAfter)
+
+	// The empty buckets are rejected without loading them again.
+	delete(kv, KVRateLimitPrefix+narrow.Key)
+	now = now.Add(10 * time.Second)
+	rejected, retryAfter = take(narrow)
+	require.Equal(t, 0, rejected)
+	require.Equal(t, 10*time.Second, retryAfter)
+	now = now.Add(20 * time.Second)
+	_, retryAfter = take(broad, narrow)
+	require.Zero(t, retryAfter)
+}
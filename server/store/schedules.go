@@ -0,0 +1,127 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// MaxSchedulesPerApp limits the number of scheduled calls an app may have.
+const MaxSchedulesPerApp = 100
+
+// ScheduleStore persists the apps' scheduled calls. Each app's schedules are
+// stored together, under a single key.
+type ScheduleStore interface {
+	// Add stores a new schedule, unless the app already has
+	// MaxSchedulesPerApp.
+	Add(apps.Schedule) error
+
+	// Update replaces a stored schedule. It returns a not found error if the
+	// schedule has been deleted, it is not re-created.
+	Update(apps.Schedule) error
+
+	Delete(_ apps.AppID, id string) error
+
+	// DeleteAll deletes all of the app's schedules.
+	DeleteAll(apps.AppID) error
+
+	// List returns the app's schedules, ordered by the next run time.
+	List(apps.AppID) ([]apps.Schedule, error)
+}
+
+type scheduleStore struct {
+	*Service
+}
+
+var _ ScheduleStore = (*scheduleStore)(nil)
+
+// scheduleKey is the key of the app's entire list of schedules. It is not used
+// as a prefix, so app IDs that contain '.' do not collide.
+func scheduleKey(appID apps.AppID) string {
+	return KVSchedulePrefix + string(appID)
+}
+
+func (s *scheduleStore) Add(schedule apps.Schedule) error {
+	return s.updateSchedules(schedule.AppID, func(all []apps.Schedule) ([]apps.Schedule, error) {
+		if len(all) >= MaxSchedulesPerApp {
+			return nil, utils.NewForbiddenError("%s already has the maximum of %v scheduled calls", schedule.AppID, MaxSchedulesPerApp)
+		}
+		return append(all, schedule), nil
+	})
+}
+
+func (s *scheduleStore) Update(schedule apps.Schedule) error {
+	return s.updateSchedules(schedule.AppID, func(all []apps.Schedule) ([]apps.Schedule, error) {
+		for i := range all {
+			if all[i].ID == schedule.ID {
+				all[i] = schedule
+				return all, nil
+			}
+		}
+		return nil, utils.NewNotFoundError("schedule %s for %s", schedule.ID, schedule.AppID)
+	})
+}
+
+func (s *scheduleStore) Delete(appID apps.AppID, id string) error {
+	return s.updateSchedules(appID, func(all []apps.Schedule) ([]apps.Schedule, error) {
+		modified := []apps.Schedule{}
+		for _, schedule := range all {
+			if schedule.ID != id {
+				modified = append(modified, schedule)
+			}
+		}
+		if len(modified) == len(all) {
+			return nil, utils.NewNotFoundError("schedule %s for %s", id, appID)
+		}
+		return modified, nil
+	})
+}
+
+func (s *scheduleStore) DeleteAll(appID apps.AppID) error {
+	return s.conf.MattermostAPI().KV.Delete(scheduleKey(appID))
+}
+
+func (s *scheduleStore) List(appID apps.AppID) ([]apps.Schedule, error) {
+	all := []apps.Schedule{}
+	err := s.conf.MattermostAPI().KV.Get(scheduleKey(appID), &all)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].NextRunAt < all[j].NextRunAt
+	})
+	return all, nil
+}
+
+// updateSchedules atomically modifies the app's list of schedules. The errors
+// returned by modify are returned unwrapped.
+func (s *scheduleStore) updateSchedules(appID apps.AppID, modify func([]apps.Schedule) ([]apps.Schedule, error)) error {
+	var modifyErr error
+	err := s.conf.MattermostAPI().KV.SetAtomicWithRetries(scheduleKey(appID), func(oldValue []byte) (interface{}, error) {
+		all := []apps.Schedule{}
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &all); err != nil {
+				return nil, err
+			}
+		}
+		modified, err := modify(all)
+		if err != nil {
+			modifyErr = err
+			return nil, err
+		}
+		if len(modified) == 0 {
+			// Delete the key.
+			return nil, nil
+		}
+		return modified, nil
+	})
+	if modifyErr != nil {
+		return modifyErr
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestScheduleStore(t *testing.T) {
+	s, _ := newTestKVService(&config.Config{})
+	ss := &scheduleStore{Service: s}
+
+	require.NoError(t, ss.Add(apps.Schedule{ID: "s2", AppID: "app", NextRunAt: 2}))
+	require.NoError(t, ss.Add(apps.Schedule{ID: "s1", AppID: "app", NextRunAt: 1}))
+	require.NoError(t, ss.Add(apps.Schedule{ID: "s3", AppID: "app.other", NextRunAt: 3}))
+
+	// App IDs that share a prefix do not collide.
+	list, err := ss.List("app")
+	require.NoError(t, err)
+	require.Equal(t, []apps.Schedule{
+		{ID: "s1", AppID: "app", NextRunAt: 1},
+		{ID: "s2", AppID: "app", NextRunAt: 2},
+	}, list)
+
+	require.NoError(t, ss.Update(apps.Schedule{ID: "s1", AppID: "app", NextRunAt: 4}))
+	list, err = ss.List("app")
+	require.NoError(t, err)
+	require.Equal(t, []string{"s2", "s1"}, []string{list[0].ID, list[1].ID})
+
+	// A deleted schedule is not re-created by an update.
+	require.NoError(t, ss.Delete("app", "s1"))
+	err = ss.Update(apps.Schedule{ID: "s1", AppID: "app", NextRunAt: 5})
+	require.ErrorIs(t, err, utils.ErrNotFound)
+	err = ss.Delete("app", "s1")
+	require.ErrorIs(t, err, utils.ErrNotFound)
+	list, err = ss.List("app")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, ss.DeleteAll("app"))
+	list, err = ss.List("app")
+	require.NoError(t, err)
+	require.Empty(t, list)
+	list, err = ss.List("app.other")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	for i := 0; i < MaxSchedulesPerApp-1; i++ {
+		require.NoError(t, ss.Add(apps.Schedule{ID: "s", AppID: "app.other"}))
+	}
+	err = ss.Add(apps.Schedule{ID: "s", AppID: "app.other"})
+	require.ErrorIs(t, err, utils.ErrForbidden)
+}
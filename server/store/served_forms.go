@@ -0,0 +1,179 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// ServedFormStore keeps the forms served to the users, to validate the
+// submissions against. The keys are expected to be already scoped to the app,
+// the user, and the form's submit path, and hashed. Each key may have several
+// forms served, identified by their IDs.
+type ServedFormStore interface {
+	// Add records the form as served with the key, and assigns its ID. If an
+	// identical form is already recorded, its ID is reused. At most
+	// MaxServedForms forms are kept for each key, the least recently served
+	// ones are dropped first.
+	Add(key string, form *apps.Form) error
+
+	// Get returns the forms served with the key in the last ServedFormTTL, the
+	// most recently served first.
+	Get(key string) ([]apps.Form, error)
+}
+
+// MaxServedForms limits the number of forms kept for each user and submit
+// path of an app.
+const MaxServedForms = 10
+
+// ServedFormTTL is how long a form served to a user is kept to validate its
+// submission against, since it was last served.
+const ServedFormTTL = 24 * time.Hour
+
+// servedFormsRefresh is how old a recorded form may get before it is recorded
+// again when served, to extend its expiry. Within it, serving an identical
+// form, e.g. in the bindings, only reuses its ID.
+const servedFormsRefresh = ServedFormTTL / 2
+
+// servedFormsCacheSize is the number of keys whose forms are cached in
+// memory, on each node, to avoid reading the KV store each time the same
+// forms are served.
+const servedFormsCacheSize = 10000
+
+// servedFormsCASAttempts limits the number of attempts to update the forms
+// concurrently updated by other requests.
+const servedFormsCASAttempts = 5
+
+type servedFormStore struct {
+	*Service
+
+	// cache is keyed by the key, the values are []servedForm as last read or
+	// written by this node.
+	cache *lru.Cache
+	now   func() time.Time
+}
+
+var _ ServedFormStore = (*servedFormStore)(nil)
+
+type servedForm struct {
+	Form     apps.Form `json:"form"`
+	ServedAt int64     `json:"served_at"`
+}
+
+func makeServedFormStore(s *Service) *servedFormStore {
+	cache, _ := lru.New(servedFormsCacheSize)
+	return &servedFormStore{
+		Service: s,
+		cache:   cache,
+		now:     time.Now,
+	}
+}
+
+func (s *servedFormStore) load(key string) (forms []servedForm, data []byte, err error) {
+	err = s.conf.MattermostAPI().KV.Get(KVServedFormPrefix+key, &data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &forms); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to decode served forms")
+		}
+	}
+	return forms, data, nil
+}
+
+// findServedForm returns the index of the recorded form identical to form,
+// ignoring the IDs, or -1.
+func findServedForm(forms []servedForm, form apps.Form) int {
+	form.ID = ""
+	data, _ := json.Marshal(form)
+	for i, f := range forms {
+		f.Form.ID = ""
+		fdata, _ := json.Marshal(f.Form)
+		if bytes.Equal(data, fdata) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *servedFormStore) Add(key string, form *apps.Form) error {
+	now := s.now()
+
+	// Nothing to do if an identical form is cached as recently served.
+	if v, ok := s.cache.Get(key); ok {
+		cached := v.([]servedForm)
+		if i := findServedForm(cached, *form); i >= 0 && cached[i].ServedAt > now.Add(-servedFormsRefresh).UnixMilli() {
+			form.ID = cached[i].Form.ID
+			return nil
+		}
+	}
+
+	for i := 0; i < servedFormsCASAttempts; i++ {
+		stored, prev, err := s.load(key)
+		if err != nil {
+			return err
+		}
+
+		form.ID = model.NewId()
+		existing := findServedForm(stored, *form)
+		if existing >= 0 {
+			form.ID = stored[existing].Form.ID
+			if stored[existing].ServedAt > now.Add(-servedFormsRefresh).UnixMilli() {
+				s.cache.Add(key, stored)
+				return nil
+			}
+		}
+		updated := []servedForm{{Form: *form, ServedAt: now.UnixMilli()}}
+		expireBefore := now.Add(-ServedFormTTL).UnixMilli()
+		for j, f := range stored {
+			if j != existing && f.ServedAt > expireBefore && len(updated) < MaxServedForms {
+				updated = append(updated, f)
+			}
+		}
+
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		ok, err := s.conf.MattermostAPI().KV.Set(KVServedFormPrefix+key, data,
+			pluginapi.SetAtomic(prev), pluginapi.SetExpiry(ServedFormTTL))
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.cache.Add(key, updated)
+			return nil
+		}
+	}
+	form.ID = ""
+	return errors.New("failed to record the served form, too many concurrent updates")
+}
+
+func (s *servedFormStore) Get(key string) ([]apps.Form, error) {
+	stored, _, err := s.load(key)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Add(key, stored)
+
+	var forms []apps.Form
+	expireBefore := s.now().Add(-ServedFormTTL).UnixMilli()
+	for _, f := range stored {
+		if f.ServedAt > expireBefore {
+			forms = append(forms, f.Form)
+		}
+	}
+	return forms, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+func TestServedFormStore(t *testing.T) {
+	s, kv := newTestKVService(&config.Config{})
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	sf := makeServedFormStore(s)
+	sf.now = func() time.Time { return now }
+
+	newForm := func(title string) *apps.Form {
+		return &apps.Form{
+			Title:  title,
+			Submit: apps.NewCall("/submit"),
+		}
+	}
+	titles := func(key string) []string {
+		forms, err := sf.Get(key)
+		require.NoError(t, err)
+		out := []string{}
+		for _, f := range forms {
+			out = append(out, f.Title)
+		}
+		return out
+	}
+
+	one := newForm("one")
+	require.NoError(t, sf.Add("key", one))
+	require.NotEmpty(t, one.ID)
+	require.Contains(t, kv, "frm.key")
+
+	two := newForm("two")
+	now = now.Add(time.Minute)
+	require.NoError(t, sf.Add("key", two))
+	require.NotEqual(t, one.ID, two.ID)
+	require.Equal(t, []string{"two", "one"}, titles("key"))
+	require.Empty(t, titles("other-key"))
+
+	// Serving an identical form reuses its ID, also when it was recorded by
+	// another node.
+	again := newForm("one")
+	require.NoError(t, sf.Add("key", again))
+	require.Equal(t, one.ID, again.ID)
+	other := makeServedFormStore(s)
+	other.now = sf.now
+	again = newForm("one")
+	require.NoError(t, other.Add("key", again))
+	require.Equal(t, one.ID, again.ID)
+
+	// The least recently served forms are dropped past MaxServedForms.
+	for i := 0; i < MaxServedForms-1; i++ {
+		now = now.Add(time.Millisecond)
+		require.NoError(t, sf.Add("key", newForm(fmt.Sprintf("many %v", i))))
+	}
+	forms, err := sf.Get("key")
+	require.NoError(t, err)
+	require.Len(t, forms, MaxServedForms)
+	require.Equal(t, "two", forms[MaxServedForms-1].Title)
+
+	// The forms expire unless served again, their expiry is extended once they
+	// get older than servedFormsRefresh.
+	now = now.Add(servedFormsRefresh)
+	again = newForm("two")
+	require.NoError(t, sf.Add("key", again))
+	require.Equal(t, two.ID, again.ID)
+	now = now.Add(ServedFormTTL - time.Millisecond)
+	require.Equal(t, []string{"two"}, titles("key"))
+}
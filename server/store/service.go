@@ -5,7 +5,9 @@ package store
 
 import (
 	"encoding/ascii85"
+	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
@@ -57,6 +59,47 @@ const (
 	// KVLocalManifestPrefix is used to store locally-listed manifests.
 	KVLocalManifestPrefix = "man."
 
+	// KVNotificationRetryPrefix and KVNotificationDeadLetterPrefix are used
+	// to store the failed subscription notifications, pending a retry, and
+	// abandoned after exhausting the retries.
+	KVNotificationRetryPrefix      = "ntf.r."
+	KVNotificationDeadLetterPrefix = "ntf.d."
+
+	// KVSchedulePrefix is used to store the apps' scheduled calls.
+	KVSchedulePrefix = "sch."
+
+	// KVCallJobPrefix is used to store the status and the results of
+	// asynchronous calls.
+	KVCallJobPrefix = "job."
+
+	// KVRateLimitPrefix is used to store the call rate limit token buckets.
+	KVRateLimitPrefix = "rl."
+
+	// KVUserCallPathsPrefix is used to store the call paths of the apps'
+	// bindings and forms that have been served to each user.
+	KVUserCallPathsPrefix = "ucp."
+
+	// KVIdempotencyPrefix is used to store the responses to the calls made
+	// with an idempotency key.
+	KVIdempotencyPrefix = "idm."
+
+	// KVAuditPrefix is used to store the audit log of the calls and the app
+	// lifecycle actions.
+	KVAuditPrefix = "aud."
+
+	// KVAuditIndexPrefix and KVAuditBucketsKey are used to index the keys of
+	// the audit log entries by the hour they were created in.
+	KVAuditIndexPrefix = "aui."
+	KVAuditBucketsKey  = "aub"
+
+	// KVServedFormPrefix is used to store the forms served to the users, to
+	// validate the submissions against.
+	KVServedFormPrefix = "frm."
+
+	// KVUserStatePrefix is used to store the state of the users, to detect
+	// the user lifecycle changes.
+	KVUserStatePrefix = "ust."
+
 	KVTokenPrefix = ".t"
 
 	// KVCallOnceKey and KVClusterMutexKey are used for invoking App Calls once,
@@ -69,6 +112,19 @@ const (
 	ListKeysPerPage = 1000
 )
 
+// Plugin cluster event IDs, used to keep the in-memory data consistent across
+// the nodes in a Mattermost cluster.
+const (
+	ClusterEventSubscriptionsChanged = "subscriptions_changed"
+	ClusterEventUserCallPathsDeleted = "user_call_paths_deleted"
+)
+
+// ClusterPublisher is used to notify other nodes in the cluster of changes to
+// the cached data. It is implemented by plugin.API.
+type ClusterPublisher interface {
+	PublishPluginClusterEvent(model.PluginClusterEvent, model.PluginClusterEventSendOptions) error
+}
+
 type Service struct {
 	App          AppStore
 	Subscription SubscriptionStore
@@ -76,23 +132,47 @@ type Service struct {
 	AppKV        AppKVStore
 	OAuth2       OAuth2Store
 	Session      SessionStore
+	Notification NotificationStore
+	Schedule     ScheduleStore
+	CallJob      CallJobStore
+	RateLimit    RateLimitStore
+	UserCallPath UserCallPathStore
+	Idempotency  IdempotencyStore
+	Audit        AuditStore
+	ServedForm   ServedFormStore
+	UserState    UserStateStore
 
 	conf    config.Service
 	httpOut httpout.Service
+	cluster ClusterPublisher
 }
 
-func MakeService(log utils.Logger, confService config.Service, httpOut httpout.Service) (*Service, error) {
+func MakeService(log utils.Logger, confService config.Service, httpOut httpout.Service, cluster ClusterPublisher) (*Service, error) {
 	s := &Service{
 		conf:    confService,
 		httpOut: httpOut,
+		cluster: cluster,
 	}
 	s.AppKV = &appKVStore{Service: s}
 	s.OAuth2 = &oauth2Store{Service: s}
-	s.Subscription = &subscriptionStore{Service: s}
 	s.Session = &sessionStore{Service: s}
+	s.Notification = &notificationStore{Service: s}
+	s.Schedule = &scheduleStore{Service: s}
+	s.CallJob = &callJobStore{Service: s}
+	s.RateLimit = &rateLimitStore{Service: s, now: time.Now}
+	s.Idempotency = &idempotencyStore{Service: s}
+	s.Audit = makeAuditStore(s)
+	s.ServedForm = makeServedFormStore(s)
+	s.UserState = &userStateStore{Service: s, now: time.Now}
+	s.UserCallPath = makeUserCallPathStore(s)
 
 	conf := confService.Get()
 	var err error
+	s.Subscription, err = makeSubscriptionStore(s, log)
+	if err != nil {
+		return nil, err
+	}
+
 	s.App, err = makeAppStore(s, conf, log)
 	if err != nil {
 		return nil, err
@@ -105,6 +185,47 @@ func MakeService(log utils.Logger, confService config.Service, httpOut httpout.S
 	return s, nil
 }
 
+// listKeys pages through all the plugin's KV keys, and returns the ones that
+// start with prefix.
+func (s *Service) listKeys(prefix string) ([]string, error) {
+	ret := []string{}
+	for i := 0; ; i++ {
+		keys, err := s.conf.MattermostAPI().KV.ListKeys(i, ListKeysPerPage)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list keys - page, %d", i)
+		}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, prefix) {
+				ret = append(ret, key)
+			}
+		}
+
+		if len(keys) < ListKeysPerPage {
+			return ret, nil
+		}
+	}
+}
+
+// OnPluginClusterEvent handles the cluster events published by the store on
+// other nodes.
+func (s *Service) OnPluginClusterEvent(ev model.PluginClusterEvent) error {
+	switch ev.Id {
+	case ClusterEventSubscriptionsChanged:
+		e := apps.Event{}
+		err := json.Unmarshal(ev.Data, &e)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode subscription event")
+		}
+		return s.Subscription.Reload(e)
+	case ClusterEventUserCallPathsDeleted:
+		s.UserCallPath.Reload(apps.AppID(ev.Data))
+		return nil
+	default:
+		return errors.Errorf("unknown cluster event %q", ev.Id)
+	}
+}
+
 const (
 	hashKeyLength = 82
 )
@@ -1,13 +1,42 @@
 package store
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost-server/v6/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
 )
 
+// newTestKVService returns a store service backed by an in-memory KV store,
+// with the compare-and-set semantics of the plugin API.
+func newTestKVService(cfg *config.Config) (*Service, map[string][]byte) {
+	conf, api := config.NewTestService(cfg)
+	kv := map[string][]byte{}
+	api.On("KVGet", mock.Anything).Return(
+		func(key string) []byte { return kv[key] },
+		func(string) *model.AppError { return nil })
+	api.On("KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(key string, value []byte, opts model.PluginKVSetOptions) bool {
+			if opts.Atomic && !bytes.Equal(kv[key], opts.OldValue) {
+				return false
+			}
+			if value == nil {
+				delete(kv, key)
+			} else {
+				kv[key] = value
+			}
+			return true
+		},
+		func(string, []byte, model.PluginKVSetOptions) *model.AppError { return nil })
+	return &Service{conf: conf}, kv
+}
+
 func TestHashkey(t *testing.T) {
 	for _, tc := range []struct {
 		name               string
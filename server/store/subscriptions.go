@@ -4,9 +4,12 @@
 package store
 
 import (
+	"encoding/json"
+	"sync"
+
 	"github.com/pkg/errors"
 
-	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
@@ -16,15 +19,38 @@ import (
 // for each "scope", everything in apps.Subscription, but the Call - the
 // subject, and the optional team/channel IDs.
 type SubscriptionStore interface {
+	// Get returns the subscriptions for the event from the in-memory index. It
+	// returns an empty list, not utils.ErrNotFound, if there are none.
 	Get(apps.Event) ([]Subscription, error)
+
+	// Owners returns the IDs of the users that own any of the subscriptions,
+	// from the in-memory index.
+	Owners() map[string]bool
+
+	// List returns all stored subscriptions, read from the KV store.
 	List() ([]StoredSubscriptions, error)
-	Save(apps.Event, []Subscription) error
+
+	// Update atomically modifies the stored subscriptions for the event. It
+	// reads them from the KV store, not the index, and invokes modify with
+	// them; modify may be invoked again if another node modifies them
+	// concurrently. An error returned by modify aborts the update. Update
+	// returns the updated subscriptions.
+	Update(e apps.Event, modify func([]Subscription) ([]Subscription, error)) ([]Subscription, error)
+
+	// Rebuild reloads the entire in-memory subscription index from the KV
+	// store.
+	Rebuild() error
+
+	// Reload refreshes the indexed subscriptions for the event from the KV
+	// store. It is invoked when another node in the cluster modifies them.
+	Reload(apps.Event) error
 }
 
 type Subscription struct {
 	Call        apps.Call
-	AppID       apps.AppID `json:"app_id"`
-	OwnerUserID string     `json:"user_id"`
+	AppID       apps.AppID               `json:"app_id"`
+	OwnerUserID string                   `json:"user_id"`
+	Filter      *apps.SubscriptionFilter `json:"filter,omitempty"`
 }
 
 type StoredSubscriptions struct {
@@ -34,54 +60,137 @@ type StoredSubscriptions struct {
 
 type subscriptionStore struct {
 	*Service
+
+	// mutex guards index, the in-memory copy of all stored subscriptions. It
+	// is consulted for every plugin event, so Get never goes to the KV store.
+	mutex sync.RWMutex
+	index subscriptionIndex
 }
 
 var _ SubscriptionStore = (*subscriptionStore)(nil)
 
-func subsKey(e apps.Event) (string, error) {
-	idSuffix := ""
+// subscriptionIndex is keyed by subject, then by the scope ID - the team or
+// the channel ID, as applicable to the subject, or "" for the globally-scoped
+// subjects.
+type subscriptionIndex map[apps.Subject]map[string][]Subscription
+
+func (index subscriptionIndex) get(subject apps.Subject, scopeID string) []Subscription {
+	return index[subject][scopeID]
+}
+
+func (index subscriptionIndex) set(subject apps.Subject, scopeID string, subs []Subscription) {
+	if len(subs) == 0 {
+		delete(index[subject], scopeID)
+		if len(index[subject]) == 0 {
+			delete(index, subject)
+		}
+		return
+	}
+	if index[subject] == nil {
+		index[subject] = map[string][]Subscription{}
+	}
+	index[subject][scopeID] = subs
+}
+
+func makeSubscriptionStore(s *Service, log utils.Logger) (*subscriptionStore, error) {
+	subStore := &subscriptionStore{
+		Service: s,
+		index:   subscriptionIndex{},
+	}
+	err := subStore.Rebuild()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build subscription index")
+	}
+	log.Debugf("loaded subscription index, %v subjects", len(subStore.index))
+	return subStore, nil
+}
+
+// subsScope returns the scope ID of the event: the channel ID for
+// channel-scoped subjects, the team ID for team-scoped ones, and "" for global
+// subjects.
+func subsScope(e apps.Event) (string, error) {
 	switch e.Subject {
 	case apps.SubjectUserCreated,
+		apps.SubjectUserDeactivated,
+		apps.SubjectUserReactivated,
+		apps.SubjectUserUpdated,
+		apps.SubjectUserRoleChanged,
 		apps.SubjectBotJoinedTeam,
-		apps.SubjectBotLeftTeam /*, apps.SubjectBotMentioned */ :
-	// Global subscriptions, no suffix
+		apps.SubjectBotLeftTeam,
+		apps.SubjectBotMentioned:
+		// Global subscriptions, no scope.
+		return "", nil
 
 	case apps.SubjectUserJoinedChannel,
-		apps.SubjectUserLeftChannel /* , apps.SubjectPostCreated */ :
-		idSuffix = "." + e.ChannelID
+		apps.SubjectUserLeftChannel,
+		apps.SubjectPostCreated,
+		apps.SubjectReactionAdded,
+		apps.SubjectReactionRemoved:
+		return e.ChannelID, nil
 
 	case apps.SubjectUserJoinedTeam,
 		apps.SubjectUserLeftTeam,
 		apps.SubjectBotJoinedChannel,
 		apps.SubjectBotLeftChannel,
-		apps.SubjectChannelCreated:
-		idSuffix = "." + e.TeamID
+		apps.SubjectChannelCreated,
+		apps.SubjectChannelArchived,
+		apps.SubjectChannelRestored,
+		apps.SubjectChannelUpdated,
+		apps.SubjectChannelConverted:
+		return e.TeamID, nil
+
 	default:
 		return "", errors.Errorf("Unknown subject %s", e.Subject)
 	}
+}
 
+func subsKey(e apps.Event) (string, error) {
+	scopeID, err := subsScope(e)
+	if err != nil {
+		return "", err
+	}
+	idSuffix := ""
+	if scopeID != "" {
+		idSuffix = "." + scopeID
+	}
 	return KVSubPrefix + string(e.Subject) + idSuffix, nil
 }
 
-func (s subscriptionStore) Get(e apps.Event) ([]Subscription, error) {
-	key, err := subsKey(e)
+func (s *subscriptionStore) Get(e apps.Event) ([]Subscription, error) {
+	scopeID, err := subsScope(e)
 	if err != nil {
 		return nil, err
 	}
 
-	stored := &StoredSubscriptions{}
-	err = s.conf.MattermostAPI().KV.Get(key, &stored)
-	if err != nil {
-		return nil, err
+	s.mutex.RLock()
+	indexed := s.index.get(e.Subject, scopeID)
+	s.mutex.RUnlock()
+
+	if len(indexed) == 0 {
+		return nil, nil
 	}
-	if stored == nil {
-		return nil, utils.ErrNotFound
+	// Return a copy, the callers may modify it before saving.
+	subs := make([]Subscription, len(indexed))
+	copy(subs, indexed)
+	return subs, nil
+}
+
+func (s *subscriptionStore) Owners() map[string]bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	owners := map[string]bool{}
+	for _, scopes := range s.index {
+		for _, subs := range scopes {
+			for _, sub := range subs {
+				owners[sub.OwnerUserID] = true
+			}
+		}
 	}
-	return stored.Subscriptions, nil
+	return owners
 }
 
-func (s subscriptionStore) List() ([]StoredSubscriptions, error) {
-	keys, err := s.conf.MattermostAPI().KV.ListKeys(0, ListKeysPerPage, pluginapi.WithPrefix(KVSubPrefix))
+func (s *subscriptionStore) List() ([]StoredSubscriptions, error) {
+	keys, err := s.listKeys(KVSubPrefix)
 	if err != nil {
 		return nil, err
 	}
@@ -101,19 +210,129 @@ func (s subscriptionStore) List() ([]StoredSubscriptions, error) {
 	return all, nil
 }
 
-func (s subscriptionStore) Save(e apps.Event, subs []Subscription) error {
+// errSubscriptionsUnchanged aborts the atomic update of subscriptions that
+// are neither stored nor to be stored.
+var errSubscriptionsUnchanged = errors.New("subscriptions unchanged")
+
+func (s *subscriptionStore) Update(e apps.Event, modify func([]Subscription) ([]Subscription, error)) ([]Subscription, error) {
+	key, err := subsKey(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []Subscription
+	var modifyErr error
+	err = s.conf.MattermostAPI().KV.SetAtomicWithRetries(key, func(oldValue []byte) (interface{}, error) {
+		stored := StoredSubscriptions{}
+		if len(oldValue) > 0 {
+			if err = json.Unmarshal(oldValue, &stored); err != nil {
+				return nil, err
+			}
+		}
+		updated, modifyErr = modify(stored.Subscriptions)
+		if modifyErr != nil {
+			return nil, modifyErr
+		}
+		switch {
+		case len(updated) > 0:
+			return StoredSubscriptions{
+				Event:         e,
+				Subscriptions: updated,
+			}, nil
+		case len(oldValue) > 0:
+			// Delete the key.
+			return nil, nil
+		default:
+			modifyErr = errSubscriptionsUnchanged
+			return nil, modifyErr
+		}
+	})
+	switch {
+	case modifyErr == errSubscriptionsUnchanged:
+		return updated, s.setIndexed(e, nil)
+	case modifyErr != nil:
+		return nil, modifyErr
+	case err != nil:
+		return nil, err
+	}
+
+	err = s.setIndexed(e, updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, s.publishClusterEvent(ClusterEventSubscriptionsChanged, e)
+}
+
+// Rebuild holds the write lock while it reads the KV store, so that an Update
+// or Reload that completes in the meantime can not be overwritten with the
+// stale data read before it.
+func (s *subscriptionStore) Rebuild() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	all, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	index := subscriptionIndex{}
+	for _, stored := range all {
+		scopeID, err := subsScope(stored.Event)
+		if err != nil {
+			// Ignore subscriptions for unknown (obsolete) subjects.
+			continue
+		}
+		index.set(stored.Event.Subject, scopeID, stored.Subscriptions)
+	}
+	s.index = index
+	return nil
+}
+
+func (s *subscriptionStore) Reload(e apps.Event) error {
+	scopeID, err := subsScope(e)
+	if err != nil {
+		return err
+	}
 	key, err := subsKey(e)
 	if err != nil {
 		return err
 	}
 
-	if len(subs) == 0 {
-		return s.conf.MattermostAPI().KV.Delete(key)
+	// Like Rebuild, read under the lock, so a concurrent Rebuild is not
+	// overwritten with data read before it.
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored := StoredSubscriptions{}
+	err = s.conf.MattermostAPI().KV.Get(key, &stored)
+	if err != nil {
+		return err
 	}
+	s.index.set(e.Subject, scopeID, stored.Subscriptions)
+	return nil
+}
 
-	_, err = s.conf.MattermostAPI().KV.Set(key, StoredSubscriptions{
-		Event:         e,
-		Subscriptions: subs,
-	})
-	return err
+func (s *subscriptionStore) setIndexed(e apps.Event, subs []Subscription) error {
+	scopeID, err := subsScope(e)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.index.set(e.Subject, scopeID, subs)
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *subscriptionStore) publishClusterEvent(id string, e apps.Event) error {
+	if s.cluster == nil {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.cluster.PublishPluginClusterEvent(
+		model.PluginClusterEvent{Id: id, Data: data},
+		model.PluginClusterEventSendOptions{SendType: model.PluginClusterEventSendTypeReliable},
+	)
 }
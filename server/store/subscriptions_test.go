@@ -1,22 +1,36 @@
 package store
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
 func TestSubsKey(t *testing.T) {
 	for subject, expected := range map[apps.Subject]string{
 		apps.SubjectUserCreated:       "sub.user_created",
+		apps.SubjectUserDeactivated:   "sub.user_deactivated",
+		apps.SubjectUserReactivated:   "sub.user_reactivated",
+		apps.SubjectUserUpdated:       "sub.user_updated",
+		apps.SubjectUserRoleChanged:   "sub.user_role_changed",
 		apps.SubjectUserJoinedChannel: "sub.user_joined_channel.channel-id",
 		apps.SubjectUserLeftChannel:   "sub.user_left_channel.channel-id",
 		apps.SubjectUserJoinedTeam:    "sub.user_joined_team.team-id",
 		apps.SubjectUserLeftTeam:      "sub.user_left_team.team-id",
 		apps.SubjectChannelCreated:    "sub.channel_created.team-id",
-		// apps.SubjectPostCreated: "sub.post_created.channel-id",
+		apps.SubjectChannelArchived:   "sub.channel_archived.team-id",
+		apps.SubjectChannelRestored:   "sub.channel_restored.team-id",
+		apps.SubjectChannelUpdated:    "sub.channel_updated.team-id",
+		apps.SubjectChannelConverted:  "sub.channel_converted.team-id",
+		apps.SubjectPostCreated:       "sub.post_created.channel-id",
+		apps.SubjectBotMentioned:      "sub.bot_mentioned",
+		apps.SubjectReactionAdded:     "sub.reaction_added.channel-id",
+		apps.SubjectReactionRemoved:   "sub.reaction_removed.channel-id",
 	} {
 		t.Run(string(subject), func(t *testing.T) {
 			r, err := subsKey(apps.Event{
@@ -29,3 +43,95 @@ func TestSubsKey(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscriptionIndex(t *testing.T) {
+	index := subscriptionIndex{}
+	sub1 := Subscription{AppID: "app1", OwnerUserID: "user1"}
+	sub2 := Subscription{AppID: "app2", OwnerUserID: "user2"}
+
+	index.set(apps.SubjectPostCreated, "channel1", []Subscription{sub1})
+	index.set(apps.SubjectPostCreated, "channel2", []Subscription{sub1, sub2})
+	index.set(apps.SubjectUserCreated, "", []Subscription{sub2})
+
+	require.Equal(t, []Subscription{sub1}, index.get(apps.SubjectPostCreated, "channel1"))
+	require.Equal(t, []Subscription{sub1, sub2}, index.get(apps.SubjectPostCreated, "channel2"))
+	require.Equal(t, []Subscription{sub2}, index.get(apps.SubjectUserCreated, ""))
+	require.Empty(t, index.get(apps.SubjectPostCreated, "channel3"))
+	require.Empty(t, index.get(apps.SubjectChannelCreated, "team1"))
+
+	index.set(apps.SubjectPostCreated, "channel1", nil)
+	require.Empty(t, index.get(apps.SubjectPostCreated, "channel1"))
+	require.Len(t, index[apps.SubjectPostCreated], 1)
+
+	index.set(apps.SubjectPostCreated, "channel2", nil)
+	require.NotContains(t, index, apps.SubjectPostCreated)
+	require.Contains(t, index, apps.SubjectUserCreated)
+}
+
+func TestSubscriptionStoreUpdate(t *testing.T) {
+	service, kv := newTestKVService(&config.Config{})
+	s := &subscriptionStore{
+		Service: service,
+		index:   subscriptionIndex{},
+	}
+	e := apps.Event{Subject: apps.SubjectPostCreated, ChannelID: "channel1"}
+	key, err := subsKey(e)
+	require.NoError(t, err)
+	sub1 := Subscription{AppID: "app1", OwnerUserID: "user1"}
+	sub2 := Subscription{AppID: "app2", OwnerUserID: "user2"}
+	add := func(sub Subscription) func([]Subscription) ([]Subscription, error) {
+		return func(subs []Subscription) ([]Subscription, error) {
+			return append(subs, sub), nil
+		}
+	}
+
+	// Another node adds a subscription while the first attempt is in
+	// progress; the update is retried with it.
+	attempts := 0
+	updated, err := s.Update(e, func(subs []Subscription) ([]Subscription, error) {
+		attempts++
+		if attempts == 1 {
+			data, _ := json.Marshal(StoredSubscriptions{Event: e, Subscriptions: []Subscription{sub2}})
+			kv[key] = data
+		}
+		return append(subs, sub1), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, []Subscription{sub2, sub1}, updated)
+	subs, err := s.Get(e)
+	require.NoError(t, err)
+	require.Equal(t, []Subscription{sub2, sub1}, subs)
+
+	// An error aborts the update.
+	_, err = s.Update(e, func([]Subscription) ([]Subscription, error) {
+		return nil, utils.ErrNotFound
+	})
+	require.Equal(t, utils.ErrNotFound, err)
+	require.Contains(t, kv, key)
+
+	// Removing all subscriptions deletes the key.
+	updated, err = s.Update(e, func([]Subscription) ([]Subscription, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, updated)
+	require.NotContains(t, kv, key)
+	subs, err = s.Get(e)
+	require.NoError(t, err)
+	require.Empty(t, subs)
+
+	// Nothing to remove, nothing stored.
+	_, err = s.Update(e, func([]Subscription) ([]Subscription, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.NotContains(t, kv, key)
+
+	_, err = s.Update(e, add(sub2))
+	require.NoError(t, err)
+	subs, err = s.Get(e)
+	require.NoError(t, err)
+	require.Equal(t, []Subscription{sub2}, subs)
+	require.Equal(t, map[string]bool{"user2": true}, s.Owners())
+}
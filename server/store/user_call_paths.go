@@ -0,0 +1,311 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// UserCallPathStore keeps track of the call paths of the apps' bindings and
+// forms that have been served to each user, so that the user can invoke them.
+// The paths of the in-post bindings are recorded for all users. The paths
+// expire after UserCallPathsTTL unless they are served again. They are shared
+// by all nodes in the cluster, and are cached in memory, as are the lookups of
+// the paths that have not been served.
+type UserCallPathStore interface {
+	// Has returns true if the path has been served by the app to the user, or
+	// to all users.
+	Has(_ apps.AppID, userID, path string) (bool, error)
+
+	// Add records the paths served by the app to the user, or to all users if
+	// userID is empty. At most MaxUserCallPaths paths are kept for each user
+	// of an app, the least recently served ones are dropped first.
+	Add(_ apps.AppID, userID string, paths []string) error
+
+	// Delete removes all paths recorded for the app, on all nodes.
+	Delete(apps.AppID) error
+
+	// Reload drops the cached paths of the app, so that they are reloaded
+	// from the KV store when needed.
+	Reload(apps.AppID)
+}
+
+// MaxUserCallPaths limits the number of recorded call paths for each user of
+// an app. Apps that construct paths dynamically should declare them in the
+// manifest's UserCallPaths instead.
+const MaxUserCallPaths = 1000
+
+// UserCallPathsTTL is how long the served call paths can be invoked for, since
+// they were last served to the user.
+const UserCallPathsTTL = 7 * 24 * time.Hour
+
+// userCallPathsRefresh is how old the recorded paths may get before they are
+// recorded again when served, to extend their expiry.
+const userCallPathsRefresh = UserCallPathsTTL / 2
+
+// userCallPathsMissTTL is how long a path that has not been served is cached
+// as such, before it is looked up in the KV store again.
+const userCallPathsMissTTL = 30 * time.Second
+
+// userCallPathsCacheSize is the number of users' paths cached in memory, on
+// each node.
+const userCallPathsCacheSize = 10000
+
+// userCallPathsCASAttempts limits the number of attempts to update the paths
+// concurrently updated by other requests.
+const userCallPathsCASAttempts = 5
+
+// allUsers is recorded in place of the user ID for the paths served to all
+// users. It can not be a valid user ID.
+const allUsers = "all"
+
+type userCallPathStore struct {
+	*Service
+
+	// cache is keyed by userCallPathsOwner, the values are
+	// *cachedUserCallPaths.
+	cache *lru.Cache
+	now   func() time.Time
+}
+
+var _ UserCallPathStore = (*userCallPathStore)(nil)
+
+type userCallPathsOwner struct {
+	appID  apps.AppID
+	userID string
+}
+
+// userCallPaths maps the paths to the time they were last served, in Unix
+// milliseconds.
+type userCallPaths map[string]int64
+
+type cachedUserCallPaths struct {
+	mutex sync.Mutex
+	paths userCallPaths
+
+	// misses maps the paths that have not been served to the time they were
+	// last looked up.
+	misses map[string]time.Time
+}
+
+func makeUserCallPathStore(s *Service) *userCallPathStore {
+	cache, _ := lru.New(userCallPathsCacheSize)
+	return &userCallPathStore{
+		Service: s,
+		cache:   cache,
+		now:     time.Now,
+	}
+}
+
+// userCallPathsKey returns the key of the paths served to a user. The app ID
+// goes last, user IDs do not contain '.' while app IDs may.
+func userCallPathsKey(owner userCallPathsOwner) string {
+	return KVUserCallPathsPrefix + owner.userID + "." + string(owner.appID)
+}
+
+func (s *userCallPathStore) load(owner userCallPathsOwner) (paths userCallPaths, data []byte, err error) {
+	err = s.conf.MattermostAPI().KV.Get(userCallPathsKey(owner), &data)
+	if err != nil {
+		return nil, nil, err
+	}
+	paths = userCallPaths{}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &paths); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to decode call paths for %s", owner.appID)
+		}
+	}
+	return paths, data, nil
+}
+
+func (s *userCallPathStore) cached(owner userCallPathsOwner) *cachedUserCallPaths {
+	if v, ok := s.cache.Get(owner); ok {
+		return v.(*cachedUserCallPaths)
+	}
+	return nil
+}
+
+func (s *userCallPathStore) setCached(owner userCallPathsOwner, paths userCallPaths) *cachedUserCallPaths {
+	c := &cachedUserCallPaths{
+		paths:  paths,
+		misses: map[string]time.Time{},
+	}
+	s.cache.Add(owner, c)
+	return c
+}
+
+// served checks the cached paths of an owner, loading them from the KV store
+// if they are not cached.
+func (s *userCallPathStore) served(owner userCallPathsOwner, path string, reload bool) (bool, error) {
+	c := s.cached(owner)
+	if c == nil || reload {
+		paths, _, err := s.load(owner)
+		if err != nil {
+			return false, err
+		}
+		c = s.setCached(owner, paths)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	servedAt, ok := c.paths[path]
+	return ok && servedAt > s.now().Add(-UserCallPathsTTL).UnixMilli(), nil
+}
+
+func (s *userCallPathStore) Has(appID apps.AppID, userID, path string) (bool, error) {
+	user := userCallPathsOwner{appID: appID, userID: userID}
+	all := userCallPathsOwner{appID: appID, userID: allUsers}
+	for _, owner := range []userCallPathsOwner{user, all} {
+		ok, err := s.served(owner, path, false)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+
+	// May have been served by another node since cached, unless it was
+	// recently looked up already.
+	now := s.now()
+	if c := s.cached(user); c != nil {
+		c.mutex.Lock()
+		missedAt, missed := c.misses[path]
+		c.mutex.Unlock()
+		if missed && now.Sub(missedAt) < userCallPathsMissTTL {
+			return false, nil
+		}
+	}
+	for _, owner := range []userCallPathsOwner{user, all} {
+		ok, err := s.served(owner, path, true)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+
+	if c := s.cached(user); c != nil {
+		c.mutex.Lock()
+		c.misses[path] = now
+		c.mutex.Unlock()
+	}
+	return false, nil
+}
+
+func (s *userCallPathStore) Add(appID apps.AppID, userID string, paths []string) error {
+	if userID == "" {
+		userID = allUsers
+	}
+	owner := userCallPathsOwner{appID: appID, userID: userID}
+	now := s.now()
+
+	// Nothing to do if all paths are cached as recently served.
+	if c := s.cached(owner); c != nil {
+		c.mutex.Lock()
+		fresh := true
+		for _, path := range paths {
+			delete(c.misses, path)
+			if c.paths[path] <= now.Add(-userCallPathsRefresh).UnixMilli() {
+				fresh = false
+			}
+		}
+		c.mutex.Unlock()
+		if fresh {
+			return nil
+		}
+	}
+
+	for i := 0; i < userCallPathsCASAttempts; i++ {
+		stored, prev, err := s.load(owner)
+		if err != nil {
+			return err
+		}
+
+		updated := userCallPaths{}
+		expireBefore := now.Add(-UserCallPathsTTL).UnixMilli()
+		for path, servedAt := range stored {
+			if servedAt > expireBefore {
+				updated[path] = servedAt
+			}
+		}
+		for _, path := range paths {
+			updated[path] = now.UnixMilli()
+		}
+		updated.dropOldest(MaxUserCallPaths)
+
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		ok, err := s.conf.MattermostAPI().KV.Set(userCallPathsKey(owner), data,
+			pluginapi.SetAtomic(prev), pluginapi.SetExpiry(UserCallPathsTTL))
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.setCached(owner, updated)
+			return nil
+		}
+	}
+	return errors.Errorf("failed to update call paths for %s, too many concurrent updates", appID)
+}
+
+// dropOldest drops the least recently served paths past max.
+func (paths userCallPaths) dropOldest(max int) {
+	if len(paths) <= max {
+		return
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return paths[sorted[i]] < paths[sorted[j]]
+	})
+	for _, path := range sorted[:len(sorted)-max] {
+		delete(paths, path)
+	}
+}
+
+func (s *userCallPathStore) Delete(appID apps.AppID) error {
+	keys, err := s.listKeys(KVUserCallPathsPrefix)
+	if err != nil {
+		return err
+	}
+	mm := s.conf.MattermostAPI()
+	for _, key := range keys {
+		// The user ID is followed by the exact app ID.
+		userID := strings.TrimPrefix(key, KVUserCallPathsPrefix)
+		if i := strings.Index(userID, "."); i < 0 || userID[i+1:] != string(appID) {
+			continue
+		}
+		if err = mm.KV.Delete(key); err != nil {
+			return err
+		}
+	}
+	s.Reload(appID)
+
+	if s.cluster == nil {
+		return nil
+	}
+	return s.cluster.PublishPluginClusterEvent(
+		model.PluginClusterEvent{Id: ClusterEventUserCallPathsDeleted, Data: []byte(appID)},
+		model.PluginClusterEventSendOptions{SendType: model.PluginClusterEventSendTypeReliable},
+	)
+}
+
+func (s *userCallPathStore) Reload(appID apps.AppID) {
+	for _, key := range s.cache.Keys() {
+		if owner, ok := key.(userCallPathsOwner); ok && owner.appID == appID {
+			s.cache.Remove(key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+func TestUserCallPathStore(t *testing.T) {
+	s, kv := newTestKVService(&config.Config{})
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	ucp := makeUserCallPathStore(s)
+	ucp.now = func() time.Time { return now }
+
+	has := func(userID, path string) bool {
+		ok, err := ucp.Has("app.1", userID, path)
+		require.NoError(t, err)
+		return ok
+	}
+
+	require.NoError(t, ucp.Add("app.1", "user1", []string{"/one"}))
+	require.NoError(t, ucp.Add("app.1", "", []string{"/all"}))
+	require.Len(t, kv, 2)
+	require.Contains(t, kv, "ucp.user1.app.1")
+	require.Contains(t, kv, "ucp.all.app.1")
+
+	require.True(t, has("user1", "/one"))
+	require.True(t, has("user1", "/all"))
+	require.True(t, has("user2", "/all"))
+	require.False(t, has("user2", "/one"))
+	ok, err := ucp.Has("app.2", "user1", "/one")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// A path recorded by another node is not looked up again until the cached
+	// miss expires.
+	data, err := json.Marshal(userCallPaths{"/one": now.UnixMilli()})
+	require.NoError(t, err)
+	kv["ucp.user2.app.1"] = data
+	require.False(t, has("user2", "/one"))
+	now = now.Add(userCallPathsMissTTL)
+	require.True(t, has("user2", "/one"))
+
+	// Recording a path clears the cached miss.
+	require.False(t, has("user1", "/two"))
+	require.NoError(t, ucp.Add("app.1", "user1", []string{"/two"}))
+	require.True(t, has("user1", "/two"))
+
+	// The least recently served paths are dropped past MaxUserCallPaths.
+	for i := 0; i < MaxUserCallPaths; i++ {
+		now = now.Add(time.Millisecond)
+		require.NoError(t, ucp.Add("app.1", "user1", []string{fmt.Sprintf("/many/%v", i)}))
+	}
+	require.False(t, has("user1", "/one"))
+	require.False(t, has("user1", "/two"))
+	require.True(t, has("user1", "/many/0"))
+
+	// The paths expire unless served again, their expiry is extended once
+	// they get older than userCallPathsRefresh.
+	now = now.Add(userCallPathsRefresh)
+	require.NoError(t, ucp.Add("app.1", "user1", []string{"/many/0"}))
+	now = now.Add(UserCallPathsTTL - time.Millisecond)
+	require.True(t, has("user1", "/many/0"))
+	require.False(t, has("user1", "/many/1"))
+	require.False(t, has("user2", "/all"))
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// userStateShards is the number of KV keys the users' state is split across,
+// to keep the individual values small on large instances.
+const userStateShards = 16
+
+// UserState is the part of a user's record that is compared to detect the
+// user lifecycle changes.
+type UserState struct {
+	UpdateAt int64  `json:"u,omitempty"`
+	DeleteAt int64  `json:"d,omitempty"`
+	Roles    string `json:"r,omitempty"`
+}
+
+// UserStateStore keeps the state of all users as of the last check for the
+// user lifecycle changes, so that the check can continue on any node in the
+// cluster.
+type UserStateStore interface {
+	// Load returns the users' state keyed by the user ID, or nil if none was
+	// saved within maxAge.
+	Load(maxAge time.Duration) (map[string]UserState, error)
+
+	// Save replaces the stored state.
+	Save(map[string]UserState) error
+}
+
+type userStateStore struct {
+	*Service
+	now func() time.Time
+}
+
+var _ UserStateStore = (*userStateStore)(nil)
+
+type storedUserStates struct {
+	SavedAt int64                `json:"saved_at"`
+	Users   map[string]UserState `json:"users"`
+}
+
+func userStateShard(userID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(userID))
+	return int(h.Sum32() % userStateShards)
+}
+
+func userStateKey(shard int) string {
+	return KVUserStatePrefix + strconv.Itoa(shard)
+}
+
+func (s *userStateStore) Load(maxAge time.Duration) (map[string]UserState, error) {
+	mm := s.conf.MattermostAPI()
+	minSavedAt := s.now().Add(-maxAge).UnixMilli()
+	states := map[string]UserState{}
+	for shard := 0; shard < userStateShards; shard++ {
+		stored := storedUserStates{}
+		if err := mm.KV.Get(userStateKey(shard), &stored); err != nil {
+			return nil, err
+		}
+		// The shards are saved together, a missing or a stale one means the
+		// whole state is not usable.
+		if stored.SavedAt < minSavedAt {
+			return nil, nil
+		}
+		for userID, state := range stored.Users {
+			states[userID] = state
+		}
+	}
+	return states, nil
+}
+
+func (s *userStateStore) Save(states map[string]UserState) error {
+	savedAt := s.now().UnixMilli()
+	shards := make([]storedUserStates, userStateShards)
+	for i := range shards {
+		shards[i] = storedUserStates{
+			SavedAt: savedAt,
+			Users:   map[string]UserState{},
+		}
+	}
+	for userID, state := range states {
+		shards[userStateShard(userID)].Users[userID] = state
+	}
+
+	mm := s.conf.MattermostAPI()
+	for i, stored := range shards {
+		if _, err := mm.KV.Set(userStateKey(i), stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
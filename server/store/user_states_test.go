@@ -0,0 +1,39 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+func TestUserStateStore(t *testing.T) {
+	s, kv := newTestKVService(&config.Config{})
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	us := &userStateStore{Service: s, now: func() time.Time { return now }}
+
+	loaded, err := us.Load(time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+
+	states := map[string]UserState{}
+	for _, id := range []string{"user1", "user2", "user3", "user4"} {
+		states[id] = UserState{UpdateAt: 1, Roles: "system_user"}
+	}
+	states["user5"] = UserState{UpdateAt: 2, DeleteAt: 2}
+	require.NoError(t, us.Save(states))
+	require.Len(t, kv, userStateShards)
+
+	now = now.Add(time.Minute)
+	loaded, err = us.Load(time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, states, loaded)
+
+	// Stale state is not returned.
+	now = now.Add(time.Second)
+	loaded, err = us.Load(time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+}
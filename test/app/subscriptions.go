@@ -19,6 +19,18 @@ var allSubjects = map[apps.Subject]apps.Expand{
 		User: apps.ExpandAll,
 		Team: apps.ExpandAll,
 	},
+	apps.SubjectUserDeactivated: {
+		User: apps.ExpandAll,
+	},
+	apps.SubjectUserReactivated: {
+		User: apps.ExpandAll,
+	},
+	apps.SubjectUserUpdated: {
+		User: apps.ExpandAll,
+	},
+	apps.SubjectUserRoleChanged: {
+		User: apps.ExpandAll,
+	},
 	apps.SubjectBotJoinedChannel: {
 		User:          apps.ExpandAll,
 		Channel:       apps.ExpandAll,
@@ -39,11 +51,11 @@ var allSubjects = map[apps.Subject]apps.Expand{
 		Team:       apps.ExpandAll,
 		TeamMember: apps.ExpandAll,
 	},
-	// apps.SubjectBotMentioned: {
-	// 	Channel:  apps.ExpandAll,
-	// 	Post:     apps.ExpandSummary,
-	// 	RootPost: apps.ExpandSummary,
-	// },
+	apps.SubjectBotMentioned: {
+		Channel:  apps.ExpandAll,
+		Post:     apps.ExpandSummary,
+		RootPost: apps.ExpandSummary,
+	},
 	apps.SubjectUserJoinedChannel: {
 		User:          apps.ExpandAll,
 		Channel:       apps.ExpandAll,
@@ -54,10 +66,39 @@ var allSubjects = map[apps.Subject]apps.Expand{
 		Channel:       apps.ExpandAll,
 		ChannelMember: apps.ExpandAll,
 	},
-	// apps.SubjectPostCreated: {
-	// 	Post:    apps.ExpandAll,
-	// 	Channel: apps.ExpandAll,
-	// },
+	apps.SubjectPostCreated: {
+		Post:    apps.ExpandAll,
+		Channel: apps.ExpandAll,
+	},
+	apps.SubjectReactionAdded: {
+		User:    apps.ExpandSummary,
+		Post:    apps.ExpandSummary,
+		Channel: apps.ExpandAll,
+	},
+	apps.SubjectReactionRemoved: {
+		User:    apps.ExpandSummary,
+		Post:    apps.ExpandSummary,
+		Channel: apps.ExpandAll,
+	},
+	apps.SubjectChannelArchived: {
+		Channel:         apps.ExpandAll,
+		PreviousChannel: apps.ExpandSummary,
+		User:            apps.ExpandSummary,
+	},
+	apps.SubjectChannelRestored: {
+		Channel: apps.ExpandAll,
+		User:    apps.ExpandSummary,
+	},
+	apps.SubjectChannelUpdated: {
+		Channel:         apps.ExpandAll,
+		PreviousChannel: apps.ExpandAll,
+		User:            apps.ExpandSummary,
+	},
+	apps.SubjectChannelConverted: {
+		Channel:         apps.ExpandAll,
+		PreviousChannel: apps.ExpandSummary,
+		User:            apps.ExpandSummary,
+	},
 	apps.SubjectUserJoinedTeam: {
 		User:       apps.ExpandAll,
 		Team:       apps.ExpandAll,
@@ -127,14 +168,21 @@ func handleSubscription(creq *apps.CallRequest, subscribe bool) apps.CallRespons
 
 	switch subject {
 	case apps.SubjectUserJoinedChannel,
-		apps.SubjectUserLeftChannel:
+		apps.SubjectUserLeftChannel,
+		apps.SubjectPostCreated,
+		apps.SubjectReactionAdded,
+		apps.SubjectReactionRemoved:
 		sub.ChannelID = creq.Context.Channel.Id
 
 	case apps.SubjectUserJoinedTeam,
 		apps.SubjectUserLeftTeam,
 		apps.SubjectBotJoinedChannel,
 		apps.SubjectBotLeftChannel,
-		apps.SubjectChannelCreated:
+		apps.SubjectChannelCreated,
+		apps.SubjectChannelArchived,
+		apps.SubjectChannelRestored,
+		apps.SubjectChannelUpdated,
+		apps.SubjectChannelConverted:
 		sub.TeamID = creq.Context.Team.Id
 	}
 
@@ -206,6 +206,36 @@ func (th *Helper) createTestTeam() *model.Team {
 	return team
 }
 
+func (th *Helper) createTestPost(client *model.Client4, channelID, message string) *model.Post {
+	post, resp, err := client.CreatePost(&model.Post{
+		ChannelId: channelID,
+		Message:   message,
+	})
+	require.NoError(th, err)
+	api4.CheckCreatedStatus(th, resp)
+	th.Logf("created test post %s in channel %s", post.Id, channelID)
+	return post
+}
+
+func (th *Helper) addTestReaction(client *model.Client4, userID, postID, emojiName string) *model.Reaction {
+	reaction, resp, err := client.SaveReaction(&model.Reaction{
+		UserId:    userID,
+		PostId:    postID,
+		EmojiName: emojiName,
+	})
+	require.NoError(th, err)
+	api4.CheckOKStatus(th, resp)
+	th.Logf("added test reaction :%s: to post %s", emojiName, postID)
+	return reaction
+}
+
+func (th *Helper) removeTestReaction(client *model.Client4, reaction *model.Reaction) {
+	resp, err := client.DeleteReaction(reaction)
+	require.NoError(th, err)
+	api4.CheckOKStatus(th, resp)
+	th.Logf("removed test reaction :%s: from post %s", reaction.EmojiName, reaction.PostId)
+}
+
 func (th *Helper) addChannelMember(channel *model.Channel, user *model.User) *model.ChannelMember {
 	cm, resp, err := th.ServerTestHelper.SystemAdminClient.AddChannelMember(channel.Id, user.Id)
 	require.NoError(th, err)
@@ -16,6 +16,7 @@ func expandEverything(level apps.ExpandLevel) apps.Expand {
 		ActingUserAccessToken: level,
 		Locale:                level,
 		Channel:               level,
+		PreviousChannel:       level,
 		ChannelMember:         level,
 		Team:                  level,
 		TeamMember:            level,
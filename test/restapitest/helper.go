@@ -154,6 +154,10 @@ func (th *Helper) verifyExpandedContext(level apps.ExpandLevel, app *apps.App, a
 	}
 	expected.ActingUserAccessToken, got.ActingUserAccessToken = "", ""
 
+	// Reaction is included regardless of the expand level.
+	th.requireEqualReaction(expected.Reaction, got.Reaction)
+	expected.Reaction, got.Reaction = nil, nil
+
 	if level == apps.ExpandNone {
 		// make sure nothing else is set.
 		require.EqualValues(th, apps.ExpandedContext{
@@ -170,6 +174,7 @@ func (th *Helper) verifyExpandedContext(level apps.ExpandLevel, app *apps.App, a
 	th.requireEqualApp(level, asSystemAdmin, expected.App, got.App)
 	th.requireEqualUser(level, expected.ActingUser, got.ActingUser)
 	th.requireEqualChannel(level, expected.Channel, got.Channel)
+	th.requireEqualChannel(level, expected.PreviousChannel, got.PreviousChannel)
 	th.requireEqualChannelMember(level, expected.ChannelMember, got.ChannelMember)
 	th.requireEqualTeam(level, expected.Team, got.Team)
 	th.requireEqualTeamMember(level, expected.TeamMember, got.TeamMember)
@@ -178,6 +183,18 @@ func (th *Helper) verifyExpandedContext(level apps.ExpandLevel, app *apps.App, a
 	th.requireEqualUser(level, expected.User, got.User)
 }
 
+func (th *Helper) requireEqualReaction(expected, got *model.Reaction) {
+	if expected == nil {
+		require.Empty(th, got, "Reaction")
+		return
+	}
+	require.NotNil(th, got)
+	require.Equal(th, expected.UserId, got.UserId)
+	require.Equal(th, expected.PostId, got.PostId)
+	require.Equal(th, expected.ChannelId, got.ChannelId)
+	require.Equal(th, expected.EmojiName, got.EmojiName)
+}
+
 func (th *Helper) requireEqualUser(level apps.ExpandLevel, expected, got *model.User) {
 	if expected == nil || expected.Id == "" {
 		require.Empty(th, got, "User")
@@ -116,7 +116,15 @@ func testNotify(th *Helper) {
 		"user_left_channel":   notifyUserLeftChannel(th),
 		"user_left_team":      notifyUserLeftTeam(th),
 		"channel_created":     notifyChannelCreated(th),
+		"channel_archived":    notifyChannelArchived(th),
+		"channel_restored":    notifyChannelRestored(th),
+		"channel_updated":     notifyChannelUpdated(th),
+		"channel_converted":   notifyChannelConverted(th),
 		"user_created":        notifyUserCreated(th),
+		"post_created":        notifyPostCreated(th),
+		"bot_mentioned":       notifyBotMentioned(th),
+		"reaction_added":      notifyReactionAdded(th),
+		"reaction_removed":    notifyReactionRemoved(th),
 	} {
 		th.Run(name, func(th *Helper) {
 			forExpandClientCombinations(th, th.LastInstalledBotUser, tc.expandCombinations, tc.except,
@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package restapitest
+
+import (
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// notifyBotMentioned creates a test channel in a new test team. Bot and user
+// are added as members of the team and the channel. User then creates a post
+// that mentions the bot to trigger. user2 has no access to the channel and the
+// post, and is excluded from the test.
+func notifyBotMentioned(th *Helper) *notifyTestCase {
+	return &notifyTestCase{
+		except: []appClient{
+			th.asUser2,
+		},
+		init: func(th *Helper) apps.ExpandedContext {
+			data := apps.ExpandedContext{
+				Team: th.createTestTeam(),
+				User: th.ServerTestHelper.BasicUser,
+			}
+			th.addTeamMember(data.Team, th.LastInstalledBotUser)
+			data.TeamMember = th.addTeamMember(data.Team, th.ServerTestHelper.BasicUser)
+
+			data.Channel = th.createTestChannel(th.ServerTestHelper.SystemAdminClient, data.Team.Id)
+			th.addChannelMember(data.Channel, th.LastInstalledBotUser)
+			th.addChannelMember(data.Channel, th.ServerTestHelper.BasicUser)
+			return data
+		},
+		event: func(th *Helper, data apps.ExpandedContext) apps.Event {
+			return apps.Event{
+				Subject: apps.SubjectBotMentioned,
+			}
+		},
+		trigger: func(th *Helper, data apps.ExpandedContext) apps.ExpandedContext {
+			data.Post = th.createTestPost(th.ServerTestHelper.Client, data.Channel.Id, "hello @"+th.LastInstalledBotUser.Username)
+			return data
+		},
+		expected: func(th *Helper, level apps.ExpandLevel, appclient appClient, data apps.ExpandedContext) apps.ExpandedContext {
+			return apps.ExpandedContext{
+				User:          data.User,
+				Team:          data.Team,
+				TeamMember:    data.TeamMember,
+				Channel:       th.getChannel(data.Channel.Id),
+				ChannelMember: th.getChannelMember(data.Channel.Id, data.User.Id),
+				Post:          data.Post,
+			}
+		},
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package restapitest
+
+import (
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/api4"
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// notifyChannelLifecycle creates a (private) test channel in a new test team.
+// Bot and user are added as members of the team and the channel, so that they
+// can receive the notifications. The admin then makes a change to the channel
+// to trigger. Since user2 is not a member of the team, it can not subscribe and
+// is excluded from the test.
+func notifyChannelLifecycle(th *Helper, subject apps.Subject, change func(*Helper, *model.Channel), previous func(*Helper, *model.Channel) *model.Channel) *notifyTestCase {
+	return &notifyTestCase{
+		except: []appClient{
+			th.asUser2,
+		},
+		init: func(th *Helper) apps.ExpandedContext {
+			data := apps.ExpandedContext{
+				Team: th.createTestTeam(),
+				User: th.ServerTestHelper.SystemAdminUser,
+			}
+			th.addTeamMember(data.Team, th.LastInstalledBotUser)
+			th.addTeamMember(data.Team, th.ServerTestHelper.BasicUser)
+
+			data.Channel = th.createTestChannel(th.ServerTestHelper.SystemAdminClient, data.Team.Id)
+			th.addChannelMember(data.Channel, th.LastInstalledBotUser)
+			th.addChannelMember(data.Channel, th.ServerTestHelper.BasicUser)
+			return data
+		},
+		event: func(th *Helper, data apps.ExpandedContext) apps.Event {
+			return apps.Event{
+				Subject: subject,
+				TeamID:  data.Team.Id,
+			}
+		},
+		trigger: func(th *Helper, data apps.ExpandedContext) apps.ExpandedContext {
+			change(th, data.Channel)
+			return data
+		},
+		expected: func(th *Helper, level apps.ExpandLevel, appclient appClient, data apps.ExpandedContext) apps.ExpandedContext {
+			channel := th.getChannel(data.Channel.Id)
+			ec := apps.ExpandedContext{
+				User:          data.User,
+				Team:          data.Team,
+				TeamMember:    th.getTeamMember(data.Team.Id, data.User.Id),
+				Channel:       channel,
+				ChannelMember: th.getChannelMember(data.Channel.Id, data.User.Id),
+			}
+			if previous != nil {
+				ec.PreviousChannel = previous(th, channel)
+			}
+			return ec
+		},
+	}
+}
+
+func notifyChannelArchived(th *Helper) *notifyTestCase {
+	return notifyChannelLifecycle(th, apps.SubjectChannelArchived,
+		func(th *Helper, channel *model.Channel) {
+			_, err := th.ServerTestHelper.SystemAdminClient.DeleteChannel(channel.Id)
+			require.NoError(th, err)
+			// Cleanups run in reverse order, restore the channel so that it can
+			// be deleted by createTestChannel's cleanup.
+			th.Cleanup(func() {
+				_, _, err := th.ServerTestHelper.SystemAdminClient.RestoreChannel(channel.Id)
+				require.NoError(th, err)
+			})
+		},
+		func(th *Helper, channel *model.Channel) *model.Channel {
+			before := *channel
+			before.DeleteAt = 0
+			return &before
+		})
+}
+
+func notifyChannelRestored(th *Helper) *notifyTestCase {
+	return notifyChannelLifecycle(th, apps.SubjectChannelRestored,
+		func(th *Helper, channel *model.Channel) {
+			_, err := th.ServerTestHelper.SystemAdminClient.DeleteChannel(channel.Id)
+			require.NoError(th, err)
+			_, resp, err := th.ServerTestHelper.SystemAdminClient.RestoreChannel(channel.Id)
+			require.NoError(th, err)
+			api4.CheckOKStatus(th, resp)
+		},
+		nil)
+}
+
+func notifyChannelUpdated(th *Helper) *notifyTestCase {
+	oldName := ""
+	return notifyChannelLifecycle(th, apps.SubjectChannelUpdated,
+		func(th *Helper, channel *model.Channel) {
+			oldName = channel.DisplayName
+			newName := "Renamed " + channel.Name
+			_, resp, err := th.ServerTestHelper.SystemAdminClient.PatchChannel(channel.Id, &model.ChannelPatch{
+				DisplayName: &newName,
+			})
+			require.NoError(th, err)
+			api4.CheckOKStatus(th, resp)
+		},
+		func(th *Helper, channel *model.Channel) *model.Channel {
+			before := *channel
+			before.DisplayName = oldName
+			return &before
+		})
+}
+
+func notifyChannelConverted(th *Helper) *notifyTestCase {
+	return notifyChannelLifecycle(th, apps.SubjectChannelConverted,
+		func(th *Helper, channel *model.Channel) {
+			_, resp, err := th.ServerTestHelper.SystemAdminClient.UpdateChannelPrivacy(channel.Id, model.ChannelTypeOpen)
+			require.NoError(th, err)
+			api4.CheckOKStatus(th, resp)
+		},
+		func(th *Helper, channel *model.Channel) *model.Channel {
+			before := *channel
+			before.Type = model.ChannelTypePrivate
+			return &before
+		})
+}
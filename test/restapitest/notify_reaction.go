@@ -0,0 +1,85 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package restapitest
+
+import (
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// notifyReactionAdded creates a test channel in a new test team, and a post in
+// it. Bot and user are added as members of the team and the channel. User then
+// reacts to the post to trigger. Since user2 is not a member of the channel, it
+// can not subscribe and is excluded from the test.
+func notifyReactionAdded(th *Helper) *notifyTestCase {
+	return &notifyTestCase{
+		except: []appClient{
+			th.asUser2,
+		},
+		init: initNotifyReaction,
+		event: func(th *Helper, data apps.ExpandedContext) apps.Event {
+			return apps.Event{
+				Subject:   apps.SubjectReactionAdded,
+				ChannelID: data.Channel.Id,
+			}
+		},
+		trigger: func(th *Helper, data apps.ExpandedContext) apps.ExpandedContext {
+			data.Reaction = th.addTestReaction(th.ServerTestHelper.Client, data.User.Id, data.Post.Id, "smile")
+			return data
+		},
+		expected: expectedNotifyReaction,
+	}
+}
+
+// notifyReactionRemoved is like notifyReactionAdded, but the user reacts to
+// the post in init, and then removes the reaction to trigger.
+func notifyReactionRemoved(th *Helper) *notifyTestCase {
+	return &notifyTestCase{
+		except: []appClient{
+			th.asUser2,
+		},
+		init: func(th *Helper) apps.ExpandedContext {
+			data := initNotifyReaction(th)
+			data.Reaction = th.addTestReaction(th.ServerTestHelper.Client, data.User.Id, data.Post.Id, "smile")
+			return data
+		},
+		event: func(th *Helper, data apps.ExpandedContext) apps.Event {
+			return apps.Event{
+				Subject:   apps.SubjectReactionRemoved,
+				ChannelID: data.Channel.Id,
+			}
+		},
+		trigger: func(th *Helper, data apps.ExpandedContext) apps.ExpandedContext {
+			th.removeTestReaction(th.ServerTestHelper.Client, data.Reaction)
+			return data
+		},
+		expected: expectedNotifyReaction,
+	}
+}
+
+func initNotifyReaction(th *Helper) apps.ExpandedContext {
+	data := apps.ExpandedContext{
+		Team: th.createTestTeam(),
+		User: th.ServerTestHelper.BasicUser,
+	}
+	th.addTeamMember(data.Team, th.LastInstalledBotUser)
+	data.TeamMember = th.addTeamMember(data.Team, th.ServerTestHelper.BasicUser)
+
+	data.Channel = th.createTestChannel(th.ServerTestHelper.SystemAdminClient, data.Team.Id)
+	th.addChannelMember(data.Channel, th.LastInstalledBotUser)
+	th.addChannelMember(data.Channel, th.ServerTestHelper.BasicUser)
+	data.Post = th.createTestPost(th.ServerTestHelper.SystemAdminClient, data.Channel.Id, "test post")
+	return data
+}
+
+func expectedNotifyReaction(th *Helper, level apps.ExpandLevel, appclient appClient, data apps.ExpandedContext) apps.ExpandedContext {
+	return apps.ExpandedContext{
+		User:          data.User,
+		Team:          data.Team,
+		TeamMember:    data.TeamMember,
+		Channel:       th.getChannel(data.Channel.Id),
+		ChannelMember: th.getChannelMember(data.Channel.Id, data.User.Id),
+		Post:          data.Post,
+		Reaction:      data.Reaction,
+	}
+}
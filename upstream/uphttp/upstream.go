@@ -19,6 +19,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type Upstream struct {
@@ -49,8 +50,11 @@ func AppRootURL(app apps.App, _ string) (string, error) {
 
 func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
 	if async {
+		// The caller does not wait for the app, so the call outlives ctx; only
+		// the trace is carried over.
+		bgCtx := tracing.ContextWithSpanContext(context.Background(), tracing.SpanContextFromContext(ctx))
 		go func() {
-			resp, _ := u.invoke(context.Background(), creq.Context.ExpandedContext.BotUserID, app, creq)
+			resp, _ := u.invoke(bgCtx, creq.Context.ExpandedContext.BotUserID, app, creq)
 			if resp != nil {
 				resp.Body.Close()
 			}
@@ -91,6 +95,10 @@ func (u *Upstream) invoke(ctx context.Context, fromMattermostUserID string, app
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	for k, v := range creq.CallHeaders() {
+		req.Header.Set(k, v)
+	}
+	tracing.Inject(ctx, req.Header)
 
 	// TODO: find a better way to control the use of JWT that both OpenFaaS and
 	// HTTP can share. For now, hard-limit the use of JWT to the HTTP gateway
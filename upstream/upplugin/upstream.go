@@ -16,6 +16,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type Upstream struct {
@@ -33,8 +34,11 @@ func NewUpstream(api PluginHTTPAPI) *Upstream {
 
 func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
 	if async {
+		// The caller does not wait for the app, so the call outlives ctx; only
+		// the trace is carried over.
+		bgCtx := tracing.ContextWithSpanContext(context.Background(), tracing.SpanContextFromContext(ctx))
 		go func() {
-			resp, _ := u.invoke(context.Background(), app, creq)
+			resp, _ := u.invoke(bgCtx, app, creq)
 			if resp != nil {
 				resp.Body.Close()
 			}
@@ -54,11 +58,11 @@ func (u *Upstream) invoke(ctx context.Context, app apps.App, creq apps.CallReque
 		return nil, errors.New("app is not available as type plugin")
 	}
 
-	return u.post(ctx, path.Join("/"+app.Manifest.Plugin.PluginID, apps.PluginAppPath, creq.Path), creq)
+	return u.post(ctx, path.Join("/"+app.Manifest.Plugin.PluginID, apps.PluginAppPath, creq.Path), creq, creq.CallHeaders())
 }
 
 // post does not close resp.Body, it's the caller's responsibility
-func (u *Upstream) post(ctx context.Context, url string, msg interface{}) (*http.Response, error) {
+func (u *Upstream) post(ctx context.Context, url string, msg interface{}, headers map[string]string) (*http.Response, error) {
 	piper, pipew := io.Pipe()
 	go func() {
 		encodeErr := json.NewEncoder(pipew).Encode(msg)
@@ -73,6 +77,10 @@ func (u *Upstream) post(ctx context.Context, url string, msg interface{}) (*http
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := u.httpClient.Do(req)
 	switch {
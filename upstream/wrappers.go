@@ -32,3 +32,13 @@ func Call(ctx context.Context, u Upstream, app apps.App, creq apps.CallRequest)
 	}
 	return cr, nil
 }
+
+// NotifyAndWait is like Notify, but waits for the app to acknowledge the
+// notification, so that the delivery failures can be detected and retried.
+func NotifyAndWait(ctx context.Context, u Upstream, app apps.App, creq apps.CallRequest) error {
+	r, err := u.Roundtrip(ctx, app, creq, false)
+	if r != nil {
+		r.Close()
+	}
+	return err
+}
@@ -9,6 +9,7 @@ var ErrAlreadyExists = errors.New("already exists")
 var ErrForbidden = errors.New("forbidden")
 var ErrInvalid = errors.New("invalid input")
 var ErrNotFound = errors.New("not found")
+var ErrTooManyRequests = errors.New("too many requests")
 var ErrUnauthorized = errors.New("unauthorized")
 
 func NewError(source error, args ...interface{}) error {
@@ -67,6 +67,8 @@ func ErrorToStatus(err error) int {
 		return http.StatusNotFound
 	case utils.ErrInvalid:
 		return http.StatusBadRequest
+	case utils.ErrTooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
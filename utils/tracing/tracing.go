@@ -0,0 +1,285 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package tracing records OpenTelemetry-compatible spans for the stages of the
+// calls to the apps, propagates them to the apps with the W3C Trace Context
+// "traceparent" header, and exports them to an OTLP/HTTP collector. Until an
+// exporter is configured tracing is a no-op: Start returns a nil *Span, and all
+// *Span methods are safe to call on nil.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TraceParentHeader is the W3C Trace Context header that carries the span of
+// the caller.
+const TraceParentHeader = "traceparent"
+
+type TraceID [16]byte
+type SpanID [8]byte
+
+// TraceFlags are the W3C Trace Context trace flags. Only FlagsSampled is
+// defined, the other bits are not propagated.
+type TraceFlags byte
+
+// FlagsSampled is set if the caller may have recorded the trace. The spans of
+// the traces that are not sampled are propagated, but not exported.
+const FlagsSampled = TraceFlags(0x01)
+
+// SpanContext identifies a span, and is propagated to the children spans, and
+// to the apps.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   TraceFlags
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagsSampled != 0
+}
+
+// TraceParent formats the span context as a version 00 W3C "traceparent"
+// header value.
+func (sc SpanContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + hex.EncodeToString([]byte{byte(sc.Flags & FlagsSampled)})
+}
+
+// ParseTraceParent parses a W3C "traceparent" header value. The values of the
+// future versions are parsed as version 00, the fields that follow the flags
+// are ignored.
+func ParseTraceParent(s string) (SpanContext, error) {
+	sc := SpanContext{}
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return sc, errors.Errorf("invalid traceparent %q", s)
+	}
+	version, ok := decodeLowerHex(parts[0], 1)
+	if !ok || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, errors.Errorf("invalid version in traceparent %q", s)
+	}
+	traceID, ok := decodeLowerHex(parts[1], len(sc.TraceID))
+	if !ok {
+		return sc, errors.Errorf("invalid trace ID in traceparent %q", s)
+	}
+	spanID, ok := decodeLowerHex(parts[2], len(sc.SpanID))
+	if !ok {
+		return sc, errors.Errorf("invalid span ID in traceparent %q", s)
+	}
+	flags, ok := decodeLowerHex(parts[3], 1)
+	if !ok {
+		return sc, errors.Errorf("invalid flags in traceparent %q", s)
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = TraceFlags(flags[0]) & FlagsSampled
+	if !sc.IsValid() {
+		return sc, errors.Errorf("invalid traceparent %q", s)
+	}
+	return sc, nil
+}
+
+// decodeLowerHex decodes n bytes from s, that must be lowercase hex, as
+// required by the W3C Trace Context.
+func decodeLowerHex(s string, n int) ([]byte, bool) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, false
+	}
+	data, err := hex.DecodeString(s)
+	return data, err == nil
+}
+
+type spanContextKey struct{}
+
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span in ctx,
+// or an invalid one if there is none.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Inject sets the "traceparent" header of an outgoing request to the current
+// span in ctx, if any.
+func Inject(ctx context.Context, header http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		header.Set(TraceParentHeader, sc.TraceParent())
+	}
+}
+
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData is a completed span, as passed to the Exporter.
+type SpanData struct {
+	Name        string
+	SpanContext SpanContext
+	Parent      SpanID
+	Start       time.Time
+	End         time.Time
+	Attributes  []Attribute
+	Error       string
+}
+
+// Exporter receives the ended spans. Export must not block.
+type Exporter interface {
+	Export(SpanData)
+	Shutdown()
+}
+
+type Span struct {
+	exporter Exporter
+	mutex    sync.Mutex
+	data     SpanData
+	ended    bool
+}
+
+var (
+	exporterMutex      sync.RWMutex
+	exporter           Exporter
+	configuredEndpoint string
+)
+
+// Start starts a new span, a child of the current span in ctx if there is one.
+// The returned context carries the new span. The children spans are sampled if
+// their parent is, new traces are always sampled.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	exporterMutex.RLock()
+	e := exporter
+	exporterMutex.RUnlock()
+	if e == nil {
+		return ctx, nil
+	}
+
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{
+		TraceID: parent.TraceID,
+		Flags:   parent.Flags,
+	}
+	if !parent.IsValid() {
+		_, _ = rand.Read(sc.TraceID[:])
+		sc.Flags = FlagsSampled
+	}
+	_, _ = rand.Read(sc.SpanID[:])
+
+	s := &Span{
+		exporter: e,
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Start:       time.Now(),
+			Attributes:  append([]Attribute{}, attrs...),
+		},
+	}
+	if parent.IsValid() {
+		s.data.Parent = parent.SpanID
+	}
+	return ContextWithSpanContext(ctx, sc), s
+}
+
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed, it does nothing if err is nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End completes the span and sends it to the exporter if it is sampled,
+// subsequent calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mutex.Unlock()
+
+	if data.SpanContext.IsSampled() {
+		s.exporter.Export(data)
+	}
+}
+
+// SetExporter replaces the exporter, shutting down the previous one. A nil
+// exporter disables tracing.
+func SetExporter(e Exporter) {
+	exporterMutex.Lock()
+	prev := exporter
+	exporter = e
+	configuredEndpoint = ""
+	exporterMutex.Unlock()
+
+	if prev != nil {
+		prev.Shutdown()
+	}
+}
+
+// Configure exports the spans to the OTLP/HTTP collector at endpoint, or
+// disables tracing if endpoint is empty. The exporter is only replaced if the
+// endpoint changes.
+func Configure(endpoint string) error {
+	exporterMutex.RLock()
+	unchanged := endpoint == configuredEndpoint && (endpoint == "") == (exporter == nil)
+	exporterMutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	if endpoint == "" {
+		SetExporter(nil)
+		return nil
+	}
+	e, err := NewOTLPExporter(endpoint, &http.Client{Timeout: otlpTimeout})
+	if err != nil {
+		return err
+	}
+	SetExporter(e)
+
+	exporterMutex.Lock()
+	configuredEndpoint = endpoint
+	exporterMutex.Unlock()
+	return nil
+}
+
+// Shutdown flushes the spans, and disables tracing.
+func Shutdown() {
+	SetExporter(nil)
+}
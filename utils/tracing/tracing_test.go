@@ -0,0 +1,187 @@
+// Copyright (c) 2022-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testExporter struct {
+	mutex sync.Mutex
+	spans []SpanData
+}
+
+func (e *testExporter) Export(s SpanData) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+func (e *testExporter) Shutdown() {}
+
+// TestParseTraceParent checks the parsing of the "traceparent" header against
+// the W3C Trace Context, level 1:
+// https://www.w3.org/TR/trace-context/#traceparent-header
+func TestParseTraceParent(t *testing.T) {
+	for name, tc := range map[string]struct {
+		in          string
+		valid       bool
+		traceParent string
+	}{
+		"valid":                      {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"not sampled":                {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		"unknown flags are dropped":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-ff", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"unknown flags, not sampled": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-fe", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		"future version":             {"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"future version, no extra":   {"cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		"surrounding whitespace":     {" 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01\t", true, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"invalid version":            {"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, ""},
+		"non-hex version":            {"0g-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, ""},
+		"short version":              {"0-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, ""},
+		"extra in v00":               {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, ""},
+		"future version, long flags": {"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01extra", false, ""},
+		"zero trace ID":              {"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, ""},
+		"zero span ID":               {"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, ""},
+		"short trace ID":             {"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01", false, ""},
+		"uppercase trace ID":         {"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, ""},
+		"non-hex span ID":            {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01", false, ""},
+		"non-hex flags":              {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x", false, ""},
+		"uppercase flags":            {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0A", false, ""},
+		"short flags":                {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", false, ""},
+		"missing flags":              {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false, ""},
+		"empty":                      {"", false, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sc, err := ParseTraceParent(tc.in)
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, sc.IsValid())
+			require.Equal(t, tc.traceParent, sc.TraceParent())
+		})
+	}
+}
+
+func TestStartNotSampled(t *testing.T) {
+	e := &testExporter{}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	parent, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+	ctx, span := Start(ContextWithSpanContext(context.Background(), parent), "child")
+	span.End()
+
+	// The span is propagated as not sampled, and is not exported.
+	require.Empty(t, e.spans)
+	header := http.Header{}
+	Inject(ctx, header)
+	sc, err := ParseTraceParent(header.Get(TraceParentHeader))
+	require.NoError(t, err)
+	require.Equal(t, parent.TraceID, sc.TraceID)
+	require.NotEqual(t, parent.SpanID, sc.SpanID)
+	require.False(t, sc.IsSampled())
+}
+
+func TestStartDisabled(t *testing.T) {
+	SetExporter(nil)
+
+	ctx, span := Start(context.Background(), "test")
+	require.Nil(t, span)
+	require.False(t, SpanContextFromContext(ctx).IsValid())
+	span.SetAttributes(Attr("k", "v"))
+	span.RecordError(errors.New("error"))
+	span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+	require.Empty(t, header.Get(TraceParentHeader))
+}
+
+func TestStartNested(t *testing.T) {
+	e := &testExporter{}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	ctx, parent := Start(context.Background(), "parent", Attr("app_id", "app1"))
+	childCtx, child := Start(ctx, "child")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	child.End()
+	parent.End()
+
+	require.Len(t, e.spans, 2)
+	c, p := e.spans[0], e.spans[1]
+	require.Equal(t, "child", c.Name)
+	require.Equal(t, "failed", c.Error)
+	require.Equal(t, "parent", p.Name)
+	require.Equal(t, []Attribute{{"app_id", "app1"}}, p.Attributes)
+	require.Equal(t, SpanID{}, p.Parent)
+	require.Equal(t, p.SpanContext.TraceID, c.SpanContext.TraceID)
+	require.Equal(t, p.SpanContext.SpanID, c.Parent)
+	require.NotEqual(t, p.SpanContext.SpanID, c.SpanContext.SpanID)
+
+	header := http.Header{}
+	Inject(childCtx, header)
+	sc, err := ParseTraceParent(header.Get(TraceParentHeader))
+	require.NoError(t, err)
+	require.Equal(t, c.SpanContext, sc)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan otlpRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		require.Equal(t, otlpTracesPath, req.URL.Path)
+		require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		data, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		r := otlpRequest{}
+		require.NoError(t, json.Unmarshal(data, &r))
+		received <- r
+	}))
+	defer server.Close()
+
+	_, err := NewOTLPExporter("localhost:4318", server.Client())
+	require.Error(t, err)
+
+	e, err := NewOTLPExporter(server.URL, server.Client())
+	require.NoError(t, err)
+	SetExporter(e)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child", Attr("path", "/submit"))
+	child.RecordError(errors.New("failed"))
+	child.End()
+	parent.End()
+
+	// Shutting down flushes the queued spans.
+	Shutdown()
+	r := <-received
+
+	require.Len(t, r.ResourceSpans, 1)
+	require.Equal(t, ServiceName, r.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+	spans := r.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	require.Equal(t, "child", spans[0].Name)
+	require.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	require.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	require.Len(t, spans[0].TraceID, 32)
+	require.Len(t, spans[0].SpanID, 16)
+	require.Equal(t, []otlpAttribute{{Key: "path", Value: otlpAnyValue{StringValue: "/submit"}}}, spans[0].Attributes)
+	require.Equal(t, otlpStatus{Code: otlpStatusCodeError, Message: "failed"}, spans[0].Status)
+	require.Equal(t, "parent", spans[1].Name)
+	require.Empty(t, spans[1].ParentSpanID)
+	require.Equal(t, otlpStatus{}, spans[1].Status)
+}